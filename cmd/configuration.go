@@ -0,0 +1,189 @@
+/*
+Copyright © 2025 Adam Kalinowski <adam.kalilarosa@proton.me>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/adamkali/egg_cli/pkg/configuration"
+	"github.com/adamkali/egg_cli/pkg/models"
+	"github.com/adamkali/egg_cli/state"
+	"github.com/adamkali/egg_cli/styles"
+)
+
+const (
+	defaultSemver       = "0.0.1"
+	defaultPort         = 8080
+	defaultDatabaseRoot = "db"
+	defaultDatabaseURL  = "postgres://postgres@localhost:5432/egg?sslmode=disable"
+	defaultSqlc         = "sql"
+	defaultProtocol     = "postgresql"
+	defaultCacheURL     = "redis://localhost:6379"
+	defaultFrontendDir  = "web/dist"
+	defaultFrontendApi  = "web/src/api"
+)
+
+// configurationFromState builds a configuration out of the values the wizard
+// pages saved into the state package. Empty values are left empty so that
+// applyDefaults can fill them in the same way for every source.
+func configurationFromState() *configuration.Configuration {
+	config := new(configuration.Configuration)
+	config.Namespace = state.ProjectNamespace
+	config.Name = state.ProjectName
+	config.License = state.License
+	config.Copyright.Author = state.ProjectUsername
+	if state.CopyrightAuthor != "" {
+		config.Copyright.Author = state.CopyrightAuthor
+	}
+	if state.CopyrightYear != "" {
+		config.Copyright.Year, _ = strconv.Atoi(state.CopyrightYear)
+	}
+
+	if state.ServerPort != "" {
+		config.Server.Port, _ = strconv.Atoi(state.ServerPort)
+	}
+	config.Server.JWT = state.ServerJWT
+	config.Server.Frontend.Dir = state.ServerFrontendDir
+	config.Server.Frontend.Api = state.ServerFrontendApi
+
+	config.Database.URL = state.DatabaseURL
+	config.Database.Sqlc = state.DatabaseSqlcOrGo
+	if state.DatabaseRoot != "" {
+		config.Database.SqlcRepositoryLocation = state.DatabaseRoot + "/repository"
+		config.Database.QueriesLocation = state.DatabaseRoot + "/queries"
+		config.Database.Migration.Destination = state.DatabaseRoot + "/migrations"
+	}
+
+	config.Cache.URL = state.CacheURL
+	config.S3.URL = state.MinioURL
+	config.S3.Access = state.MinioAccessKey
+	config.S3.Secret = state.MinioSecretKey
+	for feature, enabled := range state.Features {
+		config.SetFeature(feature, enabled)
+	}
+	return config
+}
+
+// configurationFromAnswers loads an answers file (if one was given) and then
+// applies every key=value override on top of it.
+func configurationFromAnswers(answersFile string, overrides []string) (*configuration.Configuration, error) {
+	config := new(configuration.Configuration)
+	if answersFile != "" {
+		var err error
+		config, err = configuration.LoadConfigurationFromFile(answersFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read answers file %s: %w", answersFile, err)
+		}
+	}
+	if err := config.ApplyOverrides(overrides); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// applyDefaults fills every optional field that was left empty and reports
+// what was defaulted to both the log and stdout.
+func applyDefaults(config *configuration.Configuration, logger *models.EggLog) error {
+	defaulted := func(message string, args ...any) {
+		message = fmt.Sprintf(message, args...)
+		logger.Info(message)
+		fmt.Println(styles.EggProgressInfo.Render(message))
+	}
+
+	if config.Semver == "" {
+		config.Semver = defaultSemver
+	}
+	if config.Copyright.Year == 0 {
+		defaulted("Generating copyright year")
+		config.Copyright.Year = time.Now().Year()
+	}
+	if config.Server.Port == 0 {
+		config.Server.Port = defaultPort
+	}
+	if config.Server.JWT == "" {
+		defaulted("Generating JWT secret")
+		secret, err := GenerateJWTSecret(32)
+		if err != nil {
+			return err
+		}
+		config.Server.JWT = secret
+	}
+	if config.Server.Frontend.Dir == "" {
+		config.Server.Frontend.Dir = defaultFrontendDir
+	}
+	if config.Server.Frontend.Api == "" {
+		config.Server.Frontend.Api = defaultFrontendApi
+	}
+
+	if config.Database.URL == "" {
+		defaulted("Defaulting to %s as database url", defaultDatabaseURL)
+		config.Database.URL = defaultDatabaseURL
+	}
+	if config.Database.Sqlc == "" {
+		defaulted("Defaulting to %s as database sqlc or go", defaultSqlc)
+		config.Database.Sqlc = defaultSqlc
+	}
+	if config.Database.SqlcRepositoryLocation == "" &&
+		config.Database.QueriesLocation == "" &&
+		config.Database.Migration.Destination == "" {
+		defaulted("Defaulting to %s as database root directory", defaultDatabaseRoot)
+	}
+	if config.Database.SqlcRepositoryLocation == "" {
+		config.Database.SqlcRepositoryLocation = defaultDatabaseRoot + "/repository"
+	}
+	if config.Database.QueriesLocation == "" {
+		config.Database.QueriesLocation = defaultDatabaseRoot + "/queries"
+	}
+	if config.Database.Migration.Destination == "" {
+		config.Database.Migration.Destination = defaultDatabaseRoot + "/migrations"
+	}
+	if config.Database.Migration.Protocol == "" {
+		// for now we only support postgres
+		config.Database.Migration.Protocol = defaultProtocol
+	}
+
+	if config.Cache.URL == "" {
+		config.Cache.URL = defaultCacheURL
+	}
+
+	// written out so that the configuration file lists every feature that can be turned off
+	config.Features.EnableUnset()
+	if !config.HasFeature(configuration.FeatureStorage) {
+		// without storage there is no minio to generate keys for
+		return nil
+	}
+	if config.S3.Access == "" {
+		secret, err := GenerateJWTSecret(32)
+		if err != nil {
+			return err
+		}
+		defaulted("Defaulting to %s as minio access key", secret)
+		fmt.Println("You should change this in the future to what AWS S3 / Minio generates when registering a new user")
+		config.S3.Access = secret
+	}
+	if config.S3.Secret == "" {
+		secret, err := GenerateJWTSecret(32)
+		if err != nil {
+			return err
+		}
+		defaulted("Defaulting to %s as minio secret key", secret)
+		fmt.Println("You should change this in the future to what AWS S3 / Minio generates when registering a new user")
+		config.S3.Secret = secret
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+/*
+Copyright © 2025 Adam Kalinowski <adam.kalilarosa@proton.me>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/adamkali/egg_cli/pkg/configuration"
+	"github.com/adamkali/egg_cli/pkg/doctor"
+	"github.com/adamkali/egg_cli/styles"
+	"github.com/spf13/cobra"
+)
+
+// the environment whose Postgres, Redis and S3 urls are checked
+var doctorEnv string
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check whether this machine can build an egg project",
+	Long: `Check the go release, the tools pinned in egg.lock, node and the frontend package
+managers, and whether the Postgres, Redis and S3 urls of config/<env>.yaml can be reached.
+
+Every check is printed as pass, warn or fail together with a hint on how to fix it.
+The command exits with 1 when a check failed. Outside of a project the default tool
+versions are checked and the services are skipped.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := configuration.LoadConfiguration(doctorEnv)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				fmt.Println(styles.EggProgressError.Render("🥚 failed to load " + doctorEnv + ": " + err.Error()))
+				os.Exit(1)
+			}
+			config = nil
+		}
+
+		d, err := doctor.New(config)
+		if err != nil {
+			fmt.Println(styles.EggProgressError.Render("🥚 " + err.Error()))
+			os.Exit(1)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		checks := d.Run(ctx)
+		fmt.Print(doctor.Render(checks))
+		if doctor.Failed(checks) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	doctorCmd.Flags().StringVarP(&doctorEnv, "env", "e", defaultSeedEnvironment, "environment whose services are checked")
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,139 @@
+/*
+Copyright © 2025 Adam Kalinowski <adam.kalilarosa@proton.me>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/adamkali/egg_cli/pkg/configuration"
+	"github.com/adamkali/egg_cli/pkg/models"
+	"github.com/adamkali/egg_cli/styles"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultSeedEnvironment = "development"
+
+var (
+	// the environment to seed the new configuration from, skips the wizard
+	generateEnv string
+	// the name of the configuration file to create in config/
+	generateName string
+	// key=value overrides applied on top of the seed
+	generateOverrides []string
+	// overwrite config/<name>.yaml if it already exists
+	generateForce bool
+)
+
+// generateCmd represents the generate command
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate a new environment configuration",
+	Long: `Generate a new config/<name>.yaml for an existing project.
+
+Without --env the configuration wizard is shown, seeded from config/development.yaml
+when it exists: anything left empty in the wizard keeps the development value.
+With --env the wizard is skipped and the new configuration is copied from
+config/<env>.yaml. In both cases --set can override single values:
+
+  egg_cli generate --name staging
+  egg_cli generate --name production --env development --set server.port=80`,
+	Run: func(cmd *cobra.Command, args []string) {
+		logger, err := models.NewLogger("egg-log")
+		if err != nil {
+			panic(err)
+		}
+		defer logger.Close()
+
+		fail := func(err error) {
+			logger.Error("error: %s", err.Error())
+			fmt.Println(styles.EggProgressError.Render("🥚 " + err.Error()))
+			os.Exit(1)
+		}
+
+		if err := configuration.ValidateEnvironmentName(generateName); err != nil {
+			fail(fmt.Errorf("--name: %w", err))
+		}
+		if generateEnv != "" {
+			if err := configuration.ValidateEnvironmentName(generateEnv); err != nil {
+				fail(fmt.Errorf("--env: %w", err))
+			}
+		}
+		target := configuration.ConfigurationDir + generateName + ".yaml"
+		if _, err := os.Stat(target); err == nil && !generateForce {
+			fail(fmt.Errorf("%s already exists, pass --force to overwrite it", target))
+		}
+
+		seed := generateEnv
+		if seed == "" {
+			seed = defaultSeedEnvironment
+		}
+		config, err := configuration.LoadConfiguration(seed)
+		if err != nil {
+			// a missing development.yaml is fine when running the wizard,
+			// but an environment asked for by name has to exist
+			if generateEnv != "" || !errors.Is(err, os.ErrNotExist) {
+				fail(fmt.Errorf("failed to load seed environment %s: %w", seed, err))
+			}
+			config = new(configuration.Configuration)
+		} else {
+			logger.Info("Seeding %s from %s", generateName, seed)
+		}
+
+		var wizard *configuration.Configuration
+		if generateEnv == "" {
+			pageModel := models.CreatePageModel(logger)
+			p := tea.NewProgram(pageModel)
+			if _, err := p.Run(); err != nil {
+				logger.Error("Error running program: %v", err)
+			}
+			wizard = configurationFromState()
+		}
+
+		if err := config.Seed(wizard, generateOverrides); err != nil {
+			fail(err)
+		}
+		if err := applyDefaults(config, logger); err != nil {
+			fail(err)
+		}
+		if err := config.Validate(); err != nil {
+			fail(err)
+		}
+
+		configPretty, err := yaml.Marshal(config)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(styles.EggProgressInfo.Render(string(configPretty)))
+		if err := config.GenerateConfigurationFile(generateName); err != nil {
+			fail(err)
+		}
+		logger.Info("Generated %s", target)
+		fmt.Println(styles.EggProgressTitle.Render("🥚 Generated " + target))
+	},
+}
+
+func init() {
+	generateCmd.Flags().StringVarP(&generateEnv, "env", "e", "", "environment to copy the configuration from, skips the wizard")
+	generateCmd.Flags().StringVarP(&generateName, "name", "n", "", "name of the configuration to create, e.g. staging")
+	generateCmd.Flags().StringArrayVar(&generateOverrides, "set", nil, "override a configuration value, e.g. --set server.port=9090 (repeatable)")
+	generateCmd.Flags().BoolVar(&generateForce, "force", false, "overwrite the configuration if it already exists")
+	generateCmd.MarkFlagRequired("name")
+	rootCmd.AddCommand(generateCmd)
+}
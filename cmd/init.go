@@ -16,26 +16,57 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"fmt"
-	"strconv"
-	"time"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
 
 	"github.com/adamkali/egg_cli/pkg"
 	"github.com/adamkali/egg_cli/pkg/configuration"
 	"github.com/adamkali/egg_cli/pkg/models"
-	"github.com/adamkali/egg_cli/state"
+	"github.com/adamkali/egg_cli/pkg/modules"
+	"github.com/adamkali/egg_cli/pkg/templates"
 	"github.com/adamkali/egg_cli/styles"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/term"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
 
-const (
-	defaultPort         = 8080
-	defaultDatabaseRoot = "db"
-	defaultDatabaseURL  = "postgres://postgres@localhost:5432/egg?sslmode=disable"
+var (
+	// answers file used to skip the wizard
+	initFrom string
+	// key=value overrides applied on top of the answers file
+	initOverrides []string
+	// print what would be created instead of creating it
+	initDryRun bool
+	// record undo actions and offer to roll back when a module fails
+	initTransactional bool
+	// roll back without asking when a module fails, implies initTransactional
+	initRollbackOnFailure bool
+	// print every step instead of showing the progress view
+	initNoProgress bool
+	// create the project without network access
+	initOffline bool
+	// vendor directory used instead of the module cache, implies initOffline
+	initVendor string
+	// directory the project is created in, defaults to the project name
+	initDir string
+	// replace an existing project directory
+	initForce bool
+	// create the project inside of an existing project directory
+	initMerge bool
+	// initOnConflict is what --merge does with a file that differs from its template
+	initOnConflict string
+	// directory of templates that override or add to the built-in ones
+	initTemplates string
+	// install the pinned version of an outdated tool outside of GOBIN
+	initReplaceTools bool
 )
 
 func GenerateJWTSecret(nBytes int) (string, error) {
@@ -55,155 +86,104 @@ func GenerateJWTSecret(nBytes int) (string, error) {
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize a new project",
-	Long:  `Initialize a new project with interactive setup`,
+	Long: `Initialize a new project with interactive setup.
+
+Passing --from and/or --set skips the interactive setup entirely so that
+projects can be scaffolded from CI and scripts:
+
+  egg_cli init --from answers.yaml
+  egg_cli init --from answers.yaml --set server.port=9090 --set name=my-app
+
+The answers file uses the same keys as config/development.yaml.
+
+Passing --dry-run prints the plan of every directory, file, tool and command
+the project would be created with, without touching the disk or the network.
+
+Passing --transactional records every change made while creating the project,
+and when a module fails offers to roll all of them back instead of leaving a
+.scrambled file for 'egg_cli recover'. --rollback-on-failure rolls back
+without asking.
+
+Passing --offline creates the project without network access: the go tools and
+libraries are taken from the local module cache, or the libraries from a vendor
+directory passed with --vendor, and the frontend is not created. Everything that
+is needed is checked before anything is created.
+
+The project is created in a directory named after the project, or the one passed
+with --dir. A directory that already has files in it is never touched unless
+--force is passed to replace it or --merge to create the project inside of it.
+
+When merging, files that are the same as the rendered templates are skipped and
+for every file that differs you are asked whether to keep it, overwrite it,
+write the template next to it as <file>.egg-new, or see the diff first.
+--on-conflict keep|overwrite|new answers the same for every file.
+
+Passing --templates renders every file of a directory with the same configuration
+as the built-in templates. A file replaces the built-in template of the same output
+path or adds a new one, e.g. controllers/user_controller.go.tmpl renders
+controllers/user_controller.go (a .tmpl suffix is removed). Without the flag
+~/.config/egg/templates is used when it exists.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		logger, err := models.NewLogger("egg-log")
 		if err != nil {
 			panic(err)
 		}
 
-		pageModel := models.CreatePageModel(logger)
-		p := tea.NewProgram(pageModel)
-		if _, err := p.Run(); err != nil {
-			logger.Error("Error running program: %v", err)
+		var config *configuration.Configuration
+		if initFrom != "" || len(initOverrides) > 0 {
+			config, err = configurationFromAnswers(initFrom, initOverrides)
+			if err != nil {
+				logger.Error("error: %s", err.Error())
+				fmt.Println(styles.EggProgressError.Render("🥚 " + err.Error()))
+				os.Exit(1)
+			}
+		} else {
+			pageModel := models.CreatePageModel(logger)
+			p := tea.NewProgram(pageModel)
+			if _, err := p.Run(); err != nil {
+				logger.Error("Error running program: %v", err)
+			}
+			config = configurationFromState()
 		}
-		config := new(configuration.Configuration)
-		config.Namespace = state.ProjectNamespace
-		config.Name = state.ProjectName
-		config.License = state.License
-		config.Semver = "0.0.1"
-		config.Copyright.Author = state.ProjectUsername
-		config.Copyright.Year = time.Now().Year()
 
-		if state.CopyrightYear == "" {
-			// get current year
-			generatingCopyrightYearMessage := fmt.Sprintf("Generating copyright year")
-			generatingCopyrightYearMessage = styles.EggProgressInfo.Render(generatingCopyrightYearMessage)
-			fmt.Println(generatingCopyrightYearMessage)
-			config.Copyright.Year = time.Now().Year()
+		if err := applyDefaults(config, logger); err != nil {
+			panic(err)
+		}
+		if err := config.Validate(); err != nil {
+			logger.Error("error: %s", err.Error())
+			fmt.Println(styles.EggProgressError.Render("🥚 " + err.Error()))
+			os.Exit(1)
 		}
 
-		var port int
-		if state.ServerPort == "" {
-			port = defaultPort
-		} else {
-			port, _ = strconv.Atoi(state.ServerPort)
+		offline, err := offlineOptions(initOffline, initVendor)
+		if err != nil {
+			logger.Error("error: %s", err.Error())
+			fmt.Println(styles.EggProgressError.Render("🥚 " + err.Error()))
+			os.Exit(1)
 		}
-		if state.ServerJWT == "" {
-			generatingJWTSecretMessage := fmt.Sprintf("Generating JWT secret")
-			generatingJWTSecretMessage = styles.EggProgressInfo.Render(generatingJWTSecretMessage)
-			fmt.Println(generatingJWTSecretMessage)
-			secret, err := GenerateJWTSecret(32)
-			if err != nil {
-				panic(err)
-			}
-			state.ServerJWT = secret
-		}
-
-		config.Server = struct {
-			JWT      string "yaml:\"jwt\""
-			Port     int    "yaml:\"port\""
-			Frontend struct {
-				Dir string "yaml:\"dir\""
-				Api string "yaml:\"api\""
-			} "yaml:\"frontend\""
-		}{
-			Port: port,
-			JWT:  state.ServerJWT,
-			Frontend: struct {
-				Dir string "yaml:\"dir\""
-				Api string "yaml:\"api\""
-			}{
-				Dir: "web/dist",
-				Api: "web/src/api",
-			},
-		}
-
-		if state.DatabaseURL == "" {
-			defaultingDatabaseURLMessage := fmt.Sprintf("Defaulting to %s as database url", defaultDatabaseURL)
-			logger.Info(defaultingDatabaseURLMessage)
-			defaultingDatabaseURLMessage = styles.EggProgressInfo.Render(defaultingDatabaseURLMessage)
-			fmt.Println(defaultingDatabaseURLMessage)
-			state.DatabaseURL = defaultDatabaseURL
-		}
-
-		if state.DatabaseSqlcOrGo == "" {
-			defaultingDatabaseSqlcOrGoMessage := fmt.Sprintf("Defaulting to %s as database sqlc or go", "sql")
-			logger.Info(defaultingDatabaseSqlcOrGoMessage)
-			defaultingDatabaseSqlcOrGoMessage = styles.EggProgressInfo.Render(defaultingDatabaseSqlcOrGoMessage)
-			fmt.Println(defaultingDatabaseSqlcOrGoMessage)
-			state.DatabaseSqlcOrGo = "sql"
-		}
-
-		if state.DatabaseRoot == "" {
-			defaultingDatabaseRootMessage := fmt.Sprintf("Defaulting to %s as database root directory", defaultDatabaseRoot)
-			logger.Info(defaultingDatabaseRootMessage)
-			defaultingDatabaseRootMessage = styles.EggProgressInfo.Render(defaultingDatabaseRootMessage)
-			fmt.Println(defaultingDatabaseRootMessage)
-			state.DatabaseRoot = "db"
-		}
-		config.Database = struct {
-			URL                    string "yaml:\"url\""
-			Sqlc                   string "yaml:\"sqlc\""
-			SqlcRepositoryLocation string "yaml:\"repository\""
-			QueriesLocation        string "yaml:\"queries\""
-			Migration              struct {
-				Protocol    string "yaml:\"protocol\""
-				Destination string "yaml:\"destination\""
-			} "yaml:\"migration\""
-		}{
-			URL:                    state.DatabaseURL,
-			Sqlc:                   state.DatabaseSqlcOrGo,
-			SqlcRepositoryLocation: state.DatabaseRoot + "/repository",
-			QueriesLocation:        state.DatabaseRoot + "/queries",
-			Migration: struct {
-				Protocol    string "yaml:\"protocol\""
-				Destination string "yaml:\"destination\""
-			}{
-				// for now we only support postgres
-				Protocol:    "postgresql",
-				Destination: state.DatabaseRoot + "/migrations",
-			},
-		}
-
-		config.Cache = struct {
-			URL string "yaml:\"url\""
-		}{
-			URL: "redis://localhost:6379",
-		}
-		if state.MinioAccessKey == "" {
-			secret, err := GenerateJWTSecret(32)
-			if err != nil {
-				panic(err)
-			}
-			defaultingMinioAccessKeyMessage := fmt.Sprintf("Defaulting to %s as minio access key", secret)
-			logger.Info(defaultingMinioAccessKeyMessage)
-			defaultingMinioAccessKeyMessage = styles.EggProgressInfo.Render(defaultingMinioAccessKeyMessage)
-			fmt.Println(defaultingMinioAccessKeyMessage)
-			fmt.Println("You should change this in the future to what AWS S3 / Minio generates when registering a new user")
-			state.MinioAccessKey = secret
-		}
-		if state.MinioSecretKey == "" {
-			secret, err := GenerateJWTSecret(32)
-			if err != nil {
-				panic(err)
-			}
-			defaultingMinioSecretKeyMessage := fmt.Sprintf("Defaulting to %s as minio secret key", secret)
-			logger.Info(defaultingMinioSecretKeyMessage)
-			defaultingMinioSecretKeyMessage = styles.EggProgressInfo.Render(defaultingMinioSecretKeyMessage)
-			fmt.Println(defaultingMinioSecretKeyMessage)
-			fmt.Println("You should change this in the future to what AWS S3 / Minio generates when registering a new user")
-			state.MinioSecretKey = secret
+		workspace, err := workspaceOptions(initDir, initForce, initMerge, initOnConflict)
+		if err != nil {
+			logger.Error("error: %s", err.Error())
+			fmt.Println(styles.EggProgressError.Render("🥚 " + err.Error()))
+			os.Exit(1)
+		}
+		templateDir, err := templates.OverrideDir(initTemplates)
+		if err != nil {
+			logger.Error("error: %s", err.Error())
+			fmt.Println(styles.EggProgressError.Render("🥚 " + err.Error()))
+			os.Exit(1)
 		}
 
-		config.S3 = struct {
-			URL    string "yaml:\"url\""
-			Access string "yaml:\"access\""
-			Secret string "yaml:\"secret\""
-		}{
-			URL:    state.MinioURL,
-			Access: state.MinioAccessKey,
-			Secret: state.MinioSecretKey,
+		if initDryRun {
+			plans, err := pkg.PlanFactory(config, logger, offline, workspace, templateDir)
+			if err != nil {
+				logger.Error("error: %s", err.Error())
+				fmt.Println(styles.EggProgressError.Render("🥚 " + err.Error()))
+				os.Exit(1)
+			}
+			fmt.Println(styles.EggProgressTitle.Render("🥚 Dry run: nothing will be created"))
+			fmt.Println(styles.EggProgressInfo.Render(pkg.RenderPlan(config.Name, plans)))
+			return
 		}
 
 		fmt.Printf("\n")
@@ -213,13 +193,109 @@ var initCmd = &cobra.Command{
 		}
 		fmt.Println(styles.EggProgressInfo.Render(string(configPretty)))
 		fmt.Println(styles.EggProgressTitle.Render("🥚 Creating Project: " + config.Name))
-		err = pkg.ProjectFactory(config, logger)
+		// Ctrl-C cancels the context, which stops the running module and kills
+		// any go or package manager subprocess it started
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		options := pkg.Options{
+			Transactional: initTransactional || initRollbackOnFailure,
+			Progress:      showProgress(initNoProgress),
+			Offline:       offline,
+			Workspace:     workspace,
+			Templates:     templateDir,
+			ReplaceTools:  initReplaceTools,
+		}
+		if !initRollbackOnFailure {
+			options.ConfirmRollback = confirmRollback
+		}
+		err = pkg.ProjectFactory(ctx, config, logger, options)
+		if errors.Is(err, pkg.ErrRolledBack) {
+			fmt.Println(styles.EggProgressError.Render("🥚 " + err.Error()))
+			fmt.Println(styles.EggProgressInfo.Render("🥚 rollback complete, nothing was left behind"))
+			os.Exit(1)
+		}
+		var existsErr *modules.ProjectExistsError
+		if errors.As(err, &existsErr) {
+			fmt.Println(styles.EggProgressError.Render("🥚 " + err.Error()))
+			os.Exit(1)
+		}
+		var offlineErr *modules.OfflineError
+		if errors.As(err, &offlineErr) {
+			fmt.Println(styles.EggProgressError.Render("🥚 cannot create the project offline, " + err.Error()))
+			os.Exit(1)
+		}
+		if errors.Is(err, context.Canceled) {
+			fmt.Println(styles.EggProgressError.Render("🥚 Cancelled, run 'egg_cli recover' to continue where it stopped"))
+			os.Exit(130)
+		}
 		if err != nil {
 			panic(err)
 		}
 	},
 }
 
+// confirmRollback asks whether the changes of a failed run should be undone
+func confirmRollback(failed modules.IModule, err error) bool {
+	fmt.Printf("%s failed, do you want to roll back every change? (y/n)\n", failed.Name())
+	var rollback string
+	fmt.Scanln(&rollback)
+	return rollback == "y" || rollback == "Y" || rollback == "yes" || rollback == "Yes" || rollback == "YES"
+}
+
+// offlineOptions returns the offline mode of the flags, a vendor directory implies offline
+func offlineOptions(offline bool, vendor string) (modules.Offline, error) {
+	if vendor == "" {
+		return modules.Offline{Enabled: offline}, nil
+	}
+	// the modules run from another directory than the working directory
+	dir, err := filepath.Abs(vendor)
+	if err != nil {
+		return modules.Offline{}, err
+	}
+	if _, err := os.Stat(filepath.Join(dir, modules.VendorModulesFile)); err != nil {
+		return modules.Offline{}, fmt.Errorf("%s is not a vendor directory made by go mod vendor: %w", vendor, err)
+	}
+	return modules.Offline{Enabled: true, VendorDir: dir}, nil
+}
+
+// workspaceOptions returns the workspace of the flags, --force and --merge exclude each other
+func workspaceOptions(dir string, force bool, merge bool, onConflict string) (modules.Workspace, error) {
+	workspace := modules.Workspace{Dir: dir}
+	conflicts, err := modules.ParseResolution(onConflict)
+	if err != nil {
+		return workspace, fmt.Errorf("--on-conflict: %w", err)
+	}
+	workspace.Conflicts = conflicts
+	switch {
+	case force && merge:
+		return workspace, errors.New("--force and --merge can not be used together")
+	case force:
+		workspace.Overwrite = modules.OverwriteForce
+	case merge:
+		workspace.Overwrite = modules.OverwriteMerge
+	}
+	return workspace, nil
+}
+
+// showProgress reports whether the progress view can be used, it needs a terminal
+func showProgress(disabled bool) bool {
+	return !disabled && term.IsTerminal(os.Stdout.Fd())
+}
+
 func init() {
+	initCmd.Flags().StringVar(&initFrom, "from", "", "answers file (yaml) used instead of the interactive setup")
+	initCmd.Flags().StringArrayVar(&initOverrides, "set", nil, "override a configuration value, e.g. --set server.port=9090 (repeatable)")
+	initCmd.Flags().BoolVar(&initDryRun, "dry-run", false, "print the plan of what would be created without creating anything")
+	initCmd.Flags().BoolVar(&initTransactional, "transactional", false, "offer to roll back every change when a module fails")
+	initCmd.Flags().BoolVar(&initRollbackOnFailure, "rollback-on-failure", false, "roll back every change without asking when a module fails")
+	initCmd.Flags().BoolVar(&initNoProgress, "no-progress", false, "print every step instead of showing the progress view")
+	initCmd.Flags().BoolVar(&initOffline, "offline", false, "create the project from the local module cache without network access, skips the frontend")
+	initCmd.Flags().StringVar(&initVendor, "vendor", "", "vendor directory (made by go mod vendor) to take the libraries from, implies --offline")
+	initCmd.Flags().StringVar(&initDir, "dir", "", "directory the project is created in (default is the project name)")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "replace the project directory if it already exists")
+	initCmd.Flags().BoolVar(&initMerge, "merge", false, "create the project inside of the project directory if it already exists")
+	initCmd.Flags().StringVar(&initOnConflict, "on-conflict", modules.ResolveAsk.String(), "what --merge does with a file that differs from its template: ask, keep, overwrite or new")
+	initCmd.Flags().BoolVar(&initReplaceTools, "replace-tools", false, "install the pinned version of a tool that is outdated outside of GOBIN (e.g. from a package manager)")
+	initCmd.Flags().StringVar(&initTemplates, "templates", "", "directory of templates that override or add to the built-in ones (default ~/.config/egg/templates if it exists)")
 	rootCmd.AddCommand(initCmd)
 }
@@ -16,18 +16,42 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/adamkali/egg_cli/pkg"
 	"github.com/adamkali/egg_cli/pkg/models"
+	"github.com/adamkali/egg_cli/pkg/templates"
 	"github.com/spf13/cobra"
 )
 
+var (
+	recoverFrom string
+	recoverOnly string
+	// print every step instead of showing the progress view
+	recoverNoProgress bool
+	// recover without network access, see init --offline
+	recoverOffline bool
+	recoverVendor  string
+	// the template directory the project was created with, see init --templates
+	recoverTemplates string
+	// install the pinned version of an outdated tool, see init --replace-tools
+	recoverReplaceTools bool
+)
+
 var recoverCmd = &cobra.Command{
 	Use:   "recover",
 	Short: "Recover a project from the .scrambled file",
-	Long:  `Attempt to recover a project from the .scrambled file, if it exists. This will resume execution from where the previous run failed.`,
+	Long: `Attempt to recover a project from the .scrambled file, if it exists. This will resume execution from where the previous run failed.
+
+Every module skips the steps that the previous runs already completed (created directories,
+installed tools, written templates, ...), so recovering more than once is safe.
+
+Use --from to rerun a module and every module after it, or --only to rerun a single module.
+--offline, --vendor and --templates work like they do for init.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Check if .scrambled file exists before attempting recovery
 		if !pkg.CheckScrambled() {
@@ -44,10 +68,32 @@ var recoverCmd = &cobra.Command{
 		}
 		defer logger.Close()
 
+		offline, err := offlineOptions(recoverOffline, recoverVendor)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
+		templateDir, err := templates.OverrideDir(recoverTemplates)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
 		fmt.Println("🔄 Attempting to recover project from .scrambled file...")
 
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
 		// Attempt recovery
-		if err := pkg.RecoverFromScrambled(logger); err != nil {
+		if err := pkg.RecoverFromScrambled(ctx, logger, pkg.RecoverOptions{
+			From:         recoverFrom,
+			Only:         recoverOnly,
+			Progress:     showProgress(recoverNoProgress),
+			Offline:      offline,
+			Templates:    templateDir,
+			ReplaceTools: recoverReplaceTools,
+		}); err != nil {
 			fmt.Printf("❌ Recovery failed: %v\n", err)
 			fmt.Println("💡 Check the .scrambled file for details about the failure.")
 			logger.Error("Recovery failed: %v", err)
@@ -61,4 +107,12 @@ var recoverCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(recoverCmd)
+	recoverCmd.Flags().StringVar(&recoverFrom, "from", "", "rerun this module and every module after it (e.g. egg::bootstrap_framework)")
+	recoverCmd.Flags().StringVar(&recoverOnly, "only", "", "rerun only this module (e.g. egg::install_tools)")
+	recoverCmd.Flags().BoolVar(&recoverNoProgress, "no-progress", false, "print every step instead of showing the progress view")
+	recoverCmd.Flags().BoolVar(&recoverOffline, "offline", false, "recover from the local module cache without network access, skips the frontend")
+	recoverCmd.Flags().StringVar(&recoverVendor, "vendor", "", "vendor directory (made by go mod vendor) to take the libraries from, implies --offline")
+	recoverCmd.Flags().BoolVar(&recoverReplaceTools, "replace-tools", false, "install the pinned version of a tool that is outdated outside of GOBIN (e.g. from a package manager)")
+	recoverCmd.Flags().StringVar(&recoverTemplates, "templates", "", "directory of templates that override or add to the built-in ones (default ~/.config/egg/templates if it exists)")
+	recoverCmd.MarkFlagsMutuallyExclusive("from", "only")
 }
@@ -0,0 +1,89 @@
+/*
+Copyright © 2025 Adam Kalinowski <adam.kalilarosa@proton.me>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/adamkali/egg_cli/pkg/templates"
+	"github.com/adamkali/egg_cli/pkg/upgrade"
+	"github.com/adamkali/egg_cli/styles"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// the environment whose configuration the templates are rendered with
+	upgradeEnv string
+	// the root of the project that is upgraded
+	upgradeDir string
+	// report what would change without writing anything
+	upgradeDryRun bool
+	// directory of templates that override or add to the built-in ones
+	upgradeTemplates string
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Re-render the templates of an existing project with this egg_cli release",
+	Long: `Re-render every template of an existing project with the configuration in
+config/<env>.yaml, so that the project gets the fixes of newer egg_cli releases.
+
+Every file is merged three-way against the copy egg_cli stored in .egg/baseline
+when the file was last rendered: files that were not edited are replaced, edits are
+kept when the template did not change, and both are merged when they touch different
+lines. Conflicting changes are written between <<<<<<< and >>>>>>> markers. A file
+without a baseline (a project made by an older egg_cli) is kept and the template is
+written next to it as <file>.egg-new.
+
+--templates works like it does for init, pass the directory the project was
+created with so that its overrides are upgraded instead of the built-in templates.
+
+The command exits with 1 when a file has to be resolved by hand.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		u, err := upgrade.New(upgradeDir, upgradeEnv)
+		if err != nil {
+			fmt.Println(styles.EggProgressError.Render("🥚 " + err.Error()))
+			os.Exit(1)
+		}
+		u.DryRun = upgradeDryRun
+		if u.Templates, err = templates.OverrideDir(upgradeTemplates); err != nil {
+			fmt.Println(styles.EggProgressError.Render("🥚 " + err.Error()))
+			os.Exit(1)
+		}
+
+		files, err := u.Run()
+		fmt.Print(upgrade.Render(files))
+		if err != nil {
+			fmt.Println(styles.EggProgressError.Render("🥚 " + err.Error()))
+			os.Exit(1)
+		}
+		if upgradeDryRun {
+			fmt.Println(styles.EggProgressInfo.Render("🥚 Dry run: nothing was written"))
+		}
+		if upgrade.Conflicted(files) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	upgradeCmd.Flags().StringVarP(&upgradeEnv, "env", "e", defaultSeedEnvironment, "environment whose configuration the templates are rendered with")
+	upgradeCmd.Flags().StringVar(&upgradeDir, "dir", ".", "root of the project that is upgraded")
+	upgradeCmd.Flags().BoolVar(&upgradeDryRun, "dry-run", false, "report what would change without writing anything")
+	upgradeCmd.Flags().StringVar(&upgradeTemplates, "templates", "", "directory of templates that override or add to the built-in ones (default ~/.config/egg/templates if it exists)")
+	rootCmd.AddCommand(upgradeCmd)
+}
@@ -3,6 +3,7 @@ package configuration
 import (
 	"errors"
 	"os"
+	"path/filepath"
 
 	"gopkg.in/yaml.v3"
 )
@@ -42,13 +43,19 @@ type Configuration struct {
 		Access string `yaml:"access"`
 		Secret string `yaml:"secret"`
 	} `yaml:"s3"`
+	Features Features `yaml:"features"`
 }
 
 const ConfigurationDir = "config/"
 
 func LoadConfiguration(environment string) (*Configuration, error) {
+	return LoadConfigurationFromFile(ConfigurationDir + environment + ".yaml")
+}
+
+// LoadConfigurationFromFile reads a configuration from an arbitrary yaml file,
+// such as an answers file passed to `egg_cli init --from`.
+func LoadConfigurationFromFile(configurationFile string) (*Configuration, error) {
 	configuration := new(Configuration)
-	configurationFile := ConfigurationDir + environment + ".yaml"
 	file, err := os.ReadFile(configurationFile)
 	if err != nil {
 		return configuration, err
@@ -71,14 +78,16 @@ func SaveConfiguration(configBytes []byte, environment string) error {
 }
 
 func (configuration *Configuration) GenerateConfigurationFile(environment string) error {
+	return configuration.GenerateConfigurationFileIn(".", environment)
+}
+
+// GenerateConfigurationFileIn writes the configuration of the environment into the
+// config directory of the project in dir, creating the directory when it is missing
+func (configuration *Configuration) GenerateConfigurationFileIn(dir string, environment string) error {
+	configurationDir := filepath.Join(dir, ConfigurationDir)
 	// create the config directory if not exists config/
-	if _, err := os.Stat(ConfigurationDir); errors.Is(err, os.ErrNotExist) {
-		if err := os.Mkdir(ConfigurationDir, 0777); err != nil {
-			return err
-		}
-	}
-	if _, err := os.Stat(ConfigurationDir + environment + ".yaml"); errors.Is(err, os.ErrNotExist) {
-		if _, err := os.Create(ConfigurationDir + environment + ".yaml"); err != nil {
+	if _, err := os.Stat(configurationDir); errors.Is(err, os.ErrNotExist) {
+		if err := os.Mkdir(configurationDir, 0777); err != nil {
 			return err
 		}
 	}
@@ -87,7 +96,7 @@ func (configuration *Configuration) GenerateConfigurationFile(environment string
 	if err != nil {
 		return err
 	}
-	if err := SaveConfiguration(configBytes, environment); err != nil {
+	if err := os.WriteFile(filepath.Join(configurationDir, environment+".yaml"), configBytes, 0777); err != nil {
 		return err
 	}
 	return nil
@@ -0,0 +1,309 @@
+package configuration
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func createValidConfiguration() *Configuration {
+	config := new(Configuration)
+	config.Namespace = "github.com/testuser/testproject"
+	config.Name = "testproject"
+	config.Semver = "0.0.1"
+	config.License = "MIT"
+	config.Copyright.Year = 2024
+	config.Copyright.Author = "Test User"
+	config.Server.JWT = "test-secret"
+	config.Server.Port = 8080
+	config.Database.URL = "postgres://postgres@localhost:5432/test?sslmode=disable"
+	config.Database.Sqlc = "sql"
+	config.Database.SqlcRepositoryLocation = "db/repository"
+	config.Database.QueriesLocation = "db/queries"
+	config.Database.Migration.Destination = "db/migrations"
+	return config
+}
+
+func TestLoadConfigurationFromFile(t *testing.T) {
+	answers := filepath.Join(t.TempDir(), "answers.yaml")
+	content := "namespace: github.com/testuser/testproject\nname: testproject\nserver:\n  port: 9090\n"
+	if err := os.WriteFile(answers, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write answers file: %v", err)
+	}
+
+	config, err := LoadConfigurationFromFile(answers)
+	if err != nil {
+		t.Fatalf("LoadConfigurationFromFile() error = %v", err)
+	}
+	if config.Name != "testproject" {
+		t.Errorf("Name = %q, want %q", config.Name, "testproject")
+	}
+	if config.Server.Port != 9090 {
+		t.Errorf("Server.Port = %d, want 9090", config.Server.Port)
+	}
+}
+
+func TestSetValue(t *testing.T) {
+	config := createValidConfiguration()
+
+	if err := config.SetValue("server.port", "9090"); err != nil {
+		t.Fatalf("SetValue(server.port) error = %v", err)
+	}
+	if config.Server.Port != 9090 {
+		t.Errorf("Server.Port = %d, want 9090", config.Server.Port)
+	}
+
+	if err := config.SetValue("database.migration.destination", "sql/migrations"); err != nil {
+		t.Fatalf("SetValue(database.migration.destination) error = %v", err)
+	}
+	if config.Database.Migration.Destination != "sql/migrations" {
+		t.Errorf("Database.Migration.Destination = %q, want %q", config.Database.Migration.Destination, "sql/migrations")
+	}
+
+	// numbers given to string fields should stay strings
+	if err := config.SetValue("server.jwt", "12345"); err != nil {
+		t.Fatalf("SetValue(server.jwt) error = %v", err)
+	}
+	if config.Server.JWT != "12345" {
+		t.Errorf("Server.JWT = %q, want %q", config.Server.JWT, "12345")
+	}
+
+	// the rest of the configuration must be untouched
+	if config.Name != "testproject" {
+		t.Errorf("Name = %q, want %q", config.Name, "testproject")
+	}
+}
+
+func TestSetValue_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value string
+	}{
+		{"unknown key", "server.host", "localhost"},
+		{"not a scalar", "server", "x"},
+		{"wrong type", "server.port", "abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := createValidConfiguration()
+			if err := config.SetValue(tt.key, tt.value); err == nil {
+				t.Errorf("SetValue(%q, %q) = nil, want error", tt.key, tt.value)
+			}
+			if config.Server.Port != 8080 {
+				t.Errorf("failed SetValue modified the configuration")
+			}
+		})
+	}
+}
+
+func TestApplyOverrides(t *testing.T) {
+	config := new(Configuration)
+	err := config.ApplyOverrides([]string{"name=my-app", "cache.url=redis://cache:6379", "s3.secret=a=b"})
+	if err != nil {
+		t.Fatalf("ApplyOverrides() error = %v", err)
+	}
+	if config.Name != "my-app" {
+		t.Errorf("Name = %q, want %q", config.Name, "my-app")
+	}
+	if config.Cache.URL != "redis://cache:6379" {
+		t.Errorf("Cache.URL = %q, want %q", config.Cache.URL, "redis://cache:6379")
+	}
+	if config.S3.Secret != "a=b" {
+		t.Errorf("S3.Secret = %q, want %q", config.S3.Secret, "a=b")
+	}
+
+	if err := config.ApplyOverrides([]string{"name"}); err == nil {
+		t.Error("ApplyOverrides() without '=' should return an error")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	disabled := false
+	tests := []struct {
+		name    string
+		overlay func(overlay *Configuration)
+		check   func(t *testing.T, config *Configuration)
+	}{
+		{
+			name:    "empty overlay keeps every value",
+			overlay: func(*Configuration) {},
+			check: func(t *testing.T, config *Configuration) {
+				if *config != *createValidConfiguration() {
+					t.Errorf("Merge() = %+v, want the configuration unchanged", config)
+				}
+			},
+		},
+		{
+			name:    "top level string",
+			overlay: func(overlay *Configuration) { overlay.Name = "staging" },
+			check: func(t *testing.T, config *Configuration) {
+				if config.Name != "staging" || config.Namespace != "github.com/testuser/testproject" {
+					t.Errorf("Merge() Name = %q, Namespace = %q, want only the name changed", config.Name, config.Namespace)
+				}
+			},
+		},
+		{
+			name:    "nested struct field",
+			overlay: func(overlay *Configuration) { overlay.Database.Migration.Destination = "sql/migrations" },
+			check: func(t *testing.T, config *Configuration) {
+				if config.Database.Migration.Destination != "sql/migrations" {
+					t.Errorf("Merge() Database.Migration.Destination = %q, want %q", config.Database.Migration.Destination, "sql/migrations")
+				}
+				if config.Database.URL != "postgres://postgres@localhost:5432/test?sslmode=disable" {
+					t.Errorf("Merge() changed Database.URL to %q", config.Database.URL)
+				}
+			},
+		},
+		{
+			name:    "zero int keeps the value",
+			overlay: func(overlay *Configuration) { overlay.Server.JWT = "other-secret" },
+			check: func(t *testing.T, config *Configuration) {
+				if config.Server.Port != 8080 || config.Server.JWT != "other-secret" {
+					t.Errorf("Merge() Server = %+v, want port 8080 and the new jwt", config.Server)
+				}
+			},
+		},
+		{
+			name:    "explicit false feature",
+			overlay: func(overlay *Configuration) { overlay.Features.Cache = &disabled },
+			check: func(t *testing.T, config *Configuration) {
+				if config.HasFeature(FeatureCache) || !config.HasFeature(FeatureAuth) {
+					t.Errorf("Merge() Features = %+v, want only cache disabled", config.Features)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := createValidConfiguration()
+			overlay := new(Configuration)
+			tt.overlay(overlay)
+			config.Merge(overlay)
+			tt.check(t, config)
+		})
+	}
+}
+
+func TestSeed(t *testing.T) {
+	tests := []struct {
+		name      string
+		wizard    *Configuration
+		overrides []string
+		wantName  string
+		wantPort  int
+		wantErr   bool
+	}{
+		{"copy of the seed", nil, nil, "testproject", 8080, false},
+		{"wizard over the seed", &Configuration{Name: "staging"}, nil, "staging", 8080, false},
+		{"overrides over the seed", nil, []string{"server.port=80"}, "testproject", 80, false},
+		{"overrides over the wizard", &Configuration{Name: "staging"}, []string{"name=production"}, "production", 8080, false},
+		{"invalid override", nil, []string{"server.port=abc"}, "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := createValidConfiguration()
+			err := config.Seed(tt.wizard, tt.overrides)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Seed() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if config.Name != tt.wantName {
+				t.Errorf("Name = %q, want %q", config.Name, tt.wantName)
+			}
+			if config.Server.Port != tt.wantPort {
+				t.Errorf("Server.Port = %d, want %d", config.Server.Port, tt.wantPort)
+			}
+			if config.Database.Sqlc != "sql" {
+				t.Errorf("Database.Sqlc = %q, want the seed value kept", config.Database.Sqlc)
+			}
+		})
+	}
+}
+
+func TestValidateEnvironmentName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"staging", false},
+		{"production-eu", false},
+		{"", true},
+		{"../staging", true},
+		{"..", true},
+		{"prod/eu", true},
+		{`prod\eu`, true},
+		{"/etc/passwd", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateEnvironmentName(tt.name); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateEnvironmentName(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := createValidConfiguration().Validate(); err != nil {
+		t.Errorf("Validate() on a valid configuration = %v", err)
+	}
+
+	config := createValidConfiguration()
+	config.Namespace = ""
+	config.Name = ""
+	// the license is free text in the wizard, any identifier is accepted
+	config.License = "MPL-2.0"
+	config.Server.Port = 0
+
+	err := config.Validate()
+	var report *ValidationError
+	if !errors.As(err, &report) {
+		t.Fatalf("Validate() = %v, want *ValidationError", err)
+	}
+	want := map[string]bool{"namespace": true, "name": true, "server.port": true}
+	if len(report.Fields) != len(want) {
+		t.Errorf("Validate() reported %d problems, want %d: %v", len(report.Fields), len(want), err)
+	}
+	for _, field := range report.Fields {
+		if !want[field.Key] {
+			t.Errorf("Validate() reported unexpected field %q", field.Key)
+		}
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	config := createValidConfiguration()
+	for _, feature := range ValidFeatures {
+		if !config.HasFeature(feature) {
+			t.Errorf("HasFeature(%s) = false, want an unset feature to be enabled", feature)
+		}
+	}
+	if config.HasFeature("graphql") {
+		t.Error("HasFeature(graphql) = true, want false for an unknown feature")
+	}
+
+	if err := config.SetFeature(FeatureStorage, false); err != nil {
+		t.Fatalf("SetFeature(storage) error = %v", err)
+	}
+	if err := config.SetValue("features.cache", "false"); err != nil {
+		t.Fatalf("SetValue(features.cache) error = %v", err)
+	}
+	if config.HasFeature(FeatureStorage) || config.HasFeature(FeatureCache) {
+		t.Error("HasFeature() = true for a disabled feature")
+	}
+	if err := config.SetFeature("graphql", true); err == nil {
+		t.Error("SetFeature(graphql) should return an error")
+	}
+
+	// an explicit false of the wizard is merged over the seed
+	seed := createValidConfiguration()
+	seed.Features.EnableUnset()
+	seed.Merge(config)
+	if seed.HasFeature(FeatureCache) || !seed.HasFeature(FeatureAuth) {
+		t.Errorf("Merge() features = %+v, want only cache and storage disabled", seed.Features)
+	}
+}
@@ -0,0 +1,100 @@
+package configuration
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// FeatureAuth generates the users, the login and the JWT middleware
+	FeatureAuth = "auth"
+	// FeatureCache generates the redis service
+	FeatureCache = "cache"
+	// FeatureStorage generates the minio (s3) service and, with auth, the profile pictures
+	FeatureStorage = "storage"
+	// FeatureFrontend serves a frontend and generates its typescript api client
+	FeatureFrontend = "frontend"
+	// FeatureDocker generates the Dockerfile and .dockerignore
+	FeatureDocker = "docker"
+)
+
+// the optional parts of a project that can be chosen in the wizard
+var ValidFeatures = []string{FeatureAuth, FeatureCache, FeatureStorage, FeatureFrontend, FeatureDocker}
+
+// Features
+//
+// description:
+//
+//	Features are the optional parts of a generated project. A feature that is not
+//	set is enabled, so that a configuration written before the features existed
+//	still generates every file. The flags are pointers so that Merge and --set can
+//	tell an explicit false apart from a feature that was left out.
+type Features struct {
+	Auth     *bool `yaml:"auth"`
+	Cache    *bool `yaml:"cache"`
+	Storage  *bool `yaml:"storage"`
+	Frontend *bool `yaml:"frontend"`
+	Docker   *bool `yaml:"docker"`
+}
+
+// HasFeature
+//
+// params:
+//
+//	name: string, one of ValidFeatures
+//
+// returns:
+//
+//	bool: true if the feature is enabled or not set, false for an unknown feature
+//
+// description:
+//
+//	Used by the templates and the manifest to include or strip the files and the
+//	wiring of a feature, e.g. `{{if .HasFeature "auth"}}`.
+func (configuration *Configuration) HasFeature(name string) bool {
+	if configuration == nil {
+		return true
+	}
+	flag := configuration.Features.flag(name)
+	if flag == nil {
+		return false
+	}
+	return *flag == nil || **flag
+}
+
+// SetFeature enables or disables a single feature by its name
+func (configuration *Configuration) SetFeature(name string, enabled bool) error {
+	flag := configuration.Features.flag(name)
+	if flag == nil {
+		return fmt.Errorf("unknown feature %q, must be one of: %s", name, strings.Join(ValidFeatures, ", "))
+	}
+	*flag = &enabled
+	return nil
+}
+
+// EnableUnset enables every feature that was not set, so that a written configuration lists all of them
+func (features *Features) EnableUnset() {
+	for _, name := range ValidFeatures {
+		if flag := features.flag(name); *flag == nil {
+			enabled := true
+			*flag = &enabled
+		}
+	}
+}
+
+// flag returns the field of the feature, nil for an unknown feature
+func (features *Features) flag(name string) **bool {
+	switch name {
+	case FeatureAuth:
+		return &features.Auth
+	case FeatureCache:
+		return &features.Cache
+	case FeatureStorage:
+		return &features.Storage
+	case FeatureFrontend:
+		return &features.Frontend
+	case FeatureDocker:
+		return &features.Docker
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package configuration
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SetValue
+//
+// params:
+//
+//	key: string
+//	value: string
+//
+// returns:
+//
+//	error:
+//	  - if the key does not exist in the configuration
+//	  - if the value cannot be decoded into the field (e.g. "abc" for server.port)
+//
+// description:
+//
+//	Sets a single field of the configuration addressed by its dotted yaml path,
+//	e.g. "server.port=9090" or "database.url=postgres://...". The keys are the same
+//	ones written to config/<env>.yaml so an answers file and a --set override always agree.
+func (configuration *Configuration) SetValue(key, value string) error {
+	var document yaml.Node
+	if err := document.Encode(configuration); err != nil {
+		return err
+	}
+
+	node := &document
+	for _, part := range strings.Split(key, ".") {
+		child := lookupKey(node, part)
+		if child == nil {
+			return fmt.Errorf("unknown configuration key: %s", key)
+		}
+		node = child
+	}
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("configuration key %s is not a single value", key)
+	}
+
+	// resetting the tag lets yaml resolve the type of the value again
+	// so that "8080" is decoded as an int and "my-app" as a string
+	node.Tag = ""
+	node.Style = 0
+	node.Value = value
+
+	updated := new(Configuration)
+	if err := document.Decode(updated); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	*configuration = *updated
+	return nil
+}
+
+// ApplyOverrides applies a list of key=value pairs in order using SetValue
+func (configuration *Configuration) ApplyOverrides(overrides []string) error {
+	for _, override := range overrides {
+		key, value, ok := strings.Cut(override, "=")
+		if !ok {
+			return fmt.Errorf("invalid override %q: expected key=value", override)
+		}
+		if err := configuration.SetValue(strings.TrimSpace(key), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookupKey returns the value node stored under key in a yaml mapping node
+func lookupKey(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// Merge
+//
+// params:
+//
+//	overlay: *Configuration
+//
+// description:
+//
+//	Copies every field that is set (non-zero) in overlay onto the configuration.
+//	Fields left empty in overlay keep their current value, which lets a new
+//	environment be seeded from an existing one and only change what was entered.
+func (configuration *Configuration) Merge(overlay *Configuration) {
+	mergeValue(reflect.ValueOf(configuration).Elem(), reflect.ValueOf(overlay).Elem())
+}
+
+// Seed
+//
+// params:
+//
+//	wizard: *Configuration, nil when the wizard was skipped
+//	overrides: []string
+//
+// returns:
+//
+//	error: if an override is invalid, see ApplyOverrides
+//
+// description:
+//
+//	Turns the configuration a new environment is seeded from into the new one, as
+//	`egg_cli generate` does: the values entered in the wizard are merged over it and
+//	the --set overrides are applied last, so that they win over both.
+func (configuration *Configuration) Seed(wizard *Configuration, overrides []string) error {
+	if wizard != nil {
+		configuration.Merge(wizard)
+	}
+	return configuration.ApplyOverrides(overrides)
+}
+
+func mergeValue(dst, src reflect.Value) {
+	if dst.Kind() == reflect.Struct {
+		for i := 0; i < dst.NumField(); i++ {
+			mergeValue(dst.Field(i), src.Field(i))
+		}
+		return
+	}
+	if !src.IsZero() {
+		dst.Set(src)
+	}
+}
@@ -0,0 +1,116 @@
+package configuration
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// the ways that the repository layer can be generated
+var ValidSqlcOptions = []string{"go", "sql"}
+
+// FieldError describes a single problem with a configuration field
+type FieldError struct {
+	Key     string
+	Message string
+}
+
+// ValidationError collects every problem found by Validate so that the
+// whole report can be shown at once instead of failing on the first field
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("configuration is invalid (%d problems):", len(e.Fields)))
+	for _, field := range e.Fields {
+		builder.WriteString(fmt.Sprintf("\n  - %s: %s", field.Key, field.Message))
+	}
+	return builder.String()
+}
+
+func (e *ValidationError) add(key, message string) {
+	e.Fields = append(e.Fields, FieldError{Key: key, Message: message})
+}
+
+// Validate
+//
+// returns:
+//
+//	error:
+//	  - *ValidationError listing every missing or malformed field
+//	  - nil if the configuration can be used to create a project
+//
+// description:
+//
+//	Checks that a configuration is complete enough to scaffold a project. This is
+//	used after the wizard and for non-interactive runs where there is nobody to
+//	ask for the missing values.
+func (configuration *Configuration) Validate() error {
+	report := new(ValidationError)
+
+	if configuration.Namespace == "" {
+		report.add("namespace", "is required (e.g. github.com/user/project)")
+	}
+	if configuration.Name == "" {
+		report.add("name", "is required")
+	} else if strings.ContainsAny(configuration.Name, `/\ `) {
+		report.add("name", "must not contain slashes or spaces")
+	}
+	if configuration.Semver == "" {
+		report.add("semver", "is required")
+	}
+	if configuration.Copyright.Year < 1900 || configuration.Copyright.Year > 2100 {
+		report.add("copyright.year", "must be between 1900 and 2100")
+	}
+	if configuration.Server.JWT == "" {
+		report.add("server.jwt", "is required")
+	}
+	if configuration.Server.Port < 1 || configuration.Server.Port > 65535 {
+		report.add("server.port", "must be between 1 and 65535")
+	}
+	if configuration.Database.URL == "" {
+		report.add("database.url", "is required")
+	} else if _, err := url.Parse(configuration.Database.URL); err != nil {
+		report.add("database.url", "is not a valid url")
+	}
+	if !contains(ValidSqlcOptions, configuration.Database.Sqlc) {
+		report.add("database.sqlc", "must be one of: "+strings.Join(ValidSqlcOptions, ", "))
+	}
+	if configuration.Database.SqlcRepositoryLocation == "" {
+		report.add("database.repository", "is required")
+	}
+	if configuration.Database.QueriesLocation == "" {
+		report.add("database.queries", "is required")
+	}
+	if configuration.Database.Migration.Destination == "" {
+		report.add("database.migration.destination", "is required")
+	}
+
+	if len(report.Fields) > 0 {
+		return report
+	}
+	return nil
+}
+
+// ValidateEnvironmentName returns an error when the environment can not be used as the
+// name of config/<environment>.yaml, so that it never reads or writes outside of config/
+func ValidateEnvironmentName(environment string) error {
+	if environment == "" {
+		return fmt.Errorf("the environment name is required")
+	}
+	if strings.ContainsAny(environment, `/\`) || strings.Contains(environment, "..") {
+		return fmt.Errorf("invalid environment name %q: must not contain slashes or ..", environment)
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
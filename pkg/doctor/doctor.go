@@ -0,0 +1,285 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/adamkali/egg_cli/pkg/configuration"
+	"github.com/adamkali/egg_cli/pkg/modules"
+	"github.com/adamkali/egg_cli/pkg/targets"
+)
+
+// DefaultTimeout bounds every command that is run and every service that is dialed
+const DefaultTimeout = 3 * time.Second
+
+// Status is the outcome of a single check
+type Status int
+
+const (
+	Pass Status = iota
+	// Warn is a problem that does not stop egg_cli init, but a feature of the project
+	Warn
+	// Fail is a problem that stops egg_cli init or the generated project
+	Fail
+	// Skip is a check of a feature the project does not use
+	Skip
+)
+
+func (s Status) String() string {
+	switch s {
+	case Warn:
+		return "warn"
+	case Fail:
+		return "fail"
+	case Skip:
+		return "skip"
+	default:
+		return "pass"
+	}
+}
+
+func (s Status) icon() string {
+	switch s {
+	case Warn:
+		return "!"
+	case Fail:
+		return "✗"
+	case Skip:
+		return "-"
+	default:
+		return "✓"
+	}
+}
+
+// Check is a single row of the doctor report
+type Check struct {
+	Name   string
+	Status Status
+	// Detail is what was found, e.g. a version or an address
+	Detail string
+	// Hint is how to fix a check that did not pass
+	Hint string
+}
+
+// packageManagers are the package managers RsbuildFrontendModule can create the frontend with
+var packageManagers = []string{"pnpm", "npm", "yarn", "bun"}
+
+// Doctor
+//
+// description:
+//
+//	Checks whether the machine can build an egg project: the go release, the tools
+//	pinned in egg.lock, node and a package manager for the frontend, and whether the
+//	Postgres, Redis and S3 urls of the configuration can be reached. Every lookup is
+//	injectable so that the checks can be tested against local fakes.
+type Doctor struct {
+	// Configuration is the configuration whose services are checked, nil skips them
+	Configuration *configuration.Configuration
+	// Tools are the tools that are checked, usually the ones pinned in egg.lock
+	Tools   []targets.Tool
+	Timeout time.Duration
+
+	LookPathFunc func(file string) (string, error)                                      // For testing - can be injected to mock looking up binaries
+	CommandFunc  func(ctx context.Context, name string, args ...string) ([]byte, error) // For testing - can be injected to mock running commands
+	VersionFunc  func(tool targets.Tool, path string) (string, error)                   // For testing - can be injected to mock reading the version of a tool
+	HTTPClient   *http.Client
+}
+
+// New returns a doctor for the tools pinned in egg.lock of the current directory
+func New(cfg *configuration.Configuration) (*Doctor, error) {
+	lock, err := targets.LoadLock(targets.LockFileName)
+	if err != nil {
+		return nil, err
+	}
+	return &Doctor{Configuration: cfg, Tools: lock.Tools, Timeout: DefaultTimeout}, nil
+}
+
+// Run runs every check, in the order they are reported
+func (d *Doctor) Run(ctx context.Context) []Check {
+	var checks []Check
+	checks = append(checks, d.checkGo(ctx))
+	checks = append(checks, d.checkTools(ctx)...)
+	checks = append(checks, d.checkFrontend(ctx)...)
+	checks = append(checks, d.checkServices(ctx)...)
+	return checks
+}
+
+// Failed reports whether any of the checks failed
+func Failed(checks []Check) bool {
+	for _, check := range checks {
+		if check.Status == Fail {
+			return true
+		}
+	}
+	return false
+}
+
+// Render renders the checks as a pass/fail table, with the hint below every check that did not pass
+func Render(checks []Check) string {
+	width := 0
+	for _, check := range checks {
+		width = max(width, len(check.Name))
+	}
+
+	var builder strings.Builder
+	builder.WriteString("🥚 doctor\n")
+	passed, skipped := 0, 0
+	for _, check := range checks {
+		builder.WriteString(fmt.Sprintf("%s %-*s %s  %s\n", check.Status.icon(), width, check.Name, check.Status, check.Detail))
+		if check.Status == Pass {
+			passed++
+		} else if check.Status == Skip {
+			skipped++
+		} else if check.Hint != "" {
+			builder.WriteString(fmt.Sprintf("  %-*s 💡 %s\n", width+6, "", check.Hint))
+		}
+	}
+	builder.WriteString(fmt.Sprintf("%d of %d checks passed\n", passed, len(checks)-skipped))
+	return builder.String()
+}
+
+func (d *Doctor) timeout() time.Duration {
+	if d.Timeout <= 0 {
+		return DefaultTimeout
+	}
+	return d.Timeout
+}
+
+func (d *Doctor) lookPath(file string) (string, error) {
+	// Use injected function if available, otherwise use real implementation
+	if d.LookPathFunc != nil {
+		return d.LookPathFunc(file)
+	}
+	return exec.LookPath(file)
+}
+
+func (d *Doctor) command(ctx context.Context, name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.timeout())
+	defer cancel()
+	// Use injected function if available, otherwise use real implementation
+	if d.CommandFunc != nil {
+		return d.CommandFunc(ctx, name, args...)
+	}
+	return exec.CommandContext(ctx, name, args...).CombinedOutput()
+}
+
+func (d *Doctor) checkGo(ctx context.Context) Check {
+	check := Check{Name: "go"}
+	hint := fmt.Sprintf("install go %d.%d or newer from https://go.dev/dl", modules.MinimumGoMajor, modules.MinimumGoMinor)
+	if _, err := d.lookPath("go"); err != nil {
+		check.Status, check.Detail, check.Hint = Fail, "not found on the PATH", hint
+		return check
+	}
+	output, err := d.command(ctx, "go", "version")
+	if err != nil {
+		check.Status, check.Detail, check.Hint = Fail, "go version: "+err.Error(), hint
+		return check
+	}
+	check.Detail = strings.TrimSpace(string(output))
+	if err := modules.CheckGoVersion(string(output)); err != nil {
+		check.Status, check.Detail, check.Hint = Fail, err.Error(), hint
+	}
+	return check
+}
+
+func (d *Doctor) checkTools(ctx context.Context) []Check {
+	inspector := &modules.InstallToolsModule{
+		LookPathFunc: d.LookPathFunc,
+		VersionFunc:  d.VersionFunc,
+	}
+	statuses := inspector.Inspect(ctx, d.Tools)
+	checks := make([]Check, len(statuses))
+	for i, status := range statuses {
+		check := Check{Name: status.Tool.Name}
+		install := "go install " + status.Tool.Target()
+		switch status.State {
+		case modules.ToolPresent:
+			check.Detail = status.Version + " " + status.Path
+		case modules.ToolOutdated:
+			check.Status = Warn
+			check.Detail = fmt.Sprintf("%s %s, %s is pinned in %s", status.Version, status.Path, status.Tool.Version, targets.LockFileName)
+			check.Hint = install
+		case modules.ToolUnverified:
+			check.Status = Warn
+			check.Detail = fmt.Sprintf("%s, the version could not be checked against %s", status.Path, status.Tool.Version)
+			check.Hint = install
+		default:
+			check.Status = Fail
+			check.Detail = "not found on the PATH"
+			check.Hint = install + ", and make sure $(go env GOPATH)/bin is on the PATH"
+		}
+		checks[i] = check
+	}
+	return checks
+}
+
+func (d *Doctor) checkFrontend(ctx context.Context) []Check {
+	if !d.Configuration.HasFeature(configuration.FeatureFrontend) {
+		return []Check{skipped("frontend", configuration.FeatureFrontend)}
+	}
+	var checks []Check
+
+	node := d.versionCheck(ctx, "node")
+	if node.Status != Pass {
+		node.Status = Fail
+		node.Hint = "install node from https://nodejs.org, the frontend is built with it"
+	}
+	checks = append(checks, node)
+
+	found := 0
+	managers := make([]Check, len(packageManagers))
+	for i, manager := range packageManagers {
+		managers[i] = d.versionCheck(ctx, manager)
+		if managers[i].Status == Pass {
+			found++
+		}
+	}
+	for _, manager := range managers {
+		if manager.Status != Pass {
+			manager.Status = Warn
+			if found == 0 {
+				manager.Status = Fail
+				manager.Hint = "install one of " + strings.Join(packageManagers, ", ") + " to create the frontend"
+			}
+		}
+		checks = append(checks, manager)
+	}
+
+	openapi := d.versionCheck(ctx, "openapi-generator-cli")
+	if openapi.Status != Pass {
+		openapi.Status = Warn
+		openapi.Hint = "npm install -g @openapitools/openapi-generator-cli (it needs java), `swag` uses it to generate the frontend api"
+	}
+	checks = append(checks, openapi)
+	return checks
+}
+
+// skipped is the check of a service or a binary only the disabled feature uses
+func skipped(name string, feature string) Check {
+	return Check{Name: name, Status: Skip, Detail: "the " + feature + " feature is disabled"}
+}
+
+// versionCheck passes when the binary is on the PATH and prints its version
+func (d *Doctor) versionCheck(ctx context.Context, name string) Check {
+	check := Check{Name: name}
+	path, err := d.lookPath(name)
+	if err != nil {
+		check.Status, check.Detail = Fail, "not found on the PATH"
+		return check
+	}
+	output, err := d.command(ctx, path, "--version")
+	if err != nil {
+		check.Status, check.Detail = Fail, name+" --version: "+err.Error()
+		return check
+	}
+	version := strings.TrimSpace(string(output))
+	if line, _, ok := strings.Cut(version, "\n"); ok {
+		version = line
+	}
+	check.Detail = version + " " + path
+	return check
+}
@@ -0,0 +1,234 @@
+package doctor
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamkali/egg_cli/pkg/configuration"
+	"github.com/adamkali/egg_cli/pkg/targets"
+)
+
+// fakeServer accepts connections on a local port and answers every one of them with answer
+func fakeServer(t *testing.T, answer func(conn net.Conn)) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				answer(conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// fakePostgres refuses SSL like a Postgres server without certificates
+func fakePostgres(conn net.Conn) {
+	request := make([]byte, 8)
+	if _, err := conn.Read(request); err == nil {
+		conn.Write([]byte("N"))
+	}
+}
+
+func fakeRedis(conn net.Conn) {
+	if line, err := bufio.NewReader(conn).ReadString('\n'); err == nil && strings.HasPrefix(line, "PING") {
+		conn.Write([]byte("+PONG\r\n"))
+	}
+}
+
+// closedAddress returns an address that nothing listens on
+func closedAddress(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+	return address
+}
+
+// newTestDoctor returns a doctor for a machine where every binary in installed
+// is on the PATH and reports version
+func newTestDoctor(installed map[string]string) *Doctor {
+	return &Doctor{
+		Tools:   targets.RequiredTools,
+		Timeout: time.Second,
+		LookPathFunc: func(file string) (string, error) {
+			if _, ok := installed[file]; ok {
+				return "/usr/bin/" + file, nil
+			}
+			return "", errors.New("not found")
+		},
+		CommandFunc: func(_ context.Context, name string, args ...string) ([]byte, error) {
+			return []byte(installed[strings.TrimPrefix(name, "/usr/bin/")] + "\n"), nil
+		},
+		VersionFunc: func(tool targets.Tool, _ string) (string, error) {
+			return installed[tool.Name], nil
+		},
+	}
+}
+
+func healthyMachine() map[string]string {
+	installed := map[string]string{
+		"go":                    "go version go1.24.4 linux/amd64",
+		"node":                  "v22.1.0",
+		"pnpm":                  "9.1.0",
+		"openapi-generator-cli": "7.6.0",
+	}
+	for _, tool := range targets.RequiredTools {
+		installed[tool.Name] = tool.Version
+	}
+	return installed
+}
+
+// find returns the check with the given name
+func find(t *testing.T, checks []Check, name string) Check {
+	t.Helper()
+	for _, check := range checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	t.Fatalf("no %s check in %v", name, checks)
+	return Check{}
+}
+
+func TestDoctor_Run_HealthyMachine(t *testing.T) {
+	s3 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer s3.Close()
+
+	config := new(configuration.Configuration)
+	config.Database.URL = "postgres://postgres@" + fakeServer(t, fakePostgres) + "/egg?sslmode=disable"
+	config.Cache.URL = "redis://" + fakeServer(t, fakeRedis)
+	config.S3.URL = strings.TrimPrefix(s3.URL, "http://")
+
+	d := newTestDoctor(healthyMachine())
+	d.Configuration = config
+	checks := d.Run(context.Background())
+	if Failed(checks) {
+		t.Errorf("Run() failed on a healthy machine:\n%s", Render(checks))
+	}
+	for _, name := range []string{"go", "air", "sqlc", "node", "pnpm", "postgres", "redis", "s3"} {
+		if check := find(t, checks, name); check.Status != Pass {
+			t.Errorf("%s = %s (%s), want pass", name, check.Status, check.Detail)
+		}
+	}
+	// the other package managers are optional once one is installed
+	if check := find(t, checks, "yarn"); check.Status != Warn {
+		t.Errorf("yarn = %s, want warn", check.Status)
+	}
+}
+
+func TestDoctor_Run_BrokenMachine(t *testing.T) {
+	installed := healthyMachine()
+	installed["go"] = "go version go1.21.0 linux/amd64"
+	installed["sqlc"] = "v1.20.0"
+	delete(installed, "air")
+	delete(installed, "node")
+	delete(installed, "pnpm")
+
+	config := new(configuration.Configuration)
+	config.Database.URL = "postgres://postgres@" + closedAddress(t) + "/egg"
+	// something that is not redis
+	config.Cache.URL = "redis://" + fakeServer(t, func(conn net.Conn) { conn.Write([]byte("HTTP/1.1 400\r\n")) })
+
+	d := newTestDoctor(installed)
+	d.Configuration = config
+	checks := d.Run(context.Background())
+	if !Failed(checks) {
+		t.Fatal("Failed() = false for a broken machine")
+	}
+	want := map[string]Status{
+		"go":       Fail,
+		"air":      Fail,
+		"sqlc":     Warn,
+		"node":     Fail,
+		"pnpm":     Fail,
+		"postgres": Fail,
+		"redis":    Fail,
+		"s3":       Warn,
+	}
+	for name, status := range want {
+		check := find(t, checks, name)
+		if check.Status != status {
+			t.Errorf("%s = %s (%s), want %s", name, check.Status, check.Detail, status)
+		}
+		if check.Hint == "" {
+			t.Errorf("%s has no hint", name)
+		}
+	}
+	if hint := find(t, checks, "air").Hint; !strings.Contains(hint, "go install "+targets.RequiredTools[0].Target()) {
+		t.Errorf("air hint = %q, want the go install command", hint)
+	}
+}
+
+func TestDoctor_Run_WithoutConfiguration(t *testing.T) {
+	checks := newTestDoctor(healthyMachine()).Run(context.Background())
+	if check := find(t, checks, "configuration"); check.Status != Warn {
+		t.Errorf("configuration = %s, want warn", check.Status)
+	}
+	if Failed(checks) {
+		t.Errorf("Run() failed without a configuration:\n%s", Render(checks))
+	}
+}
+
+func TestDoctor_Run_DisabledFeatures(t *testing.T) {
+	installed := healthyMachine()
+	delete(installed, "node")
+	delete(installed, "pnpm")
+
+	config := new(configuration.Configuration)
+	config.Database.URL = "postgres://postgres@" + fakeServer(t, fakePostgres) + "/egg?sslmode=disable"
+	config.Cache.URL = "redis://" + closedAddress(t)
+	for _, feature := range []string{configuration.FeatureCache, configuration.FeatureStorage, configuration.FeatureFrontend} {
+		config.SetFeature(feature, false)
+	}
+
+	d := newTestDoctor(installed)
+	d.Configuration = config
+	checks := d.Run(context.Background())
+	if Failed(checks) {
+		t.Errorf("Run() failed on the services and binaries of disabled features:\n%s", Render(checks))
+	}
+	for _, name := range []string{"frontend", "redis", "s3"} {
+		if check := find(t, checks, name); check.Status != Skip {
+			t.Errorf("%s = %s (%s), want skip", name, check.Status, check.Detail)
+		}
+	}
+	for _, check := range checks {
+		if check.Name == "node" || check.Name == "pnpm" {
+			t.Errorf("%s was checked without the frontend feature", check.Name)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	report := Render([]Check{
+		{Name: "go", Status: Pass, Detail: "go1.24.4"},
+		{Name: "redis", Status: Fail, Detail: "connection refused", Hint: "start redis"},
+		{Name: "s3", Status: Skip, Detail: "the storage feature is disabled"},
+	})
+	for _, want := range []string{"✓ go", "pass", "✗ redis", "fail", "💡 start redis", "- s3", "skip", "1 of 2 checks passed"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Render() does not contain %q:\n%s", want, report)
+		}
+	}
+}
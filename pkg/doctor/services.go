@@ -0,0 +1,167 @@
+package doctor
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/adamkali/egg_cli/pkg/configuration"
+)
+
+// postgresSSLRequest is the code of the SSLRequest message, every Postgres server
+// answers it with a single byte before any authentication
+const postgresSSLRequest = 80877103
+
+func (d *Doctor) checkServices(ctx context.Context) []Check {
+	if d.Configuration == nil {
+		return []Check{{
+			Name:   "configuration",
+			Status: Warn,
+			Detail: "no configuration found, Postgres, Redis and S3 were not checked",
+			Hint:   "run egg_cli doctor from the root of an egg project, or pass --env",
+		}}
+	}
+	checks := []Check{
+		d.serviceCheck(ctx, "postgres", "database.url", d.Configuration.Database.URL, "5432", d.pingPostgres),
+	}
+	if d.Configuration.HasFeature(configuration.FeatureCache) {
+		checks = append(checks, d.serviceCheck(ctx, "redis", "cache.url", d.Configuration.Cache.URL, "6379", d.pingRedis))
+	} else {
+		checks = append(checks, skipped("redis", configuration.FeatureCache))
+	}
+	if d.Configuration.HasFeature(configuration.FeatureStorage) {
+		checks = append(checks, d.checkS3(ctx))
+	} else {
+		checks = append(checks, skipped("s3", configuration.FeatureStorage))
+	}
+	return checks
+}
+
+// serviceCheck dials the host of rawURL and pings the service listening there
+func (d *Doctor) serviceCheck(
+	ctx context.Context,
+	name string,
+	key string,
+	rawURL string,
+	defaultPort string,
+	ping func(conn net.Conn) error,
+) Check {
+	check := Check{Name: name}
+	if rawURL == "" {
+		check.Status, check.Detail = Warn, key+" is not set"
+		check.Hint = "set " + key + " in the configuration"
+		return check
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Hostname() == "" {
+		check.Status, check.Detail = Fail, key+" is not a valid url"
+		check.Hint = "set " + key + " to a url like " + name + "://localhost:" + defaultPort
+		return check
+	}
+	port := parsed.Port()
+	if port == "" {
+		port = defaultPort
+	}
+	address := net.JoinHostPort(parsed.Hostname(), port)
+	check.Detail = address
+
+	if err := d.dial(ctx, address, ping); err != nil {
+		check.Status = Fail
+		check.Detail = address + ": " + err.Error()
+		check.Hint = fmt.Sprintf("start %s on %s, or fix %s in the configuration", name, address, key)
+	}
+	return check
+}
+
+func (d *Doctor) dial(ctx context.Context, address string, ping func(conn net.Conn) error) error {
+	ctx, cancel := context.WithTimeout(ctx, d.timeout())
+	defer cancel()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	return ping(conn)
+}
+
+// pingPostgres sends an SSLRequest, a Postgres server answers it with S or N
+func (d *Doctor) pingPostgres(conn net.Conn) error {
+	request := make([]byte, 8)
+	binary.BigEndian.PutUint32(request[0:4], 8)
+	binary.BigEndian.PutUint32(request[4:8], postgresSSLRequest)
+	if _, err := conn.Write(request); err != nil {
+		return err
+	}
+	answer := make([]byte, 1)
+	if _, err := conn.Read(answer); err != nil {
+		return err
+	}
+	if answer[0] != 'S' && answer[0] != 'N' {
+		return errors.New("is not a Postgres server")
+	}
+	return nil
+}
+
+// pingRedis sends PING, a Redis server answers with +PONG or an error (e.g. -NOAUTH)
+func (d *Doctor) pingRedis(conn net.Conn) error {
+	if _, err := conn.Write([]byte("PING\r\n")); err != nil {
+		return err
+	}
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "-") {
+		return errors.New("is not a Redis server")
+	}
+	return nil
+}
+
+// checkS3 passes for any http response, the endpoint answers anonymous requests with 403
+func (d *Doctor) checkS3(ctx context.Context) Check {
+	check := Check{Name: "s3"}
+	rawURL := d.Configuration.S3.URL
+	if rawURL == "" {
+		check.Status, check.Detail = Warn, "s3.url is not set"
+		check.Hint = "set s3.url in the configuration"
+		return check
+	}
+	if !strings.Contains(rawURL, "://") {
+		// minio endpoints are usually configured as host:port
+		rawURL = "http://" + rawURL
+	}
+	check.Detail = rawURL
+
+	ctx, cancel := context.WithTimeout(ctx, d.timeout())
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodHead, rawURL, nil)
+	if err != nil {
+		check.Status, check.Detail = Fail, "s3.url is not a valid url"
+		check.Hint = "set s3.url to a url like http://localhost:9000"
+		return check
+	}
+	client := d.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		check.Status = Fail
+		check.Detail = err.Error()
+		check.Hint = "start the S3 server (e.g. minio) on " + rawURL + ", or fix s3.url in the configuration"
+		return check
+	}
+	response.Body.Close()
+	check.Detail = fmt.Sprintf("%s %s", rawURL, response.Status)
+	return check
+}
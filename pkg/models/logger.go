@@ -9,6 +9,8 @@ import (
 // EggLog struct holds the file and log writers
 type EggLog struct {
 	file *os.File
+	// tail receives every message as it is logged
+	tail func(line string)
 }
 
 // NewLogger creates a new logger instance
@@ -24,6 +26,13 @@ func NewLogger(filepath string) (*EggLog, error) {
 	}, nil
 }
 
+// WithTail returns a logger that writes to the same file and also passes every
+// message to tail, e.g. to show the latest lines of a module while it runs.
+// Closing either logger closes the shared file.
+func (l *EggLog) WithTail(tail func(line string)) *EggLog {
+	return &EggLog{file: l.file, tail: tail}
+}
+
 // Close closes the log file
 func (l *EggLog) Close() error {
 	if l.file != nil {
@@ -36,6 +45,9 @@ func (l *EggLog) Close() error {
 func (l *EggLog) Info(message string, args ...any) error {
 	// Format the message using fmt.Sprintf and include all args
 	message = fmt.Sprintf(message, args...)
+	if l.tail != nil {
+		l.tail(message)
+	}
 	formatted := fmt.Sprintf("%s INFO: %s", time.Now().Format("2006-01-02 15:04:05.000"), message)
 
 	// Write the formatted message to the log file with all args included
@@ -50,6 +62,9 @@ func (l *EggLog) Info(message string, args ...any) error {
 func (l *EggLog) Error(message string, args ...any) error {
 	// Format the message using fmt.Sprintf and include all args
 	message = fmt.Sprintf(message, args...)
+	if l.tail != nil {
+		l.tail(message)
+	}
 	formatted := fmt.Sprintf("%s ERROR: %s", time.Now().Format("2006-01-02 15:04:05.000"), message)
 
 	// Write the formatted message to the log file with all args included
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/adamkali/egg_cli/pkg/configuration"
+	"github.com/adamkali/egg_cli/state"
+	"github.com/adamkali/egg_cli/styles"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// the description of every feature shown next to its checkbox
+var featureDescriptions = map[string]string{
+	configuration.FeatureAuth:     "users, login and the JWT middleware",
+	configuration.FeatureCache:    "redis service",
+	configuration.FeatureStorage:  "minio (s3) service and profile pictures",
+	configuration.FeatureFrontend: "static frontend and the typescript api client",
+	configuration.FeatureDocker:   "Dockerfile and .dockerignore",
+}
+
+type ProjectFeatures struct {
+	enabled map[string]bool
+	cursor  int
+	eggl    *EggLog
+}
+
+// ProjectFeaturesModel starts with every feature enabled, like a configuration without features
+func ProjectFeaturesModel(l *EggLog) ProjectFeatures {
+	enabled := make(map[string]bool, len(configuration.ValidFeatures))
+	for _, feature := range configuration.ValidFeatures {
+		enabled[feature] = true
+	}
+	return ProjectFeatures{
+		enabled: enabled,
+		cursor:  0,
+		eggl:    l,
+	}
+}
+
+func (m ProjectFeatures) FocusFirstInput() {
+	m.cursor = 0
+}
+
+func (m ProjectFeatures) IsUnsavedChanges() bool {
+	for feature, enabled := range m.enabled {
+		saved, ok := state.Features[feature]
+		if !ok {
+			saved = true
+		}
+		if saved != enabled {
+			return true
+		}
+	}
+	return false
+}
+
+func (m ProjectFeatures) Init() tea.Cmd {
+	return nil
+}
+
+func (m ProjectFeatures) View() string {
+	view := fmt.Sprintf("%s %s\n\n", styles.Keyword.Width(70).Render("Features"), NewUnsavedChangesIcon(m).View())
+	for i, feature := range configuration.ValidFeatures {
+		cursor := " "
+		if i == m.cursor {
+			cursor = ">"
+		}
+		checked := "[ ]"
+		if m.enabled[feature] {
+			checked = "[x]"
+		}
+		view += fmt.Sprintf(
+			"%s %s %s --> %s\n",
+			cursor,
+			checked,
+			styles.Keyword.Align(lipgloss.Left).Width(20).Render(feature),
+			featureDescriptions[feature],
+		)
+	}
+	return view + "\n" + styles.Hint.Render("space toggles a feature, ctrl+s saves them") + "\n"
+}
+
+// save stores the features into the state package for configurationFromState
+func (m ProjectFeatures) save() {
+	for feature, enabled := range m.enabled {
+		state.Features[feature] = enabled
+	}
+	m.eggl.Info("Saved features: %v", state.Features)
+}
+
+func (m ProjectFeatures) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlS, tea.KeyEnter:
+			m.save()
+		case tea.KeyCtrlC:
+			return m, tea.Quit
+		case tea.KeySpace:
+			feature := configuration.ValidFeatures[m.cursor]
+			m.enabled[feature] = !m.enabled[feature]
+		case tea.KeyShiftTab, tea.KeyUp:
+			m.cursor--
+			if m.cursor < 0 {
+				m.cursor = len(configuration.ValidFeatures) - 1
+			}
+		case tea.KeyTab, tea.KeyDown:
+			m.cursor = (m.cursor + 1) % len(configuration.ValidFeatures)
+		}
+	}
+	return m, nil
+}
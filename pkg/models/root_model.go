@@ -22,6 +22,7 @@ func CreatePageModel(log *EggLog) PageModel {
 	license := ProjectLicenseModel(log)
 	server := ProjectServerSettingsModel(log)
 	mini := ProjectS3Model(log)
+	features := ProjectFeaturesModel(log)
 
 	return PageModel{
 		pages: []tea.Model{
@@ -30,6 +31,7 @@ func CreatePageModel(log *EggLog) PageModel {
 			server,
 			database,
 			mini,
+			features,
 		},
 		currentPage: 0,
 		eggl:        log,
@@ -103,6 +105,7 @@ func (m *PageModel) headerLine() string {
 		"License",
 		"Database",
 		"S3",
+		"Features",
 	}
 	tabs := make([]string, 0)
 
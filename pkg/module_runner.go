@@ -1,12 +1,21 @@
 package pkg
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
 
 	"github.com/adamkali/egg_cli/pkg/configuration"
 	"github.com/adamkali/egg_cli/pkg/models"
 	"github.com/adamkali/egg_cli/pkg/modules"
+	"github.com/adamkali/egg_cli/pkg/targets"
+	"github.com/adamkali/egg_cli/pkg/templates"
 	"github.com/adamkali/egg_cli/styles"
 	"gopkg.in/yaml.v3"
 )
@@ -15,109 +24,560 @@ const (
 	ScrambledFileName = ".scrambled"
 )
 
+// ErrRolledBack wraps the error of the failed module after a successful rollback
+var ErrRolledBack = errors.New("project was rolled back")
+
 var (
-	Modules = []modules.IModule{
-		&modules.InitializeModule{},
-		&modules.InstallToolsModule{},
-		&modules.InstallLibrariesModule{},
-		&modules.BootstrapDirectoriesModule{},
-		&modules.GenerateConfigurationModule{},
-		&modules.BootstrapFrameworkFilesFromTemplatesModule{},
-		&modules.RsbuildFrontendModule{},
-	}
+	// Registry provides the modules of every run, ordered by their dependencies
+	Registry = modules.DefaultRegistry
 )
 
+// Options
+//
+// description:
+//
+//	Options changes how ProjectFactory runs the modules. The zero value runs every
+//	module and leaves a .scrambled file behind when one of them fails.
+type Options struct {
+	// Transactional records the undo actions of every module so that a failed
+	// run can be rolled back to the state before it started
+	Transactional bool
+	// ConfirmRollback is asked in transactional mode when a module fails. Returning
+	// true rolls the project back, false keeps it and writes the .scrambled file.
+	// A nil ConfirmRollback always rolls back.
+	ConfirmRollback func(failed modules.IModule, err error) bool
+	// Progress shows a live progress view instead of printing every step, it
+	// should only be used when stdout is a terminal
+	Progress bool
+	// Offline runs without network access, see modules.Offline
+	Offline modules.Offline
+	// Workspace is where the project is created, an empty Dir is the project
+	// name inside of the working directory
+	Workspace modules.Workspace
+	// Templates is a directory of templates that override or add to templates.Mapping
+	// by output path, empty uses the built-in templates alone (see templates.OverrideDir)
+	Templates string
+	// ReplaceTools installs the pinned version of a tool that is outdated outside of GOBIN,
+	// see modules.IReplaceTools
+	ReplaceTools bool
+}
+
+// RecoverOptions
+//
+// description:
+//
+//	RecoverOptions selects which modules RecoverFromScrambled runs. The zero value
+//	runs every module that did not succeed yet. The checkpoints of the previous
+//	runs are applied either way, so steps that already completed are skipped.
+type RecoverOptions struct {
+	// From reruns the named module and every module after it
+	From string
+	// Only reruns the named module and nothing else
+	Only string
+	// Progress shows a live progress view, see Options
+	Progress bool
+	// Offline runs without network access, see modules.Offline
+	Offline modules.Offline
+	// Dir is the project directory with the .scrambled file, empty is the working directory
+	Dir string
+	// Templates is the template directory the project was created with, see Options
+	Templates string
+	// ReplaceTools installs the pinned version of outdated tools, see Options
+	ReplaceTools bool
+}
+
 type scrambleFile struct {
-	Failed struct {
-		ModuleName string `yaml:"moduleName"`
-		Error      string `yaml:"error"`
-	}
-	Succeeded     []string                    `yaml:"succeeded"`
+	Failed    *scrambleFailure `yaml:"failed,omitempty"`
+	Succeeded []string         `yaml:"succeeded"`
+	// Checkpoints are the completed steps of every module by module name
+	Checkpoints   map[string][]string         `yaml:"checkpoints,omitempty"`
 	Configuration configuration.Configuration `yaml:"configuration"`
 }
 
-func PrintError(m modules.IModule, eggl *models.EggLog) bool {
-	if m.IsError() != nil {
-		styles.EggProgressError.Render(fmt.Sprintf("🥚 %s encountered error: %v", m.Name(), m.IsError().Error()))
-		eggl.Error("error: %s", m.IsError().Error())
+type scrambleFailure struct {
+	ModuleName string `yaml:"moduleName"`
+	Error      string `yaml:"error"`
+}
+
+// Scrambled is the state of a failed project loaded from the .scrambled file
+type Scrambled struct {
+	Configuration *configuration.Configuration
+	// Modules is every module of the pipeline in execution order
+	Modules []modules.IModule
+	// Succeeded are the modules that completed, Pending the ones that did not
+	Succeeded   []modules.IModule
+	Pending     []modules.IModule
+	Checkpoints map[string][]string
+}
+
+func PrintError(m modules.IModule, err error, eggl *models.EggLog) bool {
+	if err != nil {
+		fmt.Println(styles.EggProgressError.Render(fmt.Sprintf("🥚 %s encountered error: %v", m.Name(), err.Error())))
+		eggl.Error("error: %s", err.Error())
 		return true
 	}
 	return false
 }
 
-func ProjectFactory(configuration *configuration.Configuration, eggl *models.EggLog) error {
-	var err error
-	var succeededModules []modules.IModule
-	for _, module := range Modules {
-		module.LoadFromConfig(configuration, eggl)
-		module.Run()
-		err = module.IsError()
-		if PrintError(module, eggl) {
-			// if there is an error, then we to still write the .scrambled file
-			err = WriteScrambled(configuration, succeededModules, module, err)
-			// print out to check the Scrambled file
-			fmt.Println("check the .scrambled for the breaking error and what module failed")
+// loadModule loads a module from the configuration, resumable modules skip the completed steps
+func loadModule(
+	module modules.IModule,
+	configuration *configuration.Configuration,
+	eggl *models.EggLog,
+	completed []string,
+) {
+	module.LoadFromConfig(configuration, eggl)
+	if resumable, ok := module.(modules.IResumable); ok {
+		resumable.Resume(completed)
+	}
+}
+
+// runModule runs a single loaded module and stamps how long it took on its result
+func runModule(ctx context.Context, module modules.IModule) (modules.Result, error) {
+	start := time.Now()
+	result, err := module.Run(ctx)
+	result.Duration = time.Since(start)
+	if result.Module == "" {
+		result.Module = module.Name()
+	}
+	return result, err
+}
+
+// execution is the outcome of running a pipeline
+type execution struct {
+	// results of the modules that ran and the modules that succeeded, in pipeline order
+	results   []modules.Result
+	succeeded []modules.IModule
+	// failed is the module that failed first, nil if every module succeeded
+	failed modules.IModule
+	err    error
+}
+
+// executePipeline
+//
+// params:
+//
+//	ctx: context.Context
+//	title: string
+//	pipeline: []modules.IModule
+//	configuration: *configuration.Configuration
+//	eggl: *models.EggLog
+//	checkpoints: map[string][]string
+//	offline: modules.Offline
+//	workspace: modules.Workspace
+//	mapping: map[string]*template.Template
+//	replaceTools: bool
+//	showProgress: bool
+//
+// returns:
+//
+//	execution: the results of every module that ran and the module that failed
+//
+// description:
+//
+//	Loads every module of the pipeline and runs them with the scheduler, so that
+//	modules which do not depend on each other run at the same time. The steps the
+//	modules complete are added to checkpoints. With showProgress the progress view
+//	is shown while the modules run, otherwise every module prints its own steps.
+func executePipeline(
+	ctx context.Context,
+	title string,
+	pipeline []modules.IModule,
+	configuration *configuration.Configuration,
+	eggl *models.EggLog,
+	checkpoints map[string][]string,
+	offline modules.Offline,
+	workspace modules.Workspace,
+	mapping map[string]*template.Template,
+	replaceTools bool,
+	showProgress bool,
+) execution {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	state := newProgressState(pipeline)
+
+	// the modules are loaded up front so that the scheduler can ask them whether they are interactive
+	for _, module := range pipeline {
+		logger := eggl
+		if showProgress {
+			logger = eggl.WithTail(func(line string) { state.log(module, line) })
+		}
+		loadModule(module, configuration, logger, checkpoints[module.Name()])
+		if o, ok := module.(modules.IOffline); ok {
+			o.SetOffline(offline)
+		}
+		if w, ok := module.(modules.IWorkspace); ok {
+			w.SetWorkspace(workspace)
+		}
+		if t, ok := module.(modules.ITemplates); ok {
+			t.SetTemplates(mapping)
+		}
+		if r, ok := module.(modules.IReplaceTools); ok {
+			r.SetReplaceTools(replaceTools)
+		}
+	}
+
+	var mu sync.Mutex
+	results := make(map[string]modules.Result, len(pipeline))
+	run := func(module modules.IModule) error {
+		state.start(module)
+		result, err := runModule(ctx, module)
+		mu.Lock()
+		results[module.Name()] = result
+		addCheckpoints(checkpoints, module, result)
+		mu.Unlock()
+		state.finish(module, err)
+		PrintError(module, err, eggl)
+		return err
+	}
+
+	exclusive := func(run func()) { run() }
+	if showProgress {
+		view := &progressView{title: title, state: state, cancel: cancel, eggl: eggl}
+		view.start()
+		defer view.stop()
+		exclusive = view.exclusive
+	}
+
+	var done execution
+	done.failed, done.err = schedule(ctx, pipeline, dependsOn, exclusive, run)
+	for _, module := range pipeline {
+		result, ok := results[module.Name()]
+		if !ok {
+			continue
+		}
+		done.results = append(done.results, result)
+		if !state.failed(module) {
+			done.succeeded = append(done.succeeded, module)
+		}
+	}
+	return done
+}
+
+// checkOffline fails fast, before anything is created, when an offline run is missing a library or a tool
+func checkOffline(
+	ctx context.Context,
+	offline modules.Offline,
+	workspace modules.Workspace,
+	cfg *configuration.Configuration,
+	mapping map[string]*template.Template,
+	eggl *models.EggLog,
+) error {
+	if !offline.Enabled {
+		return nil
+	}
+	// a drift is reported by egg::install_libraries, the modules are still the ones it requires
+	required, err := modules.RequiredModules(cfg, mapping)
+	var drift *templates.DriftError
+	if err != nil && !errors.As(err, &drift) {
+		return err
+	}
+	var imports []string
+	if cfg != nil {
+		if mapping == nil {
+			mapping = templates.Mapping(cfg)
+		}
+		if imports, err = templates.Imports(cfg, mapping); err != nil {
 			return err
 		}
-		succeededModules = append(succeededModules, module)
 	}
+	lock, err := targets.LoadLock(filepath.Join(workspace.Dir, targets.LockFileName))
+	if err != nil {
+		return err
+	}
+	tools := new(modules.InstallToolsModule).Inspect(ctx, lock.Tools)
+	if err := modules.CheckOffline(ctx, offline, required, imports, tools); err != nil {
+		eggl.Error("error: %s", err.Error())
+		return err
+	}
+	eggl.Info("Every library and tool is available offline")
 	return nil
 }
 
-func RecoverFromScrambled(eggl *models.EggLog) error {
-	configuration, succeededModules, failedModules, err := LoadScrambled()
+// projectWorkspace returns the workspace with an absolute Dir, defaulting to name inside of the working directory
+func projectWorkspace(workspace modules.Workspace, name string) (modules.Workspace, error) {
+	dir := workspace.Dir
+	if dir == "" {
+		dir = name
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return workspace, fmt.Errorf("failed to resolve the project directory %s: %w", dir, err)
+	}
+	workspace.Dir = abs
+	return workspace, nil
+}
+
+// dependsOn returns the IDs of the modules the named module depends on
+func dependsOn(name string) []string {
+	dependencies := Registry.DependsOn(name)
+	for i, dependency := range dependencies {
+		dependencies[i] = resolveModuleName(dependency)
+	}
+	return dependencies
+}
+
+// ProjectFactory
+//
+// params:
+//
+//	ctx: context.Context
+//	configuration: *configuration.Configuration
+//	eggl: *models.EggLog
+//	options: Options
+//
+// returns:
+//
+//	error:
+//	  - if the modules could not be ordered by their dependencies
+//	  - the error of the module that failed first, wrapped in ErrRolledBack after a rollback
+//
+// description:
+//
+//	Creates the project by running every registered module, modules that do not
+//	depend on each other run at the same time. When a module fails the project is
+//	either rolled back (see Options) or a .scrambled file is written for `egg_cli recover`.
+func ProjectFactory(
+	ctx context.Context,
+	configuration *configuration.Configuration,
+	eggl *models.EggLog,
+	options Options,
+) error {
+	pipeline, err := Registry.Modules()
+	if err != nil {
+		return err
+	}
+	workspace, err := projectWorkspace(options.Workspace, configuration.Name)
+	if err != nil {
+		return err
+	}
+	// nothing is created, not even the .scrambled file, in a directory that may not be touched
+	if err := modules.CheckWorkspace(workspace); err != nil {
+		return err
+	}
+	// a template that does not parse fails the run before anything is created
+	mapping, err := templates.Load(configuration, options.Templates)
+	if err != nil {
+		return err
+	}
+	if err := checkOffline(ctx, options.Offline, workspace, configuration, mapping, eggl); err != nil {
+		return err
+	}
+	checkpoints := make(map[string][]string)
+	done := executePipeline(ctx, configuration.Name, pipeline, configuration, eggl, checkpoints, options.Offline, workspace, mapping, options.ReplaceTools, options.Progress)
+	fmt.Println(RenderSummary(done.results))
+	transaction := new(modules.Transaction)
+	for _, result := range done.results {
+		transaction.Add(result)
+	}
+	if done.failed == nil {
+		commit(transaction, eggl)
+		return nil
+	}
+
+	if options.Transactional && (options.ConfirmRollback == nil || options.ConfirmRollback(done.failed, done.err)) {
+		return rollback(transaction, done.err, eggl)
+	}
+	commit(transaction, eggl)
+	// if there is an error, then we to still write the .scrambled file
+	if bigErr := WriteScrambled(workspace.Dir, configuration, done.succeeded, done.failed, done.err, checkpoints); bigErr != nil {
+		return fmt.Errorf("failed to write .scrambled file: %w", bigErr)
+	}
+	// print out to check the Scrambled file
+	fmt.Println("check " + filepath.Join(workspace.Dir, ScrambledFileName) + " for the breaking error and what module failed")
+	return done.err
+}
+
+// rollback undoes every change recorded in the transaction and returns the
+// error of the module that failed, or the rollback error if it did not succeed
+func rollback(transaction *modules.Transaction, moduleErr error, eggl *models.EggLog) error {
+	eggl.Info("Rolling back %d changes", transaction.Len())
+	fmt.Println(styles.EggProgressInfo.Render(fmt.Sprintf("🥚 rolling back %d changes", transaction.Len())))
+	if err := transaction.Rollback(); err != nil {
+		eggl.Error("error: rollback failed: %s", err.Error())
+		return fmt.Errorf("rollback failed after %w: %w", moduleErr, err)
+	}
+	eggl.Info("Rollback complete")
+	return fmt.Errorf("%w: %w", ErrRolledBack, moduleErr)
+}
+
+// commit keeps every change of the run and removes what was only kept for a rollback,
+// such as the project directory replaced by --force
+func commit(transaction *modules.Transaction, eggl *models.EggLog) {
+	if err := transaction.Commit(); err != nil {
+		// the project itself is fine, only a backup is left behind
+		eggl.Error("error: %s", err.Error())
+		fmt.Println(styles.EggProgressError.Render("🥚 failed to clean up after the run: " + err.Error()))
+	}
+}
+
+// addCheckpoints records the steps the module completed in this run
+func addCheckpoints(checkpoints map[string][]string, module modules.IModule, result modules.Result) {
+	if len(result.Checkpoints) > 0 {
+		checkpoints[module.Name()] = append(checkpoints[module.Name()], result.Checkpoints...)
+	}
+}
+
+// RecoverFromScrambled
+//
+// params:
+//
+//	ctx: context.Context
+//	eggl: *models.EggLog
+//	options: RecoverOptions
+//
+// returns:
+//
+//	error:
+//	  - if the .scrambled file could not be loaded or names an unknown module
+//	  - the error of the module that failed again
+//
+// description:
+//
+//	Reruns the modules selected by options with the configuration and checkpoints
+//	stored in the .scrambled file. Every module only runs the steps that were not
+//	completed before, so recovering twice does not repeat any work. The .scrambled
+//	file is updated after every run and removed once every module has succeeded.
+func RecoverFromScrambled(ctx context.Context, eggl *models.EggLog, options RecoverOptions) error {
+	scrambled, err := LoadScrambledFrom(options.Dir)
 	if err != nil {
 		return fmt.Errorf("failed to load .scrambled file: %w", err)
 	}
+	// the project already exists, so a recovery always writes into it and replaces
+	// the files the failed run had not finished
+	workspace, err := projectWorkspace(modules.Workspace{Dir: options.Dir, Overwrite: modules.OverwriteMerge, Conflicts: modules.ResolveOverwrite}, ".")
+	if err != nil {
+		return err
+	}
 
-	if len(failedModules) == 0 {
+	selected, err := selectRecoverModules(scrambled, options)
+	if err != nil {
+		return err
+	}
+	if len(selected) == 0 {
 		eggl.Info("No failed modules to recover - all modules completed successfully")
 		return nil
 	}
 
-	eggl.Info("Recovering %d failed modules", len(failedModules))
+	mapping, err := templates.Load(scrambled.Configuration, options.Templates)
+	if err != nil {
+		return err
+	}
+	if err := checkOffline(ctx, options.Offline, workspace, scrambled.Configuration, mapping, eggl); err != nil {
+		return err
+	}
+	eggl.Info("Recovering %d modules", len(selected))
 
-	for _, module := range failedModules {
-		eggl.Info("Attempting to recover module: %s", module.Name())
+	checkpoints := scrambled.Checkpoints
+	done := executePipeline(ctx, scrambled.Configuration.Name, selected, scrambled.Configuration, eggl, checkpoints, options.Offline, workspace, mapping, options.ReplaceTools, options.Progress)
+	fmt.Println(RenderSummary(done.results))
 
-		module.LoadFromConfig(configuration, eggl)
-		module.Run()
-		err = module.IsError()
+	succeededModules := scrambled.Succeeded
+	for _, module := range done.succeeded {
+		eggl.Info("Successfully recovered module: %s", module.Name())
+		if !containsModule(succeededModules, module.Name()) {
+			succeededModules = append(succeededModules, module)
+		}
+	}
+	if done.failed != nil {
+		// if there is an error, then we have to write the .scrambled file
+		bigErr := WriteScrambled(workspace.Dir, scrambled.Configuration, succeededModules, done.failed, done.err, checkpoints)
+		if bigErr != nil {
+			return fmt.Errorf("failed to write .scrambled file: %w", bigErr)
+		}
+		// print out to check the Scrambled file
+		fmt.Println("check the .scrambled for the breaking error and what module failed")
+		return done.err
+	}
 
-		if PrintError(module, eggl) {
-			// if there is an error, then we have to write the .scrambled file
-			bigErr := WriteScrambled(configuration, succeededModules, module, err)
-			if bigErr != nil {
-				return fmt.Errorf("failed to write .scrambled file: %w", bigErr)
+	for _, module := range scrambled.Modules {
+		if !containsModule(succeededModules, module.Name()) {
+			// --only recovered a single module, keep the rest for the next run
+			eggl.Info("Recovered %d modules, %s has not succeeded yet", len(selected), module.Name())
+			return WriteScrambled(workspace.Dir, scrambled.Configuration, succeededModules, nil, nil, checkpoints)
+		}
+	}
+	eggl.Info("All modules recovered successfully")
+	if err := os.Remove(filepath.Join(workspace.Dir, ScrambledFileName)); err != nil {
+		return fmt.Errorf("failed to remove .scrambled file: %w", err)
+	}
+	return nil
+}
+
+// selectRecoverModules returns the modules RecoverFromScrambled has to run
+func selectRecoverModules(scrambled *Scrambled, options RecoverOptions) ([]modules.IModule, error) {
+	switch {
+	case options.Only != "":
+		module := findModule(scrambled.Modules, options.Only)
+		if module == nil {
+			return nil, unknownModuleError(scrambled.Modules, options.Only)
+		}
+		return []modules.IModule{module}, nil
+	case options.From != "":
+		from := resolveModuleName(options.From)
+		for i, module := range scrambled.Modules {
+			if module.Name() == from {
+				return scrambled.Modules[i:], nil
 			}
-			// print out to check the Scrambled file
-			fmt.Println("check the .scrambled for the breaking error and what module failed")
-			return err
 		}
+		return nil, unknownModuleError(scrambled.Modules, options.From)
+	default:
+		return scrambled.Pending, nil
+	}
+}
 
-		eggl.Info("Successfully recovered module: %s", module.Name())
-		succeededModules = append(succeededModules, module)
+func unknownModuleError(pipeline []modules.IModule, name string) error {
+	names := make([]string, len(pipeline))
+	for i, module := range pipeline {
+		names[i] = module.Name()
 	}
+	return fmt.Errorf("unknown module %q, expected one of: %s", name, strings.Join(names, ", "))
+}
 
-	eggl.Info("All modules recovered successfully")
+// findModule returns the module of the pipeline with the given ID or alias
+func findModule(pipeline []modules.IModule, name string) modules.IModule {
+	name = resolveModuleName(name)
+	for _, module := range pipeline {
+		if module.Name() == name {
+			return module
+		}
+	}
 	return nil
 }
 
+// resolveModuleName returns the ID of a module for one of its aliases, or name itself
+func resolveModuleName(name string) string {
+	if id, ok := Registry.Resolve(name); ok {
+		return id
+	}
+	return name
+}
+
+func containsModule(list []modules.IModule, name string) bool {
+	for _, module := range list {
+		if module.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
 func CheckScrambled() bool {
 	_, err := os.Stat(ScrambledFileName)
 	return !os.IsNotExist(err)
 }
 
-func LoadScrambled() (
-	*configuration.Configuration,
-	[]modules.IModule,
-	[]modules.IModule,
-	error,
-) {
+// LoadScrambled loads the .scrambled file of the working directory
+func LoadScrambled() (*Scrambled, error) {
+	return LoadScrambledFrom("")
+}
+
+// LoadScrambledFrom loads the .scrambled file of the project in dir, empty is the working directory
+func LoadScrambledFrom(dir string) (*Scrambled, error) {
 	// open the .scrambled file
-	file, err := os.Open(ScrambledFileName)
+	file, err := os.Open(filepath.Join(dir, ScrambledFileName))
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to open .scrambled file: %w", err)
+		return nil, fmt.Errorf("failed to open .scrambled file: %w", err)
 	}
 	defer file.Close()
 
@@ -125,37 +585,56 @@ func LoadScrambled() (
 	var scramble scrambleFile
 	err = yaml.NewDecoder(file).Decode(&scramble)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to decode .scrambled file: %w", err)
+		return nil, fmt.Errorf("failed to decode .scrambled file: %w", err)
+	}
+
+	pipeline, err := Registry.Modules()
+	if err != nil {
+		return nil, err
+	}
+
+	scrambled := &Scrambled{
+		Configuration: &scramble.Configuration,
+		Modules:       pipeline,
+		Succeeded:     make([]modules.IModule, 0, len(scramble.Succeeded)),
+		Checkpoints:   make(map[string][]string, len(scramble.Checkpoints)),
+	}
+	// older .scrambled files may record a module by one of its aliases
+	for moduleName, steps := range scramble.Checkpoints {
+		id := resolveModuleName(moduleName)
+		scrambled.Checkpoints[id] = append(scrambled.Checkpoints[id], steps...)
 	}
 
 	// Create a map for O(1) lookup of succeeded modules
 	succeededMap := make(map[string]bool)
-	succeededModules := make([]modules.IModule, 0, len(scramble.Succeeded))
-
 	for _, moduleName := range scramble.Succeeded {
-		module := modules.ModuleFactory(moduleName)
+		module := findModule(pipeline, moduleName)
 		if module == nil {
-			return nil, nil, nil, fmt.Errorf("module not found: %s", moduleName)
+			return nil, fmt.Errorf("module not found: %s", moduleName)
 		}
-		succeededModules = append(succeededModules, module)
-		succeededMap[moduleName] = true
+		scrambled.Succeeded = append(scrambled.Succeeded, module)
+		succeededMap[module.Name()] = true
 	}
 
-	// Find failed modules using efficient map lookup
-	var failedModules []modules.IModule
-	for _, module := range Modules {
+	// Find pending modules using efficient map lookup
+	for _, module := range pipeline {
 		if !succeededMap[module.Name()] {
-			failedModules = append(failedModules, module)
+			scrambled.Pending = append(scrambled.Pending, module)
 		}
 	}
 
-	return &scramble.Configuration, succeededModules, failedModules, nil
+	return scrambled, nil
 }
 
 // WriteScrambled
 //
 // params:
 //
+//	dir:
+//	  type: string
+//	  description:
+//	    the project directory the .scrambled file is written to, so that
+//	    `egg_cli recover` finds it from inside of the project
 //	configuration:
 //	  type: *configuration.Configuration
 //	  description:
@@ -170,11 +649,16 @@ func LoadScrambled() (
 //	failed:
 //	  type: modules.IModule
 //	  description:
-//	    the module that failed and can be written to the .scrambled file
+//	    the module that failed and can be written to the .scrambled file,
+//	    nil when a recovery succeeded but other modules are still pending
 //	err:
 //	  type: error
 //	  description:
 //	    the error that occurred during the project creation
+//	checkpoints:
+//	  type: map[string][]string
+//	  description:
+//	    the completed steps of every module, so that a recovery skips them
 //
 // returns:
 //
@@ -183,20 +667,28 @@ func LoadScrambled() (
 //
 // description:
 //
-//		This function is used to write the .scrambled file to the current directory
+//		This function is used to write the .scrambled file to the project directory
 //	 if there were any errors during the project creation. The .scrambled file
 //	 contains the modules that were successfully run and the module that failed
 //	 this allows us to recreate the state of the project from the .scrambled file
 //	 and re-run the failed module by trusting that the rest of the modules were
 //	 correct
 func WriteScrambled(
+	dir string,
 	configuration *configuration.Configuration,
 	succeeded []modules.IModule,
 	failed modules.IModule,
 	ModuleError error,
+	checkpoints map[string][]string,
 ) error {
+	// a project that failed before its directory was created still gets one for the .scrambled file
+	if dir != "" {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return fmt.Errorf("failed to create the project directory: %w", err)
+		}
+	}
 	// Create or truncate the .scrambled file
-	f, err := os.Create(ScrambledFileName)
+	f, err := os.Create(filepath.Join(dir, ScrambledFileName))
 	if err != nil {
 		return fmt.Errorf("failed to create .scrambled file: %w", err)
 	}
@@ -210,16 +702,16 @@ func WriteScrambled(
 
 	// Create scramble file structure
 	scr := scrambleFile{
-		Failed: struct {
-			ModuleName string `yaml:"moduleName"`
-			Error      string `yaml:"error"`
-		}{
-			ModuleName: failed.Name(),
-			Error:      ModuleError.Error(),
-		},
 		Succeeded:     succeededNames,
+		Checkpoints:   checkpoints,
 		Configuration: *configuration,
 	}
+	if failed != nil {
+		scr.Failed = &scrambleFailure{ModuleName: failed.Name()}
+		if ModuleError != nil {
+			scr.Failed.Error = ModuleError.Error()
+		}
+	}
 
 	// Marshal the scramble file
 	scrambled, err := yaml.Marshal(scr)
@@ -0,0 +1,430 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/adamkali/egg_cli/pkg/configuration"
+	"github.com/adamkali/egg_cli/pkg/models"
+	"github.com/adamkali/egg_cli/pkg/modules"
+)
+
+// fakeModule writes a single file and can be told to fail afterwards
+type fakeModule struct {
+	name string
+	file string
+	err  error
+}
+
+func (m *fakeModule) Name() string                                                { return m.name }
+func (m *fakeModule) LoadFromConfig(*configuration.Configuration, *models.EggLog) {}
+func (m *fakeModule) Describe() modules.Plan                                      { return modules.Plan{Module: m.name} }
+func (m *fakeModule) Run(ctx context.Context) (modules.Result, error) {
+	result := modules.Result{Module: m.name}
+	if m.file != "" {
+		file := m.file
+		result.Undo = append(result.Undo, modules.UndoAction{
+			Description: "remove " + file,
+			Undo:        func() error { return os.Remove(file) },
+		})
+		if err := os.WriteFile(file, []byte(m.name), 0644); err != nil {
+			return result, err
+		}
+		result.Files = append(result.Files, file)
+	}
+	return result, m.err
+}
+
+func setupFakeModules(t *testing.T, fakes ...modules.IModule) *models.EggLog {
+	t.Chdir(t.TempDir())
+	previous := Registry
+	Registry = modules.NewRegistry()
+	// every fake depends on the one before it, so they run one after another
+	for i, fake := range fakes {
+		var dependsOn []string
+		if i > 0 {
+			dependsOn = append(dependsOn, fakes[i-1].Name())
+		}
+		Registry.Register(fake.Name(), func() modules.IModule { return fake }, dependsOn...)
+	}
+	t.Cleanup(func() { Registry = previous })
+
+	logger, err := models.NewLogger(filepath.Join(t.TempDir(), "test.log"))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	t.Cleanup(func() { logger.Close() })
+	return logger
+}
+
+func TestProjectFactory_WritesScrambledOnFailure(t *testing.T) {
+	logger := setupFakeModules(t,
+		&fakeModule{name: "egg::first", file: "first.txt"},
+		&fakeModule{name: "egg::second", err: errors.New("simulated error")},
+	)
+
+	err := ProjectFactory(context.Background(), new(configuration.Configuration), logger, Options{})
+	if err == nil || errors.Is(err, ErrRolledBack) {
+		t.Fatalf("ProjectFactory() error = %v, want the module error", err)
+	}
+	if !CheckScrambled() {
+		t.Error("ProjectFactory() did not write the .scrambled file")
+	}
+	if _, err := os.Stat("first.txt"); err != nil {
+		t.Error("ProjectFactory() removed files without being transactional")
+	}
+}
+
+func TestProjectFactory_RollsBackOnFailure(t *testing.T) {
+	logger := setupFakeModules(t,
+		&fakeModule{name: "egg::first", file: "first.txt"},
+		&fakeModule{name: "egg::second", file: "second.txt", err: errors.New("simulated error")},
+	)
+
+	asked := false
+	err := ProjectFactory(context.Background(), new(configuration.Configuration), logger, Options{
+		Transactional: true,
+		ConfirmRollback: func(failed modules.IModule, err error) bool {
+			asked = failed.Name() == "egg::second"
+			return true
+		},
+	})
+	if !errors.Is(err, ErrRolledBack) {
+		t.Fatalf("ProjectFactory() error = %v, want ErrRolledBack", err)
+	}
+	if !asked {
+		t.Error("ProjectFactory() did not ask to roll back the failed module")
+	}
+	for _, file := range []string{"first.txt", "second.txt"} {
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("%s was not rolled back", file)
+		}
+	}
+	if CheckScrambled() {
+		t.Error("ProjectFactory() wrote a .scrambled file after rolling back")
+	}
+}
+
+func TestProjectFactory_DeclinedRollback(t *testing.T) {
+	logger := setupFakeModules(t,
+		&fakeModule{name: "egg::first", file: "first.txt"},
+		&fakeModule{name: "egg::second", err: errors.New("simulated error")},
+	)
+
+	err := ProjectFactory(context.Background(), new(configuration.Configuration), logger, Options{
+		Transactional:   true,
+		ConfirmRollback: func(modules.IModule, error) bool { return false },
+	})
+	if err == nil || errors.Is(err, ErrRolledBack) {
+		t.Fatalf("ProjectFactory() error = %v, want the module error", err)
+	}
+	if _, err := os.Stat("first.txt"); err != nil {
+		t.Error("ProjectFactory() rolled back after the rollback was declined")
+	}
+	if !CheckScrambled() {
+		t.Error("ProjectFactory() did not write the .scrambled file after the rollback was declined")
+	}
+}
+
+// workspaceModule records the workspace it was given and fails
+type workspaceModule struct {
+	fakeModule
+	workspace modules.Workspace
+	ran       bool
+}
+
+func (m *workspaceModule) SetWorkspace(workspace modules.Workspace) { m.workspace = workspace }
+func (m *workspaceModule) Run(ctx context.Context) (modules.Result, error) {
+	m.ran = true
+	return m.fakeModule.Run(ctx)
+}
+
+func TestProjectFactory_Workspace(t *testing.T) {
+	module := &workspaceModule{fakeModule: fakeModule{name: "egg::workspace", err: errors.New("simulated error")}}
+	logger := setupFakeModules(t, module)
+	dir := filepath.Join(t.TempDir(), "app")
+
+	err := ProjectFactory(context.Background(), new(configuration.Configuration), logger, Options{
+		Workspace: modules.Workspace{Dir: dir},
+	})
+	if err == nil {
+		t.Fatal("ProjectFactory() error = nil, want the module error")
+	}
+	if module.workspace.Dir != dir {
+		t.Errorf("workspace = %q, want %q", module.workspace.Dir, dir)
+	}
+	// the .scrambled file is written into the project, not the working directory
+	if _, err := os.Stat(filepath.Join(dir, ScrambledFileName)); err != nil {
+		t.Errorf("ProjectFactory() did not write the .scrambled file into %s: %v", dir, err)
+	}
+	if CheckScrambled() {
+		t.Error("ProjectFactory() wrote the .scrambled file into the working directory")
+	}
+	if _, err := LoadScrambledFrom(dir); err != nil {
+		t.Errorf("LoadScrambledFrom() error = %v", err)
+	}
+
+	// the project directory has files in it now
+	module.ran = false
+	err = ProjectFactory(context.Background(), new(configuration.Configuration), logger, Options{
+		Workspace: modules.Workspace{Dir: dir},
+	})
+	var existsErr *modules.ProjectExistsError
+	if !errors.As(err, &existsErr) {
+		t.Fatalf("ProjectFactory() error = %v, want a ProjectExistsError", err)
+	}
+	if module.ran {
+		t.Error("ProjectFactory() ran a module in a project directory that may not be touched")
+	}
+}
+
+// stepsModule completes its steps in order and fails at failAt, skipping resumed steps
+type stepsModule struct {
+	name      string
+	steps     []string
+	failAt    string
+	completed map[string]bool
+	ran       []string
+}
+
+func (m *stepsModule) Name() string                                                { return m.name }
+func (m *stepsModule) LoadFromConfig(*configuration.Configuration, *models.EggLog) {}
+func (m *stepsModule) Describe() modules.Plan                                      { return modules.Plan{Module: m.name} }
+func (m *stepsModule) Resume(completed []string) {
+	m.completed = make(map[string]bool)
+	for _, step := range completed {
+		m.completed[step] = true
+	}
+}
+func (m *stepsModule) Run(ctx context.Context) (modules.Result, error) {
+	result := modules.Result{Module: m.name}
+	for _, step := range m.steps {
+		if m.completed[step] {
+			continue
+		}
+		if step == m.failAt {
+			return result, errors.New("simulated error at " + step)
+		}
+		m.ran = append(m.ran, step)
+		result.Checkpoints = append(result.Checkpoints, step)
+	}
+	return result, nil
+}
+
+func TestRecoverFromScrambled_ResumesFromCheckpoints(t *testing.T) {
+	steps := &stepsModule{name: "egg::steps", steps: []string{"a", "b", "c"}, failAt: "b"}
+	logger := setupFakeModules(t, &fakeModule{name: "egg::first"}, steps)
+
+	if err := ProjectFactory(context.Background(), new(configuration.Configuration), logger, Options{}); err == nil {
+		t.Fatal("ProjectFactory() error = nil, want the module error")
+	}
+	scrambled, err := LoadScrambled()
+	if err != nil {
+		t.Fatalf("LoadScrambled() error = %v", err)
+	}
+	if got := scrambled.Checkpoints["egg::steps"]; !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("Checkpoints = %v, want [a]", got)
+	}
+	if len(scrambled.Pending) != 1 || scrambled.Pending[0].Name() != "egg::steps" {
+		t.Errorf("Pending = %v, want egg::steps", scrambled.Pending)
+	}
+
+	steps.failAt = ""
+	steps.ran = nil
+	if err := RecoverFromScrambled(context.Background(), logger, RecoverOptions{}); err != nil {
+		t.Fatalf("RecoverFromScrambled() error = %v", err)
+	}
+	if want := []string{"b", "c"}; !reflect.DeepEqual(steps.ran, want) {
+		t.Errorf("recover ran %v, want %v", steps.ran, want)
+	}
+	if CheckScrambled() {
+		t.Error("RecoverFromScrambled() kept the .scrambled file after every module succeeded")
+	}
+}
+
+func TestRecoverFromScrambled_Only(t *testing.T) {
+	second := &stepsModule{name: "egg::second", steps: []string{"a"}, failAt: "a"}
+	third := &stepsModule{name: "egg::third", steps: []string{"a"}}
+	logger := setupFakeModules(t, &fakeModule{name: "egg::first"}, second, third)
+
+	if err := ProjectFactory(context.Background(), new(configuration.Configuration), logger, Options{}); err == nil {
+		t.Fatal("ProjectFactory() error = nil, want the module error")
+	}
+	if err := RecoverFromScrambled(context.Background(), logger, RecoverOptions{Only: "egg::third"}); err != nil {
+		t.Fatalf("RecoverFromScrambled() error = %v", err)
+	}
+	if len(second.ran) != 0 || len(third.ran) != 1 {
+		t.Errorf("--only ran second %v and third %v, want only third", second.ran, third.ran)
+	}
+
+	scrambled, err := LoadScrambled()
+	if err != nil {
+		t.Fatalf("LoadScrambled() error = %v", err)
+	}
+	if len(scrambled.Pending) != 1 || scrambled.Pending[0].Name() != "egg::second" {
+		t.Errorf("Pending = %v, want egg::second", scrambled.Pending)
+	}
+}
+
+func TestRecoverFromScrambled_From(t *testing.T) {
+	first := &stepsModule{name: "egg::first", steps: []string{"a"}}
+	second := &stepsModule{name: "egg::second", steps: []string{"a", "b"}, failAt: "b"}
+	logger := setupFakeModules(t, first, second)
+
+	if err := ProjectFactory(context.Background(), new(configuration.Configuration), logger, Options{}); err == nil {
+		t.Fatal("ProjectFactory() error = nil, want the module error")
+	}
+	first.ran, second.ran, second.failAt = nil, nil, ""
+	if err := RecoverFromScrambled(context.Background(), logger, RecoverOptions{From: "egg::first"}); err != nil {
+		t.Fatalf("RecoverFromScrambled() error = %v", err)
+	}
+	// the first module is rerun, but its only step was already completed
+	if len(first.ran) != 0 {
+		t.Errorf("--from repeated the completed steps %v", first.ran)
+	}
+	if want := []string{"b"}; !reflect.DeepEqual(second.ran, want) {
+		t.Errorf("--from ran %v, want %v", second.ran, want)
+	}
+}
+
+func TestRecoverFromScrambled_UnknownModule(t *testing.T) {
+	logger := setupFakeModules(t, &fakeModule{name: "egg::first", err: errors.New("simulated error")})
+
+	if err := ProjectFactory(context.Background(), new(configuration.Configuration), logger, Options{}); err == nil {
+		t.Fatal("ProjectFactory() error = nil, want the module error")
+	}
+	for _, options := range []RecoverOptions{{Only: "egg::missing"}, {From: "egg::missing"}} {
+		err := RecoverFromScrambled(context.Background(), logger, options)
+		if err == nil || !strings.Contains(err.Error(), "egg::missing") {
+			t.Errorf("RecoverFromScrambled(%+v) error = %v, want unknown module", options, err)
+		}
+	}
+}
+
+func TestScrambled_RoundTripsEveryModule(t *testing.T) {
+	t.Chdir(t.TempDir())
+	pipeline, err := Registry.Modules()
+	if err != nil {
+		t.Fatalf("Registry.Modules() error = %v", err)
+	}
+	for i, failed := range pipeline {
+		t.Run(failed.Name(), func(t *testing.T) {
+			checkpoints := map[string][]string{failed.Name(): {"step"}}
+			if err := WriteScrambled("", new(configuration.Configuration), pipeline[:i], failed, errors.New("simulated error"), checkpoints); err != nil {
+				t.Fatalf("WriteScrambled() error = %v", err)
+			}
+			scrambled, err := LoadScrambled()
+			if err != nil {
+				t.Fatalf("LoadScrambled() error = %v", err)
+			}
+			if got, want := moduleNames(scrambled.Succeeded), moduleNames(pipeline[:i]); !reflect.DeepEqual(got, want) {
+				t.Errorf("Succeeded = %v, want %v", got, want)
+			}
+			if got, want := moduleNames(scrambled.Pending), moduleNames(pipeline[i:]); !reflect.DeepEqual(got, want) {
+				t.Errorf("Pending = %v, want %v", got, want)
+			}
+			if !reflect.DeepEqual(scrambled.Checkpoints, checkpoints) {
+				t.Errorf("Checkpoints = %v, want %v", scrambled.Checkpoints, checkpoints)
+			}
+		})
+	}
+}
+
+func TestLoadScrambled_LegacyNames(t *testing.T) {
+	t.Chdir(t.TempDir())
+	legacy := `failed:
+  moduleName: egg::bootstrap-framework-files-from-templates
+  error: simulated error
+succeeded:
+  - egg::initialize
+  - egg::install-tools
+  - egg::install-libraries
+  - egg::bootstrap-directories
+  - egg::generate-configuration
+checkpoints:
+  egg::bootstrap_framwork:
+    - main.go
+`
+	if err := os.WriteFile(ScrambledFileName, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	scrambled, err := LoadScrambled()
+	if err != nil {
+		t.Fatalf("LoadScrambled() error = %v", err)
+	}
+	want := []string{modules.BootstrapFrameworkModuleID, modules.RsbuildFrontendModuleID}
+	if got := moduleNames(scrambled.Pending); !reflect.DeepEqual(got, want) {
+		t.Errorf("Pending = %v, want %v", got, want)
+	}
+	if got := scrambled.Checkpoints[modules.BootstrapFrameworkModuleID]; !reflect.DeepEqual(got, []string{"main.go"}) {
+		t.Errorf("Checkpoints = %v, want the checkpoints of the legacy name", scrambled.Checkpoints)
+	}
+}
+
+func moduleNames(list []modules.IModule) []string {
+	names := make([]string, len(list))
+	for i, module := range list {
+		names[i] = module.Name()
+	}
+	return names
+}
+
+func TestProjectFactory_ForceRollsBackToPreviousProject(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"rolled back", errors.New("simulated error")},
+		{"succeeded", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := setupFakeModules(t,
+				&modules.InitializeModule{},
+				&fakeModule{name: "egg::later", err: tt.err},
+			)
+			dir := filepath.Join(t.TempDir(), "app")
+			if err := os.MkdirAll(filepath.Join(dir, "cmd"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "cmd", "main.go"), []byte("package main\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			config := new(configuration.Configuration)
+			config.Namespace = "github.com/testuser/app"
+			config.Name = "app"
+
+			err := ProjectFactory(context.Background(), config, logger, Options{
+				Transactional: true,
+				Workspace:     modules.Workspace{Dir: dir, Overwrite: modules.OverwriteForce},
+			})
+			if _, statErr := os.Stat(dir + modules.PreviousSuffix); !os.IsNotExist(statErr) {
+				t.Errorf("ProjectFactory() left %s behind", dir+modules.PreviousSuffix)
+			}
+			content, readErr := os.ReadFile(filepath.Join(dir, "cmd", "main.go"))
+			if tt.err == nil {
+				if err != nil {
+					t.Fatalf("ProjectFactory() error = %v", err)
+				}
+				if readErr == nil {
+					t.Error("ProjectFactory() kept the files of the replaced project")
+				}
+				return
+			}
+			if !errors.Is(err, ErrRolledBack) {
+				t.Fatalf("ProjectFactory() error = %v, want ErrRolledBack", err)
+			}
+			if readErr != nil || string(content) != "package main\n" {
+				t.Errorf("cmd/main.go = %q, %v after the rollback, want the previous project back", content, readErr)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "go.mod")); !os.IsNotExist(err) {
+				t.Error("the rollback kept the go.mod of the failed run")
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package modules
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// BaselineDir keeps a copy of every rendered template inside of the project, it is the
+// common ancestor of the three-way merge of `egg_cli upgrade` and has to be committed
+const BaselineDir = ".egg/baseline"
+
+// BaselinePath returns the path of the baseline of the template name, relative to the project
+func BaselinePath(name string) string {
+	return filepath.Join(BaselineDir, filepath.FromSlash(path.Clean(name)))
+}
+
+// ReadBaseline returns the content the template name was last rendered with in the project in dir
+func ReadBaseline(dir, name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(dir, BaselinePath(name)))
+}
+
+// WriteBaseline stores the rendered content of the template name in the project in dir
+func WriteBaseline(dir, name string, rendered []byte) error {
+	baseline := filepath.Join(dir, BaselinePath(name))
+	if err := os.MkdirAll(filepath.Dir(baseline), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(baseline, rendered, 0666)
+}
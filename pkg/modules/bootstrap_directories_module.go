@@ -1,9 +1,10 @@
 package modules
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"os/exec"
+	"os"
 	"sync"
 
 	"github.com/adamkali/egg_cli/pkg/configuration"
@@ -12,11 +13,14 @@ import (
 )
 
 type BootstrapDirectoriesModule struct {
+	checkpoints
+	projectWorkspace
 	Directories []string
 	Error       error
 	Progress    int
 	eggl        *models.EggLog
 	MkdirFunc   func(dir string) error // For testing - can be injected to mock directory creation
+	mu          sync.Mutex
 }
 
 // Name
@@ -29,7 +33,7 @@ type BootstrapDirectoriesModule struct {
 //
 //	This function returns the name of the module
 func (m *BootstrapDirectoriesModule) Name() string {
-	return "egg::bootstrap_directories"
+	return BootstrapDirectoriesModuleID
 }
 
 // IncrProg
@@ -38,6 +42,8 @@ func (m *BootstrapDirectoriesModule) Name() string {
 //
 //	increments the progress
 func (m *BootstrapDirectoriesModule) IncrProg() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.Progress += 1
 	return
 }
@@ -48,6 +54,8 @@ func (m *BootstrapDirectoriesModule) IncrProg() {
 //
 //	float64: the progress
 func (m *BootstrapDirectoriesModule) GetProgress() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	return float64(m.Progress) / float64(len(m.Directories))
 }
 
@@ -55,56 +63,75 @@ func (m *BootstrapDirectoriesModule) GetProgress() float64 {
 //
 // description:
 //
-//		This function is used to bootstrap the directories for the project.
-//	  this is done by iterating over the directories and creating a goroutine for each directory
-//	  and then waiting for all the goroutines to finish
-//	  and collecting the errors
-//	  and logging the errors
-//	  and canceling the context if there is an error
-func (m *BootstrapDirectoriesModule) Run() {
+//	This function is used to bootstrap the directories for the project.
+//	the directories are created by a bounded pool of workers and Run blocks
+//	until every directory has been attempted. Every failing directory is
+//	reported in the returned error, not only the first one.
+func (m *BootstrapDirectoriesModule) Run(ctx context.Context) (Result, error) {
+	result := newResult(m)
+	if err := ctx.Err(); err != nil {
+		m.Error = err
+		return result, m.Error
+	}
 	bootstrapDirectoriesStart := styles.EggProgressInfo.Render("🥚 " + m.Name() + " start")
 	fmt.Println(bootstrapDirectoriesStart)
-	errChan := make(chan error)
-	logChan := make(chan string)
-	wg := new(sync.WaitGroup)
-
-	for _, dir := range m.Directories {
-		wg.Add(1)
-		go func(dir string) {
-			defer wg.Done()
-			var err error
-			if m.MkdirFunc != nil {
-				err = m.MkdirFunc(dir)
-			} else {
-				err = m.mkdir(dir)
-			}
-			logChan <- fmt.Sprintf("🥚 %s creating %s", m.Name(), dir)
-			if err != nil {
-				errChan <- err
-			}
-		}(dir)
-	}
 
-	go func() {
-		for log := range logChan {
-			m.eggl.Info(log)
-			log = styles.EggProgressInfo.Render(log)
-			fmt.Println(log)
+	m.Error = forEachBounded(ctx, m.Directories, func(dir string) error {
+		if m.isCompleted(dir) {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			m.Progress += 1
+			return nil
 		}
-	}()
-	go func() {
-		for err := range errChan {
-			m.Error = err
-			return
+		undo := snapshotDirectory(m.projectPath(dir))
+		defer func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			result.addUndo(undo)
+		}()
+
+		var err error
+		if m.MkdirFunc != nil {
+			err = m.MkdirFunc(m.projectPath(dir))
+		} else {
+			err = m.mkdir(m.projectPath(dir))
 		}
-	}()
-	go func() {
-		wg.Wait()
-		bootstrapDirectoriesComplete := styles.EggProgressInfo.Render("🥚 " + m.Name() + " complete")
-		fmt.Println(bootstrapDirectoriesComplete)
-		m.eggl.Info("🥚 " + m.Name() + " complete")
-	}()
-	return
+		if err != nil {
+			m.eggl.Error("error: %s", err.Error())
+			return err
+		}
+
+		log := fmt.Sprintf("🥚 %s creating %s", m.Name(), dir)
+		m.eggl.Info(log)
+		fmt.Println(styles.EggProgressInfo.Render(log))
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		result.Directories = append(result.Directories, dir)
+		result.checkpoint(dir)
+		m.Progress += 1
+		return nil
+	})
+	if m.Error != nil {
+		return result, m.Error
+	}
+
+	bootstrapDirectoriesComplete := styles.EggProgressInfo.Render("🥚 " + m.Name() + " complete")
+	fmt.Println(bootstrapDirectoriesComplete)
+	m.eggl.Info("🥚 " + m.Name() + " complete")
+	return result, nil
+}
+
+// Describe
+//
+// returns:
+//
+//	Plan: the directories that would be created
+func (m *BootstrapDirectoriesModule) Describe() Plan {
+	return Plan{
+		Module:      m.Name(),
+		Directories: m.Directories,
+	}
 }
 
 // mkdir
@@ -119,15 +146,11 @@ func (m *BootstrapDirectoriesModule) Run() {
 //
 // description:
 //
-//	This function is used to create a directory using the builtin mkdir command
-//	and passing in the -p flag to create the entire parent directory if it does not exist
+//	This function is used to create a directory and every missing parent,
+//	a directory that already exists is not an error
 func (m *BootstrapDirectoriesModule) mkdir(dir string) error {
-	_, err := exec.Command("mkdir", "-p", dir).Output()
-	// if err is not File Exists, return the error
-	if err != nil {
-		if err.Error() != "exit status 1" {
-			return errors.New("error creating directory: " + dir + " " + err.Error())
-		}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return errors.New("error creating directory: " + dir + " " + err.Error())
 	}
 	return nil
 }
@@ -158,7 +181,7 @@ func (m *BootstrapDirectoriesModule) LoadFromConfig(configuration *configuration
 		"cmd/database_cli",
 		"controllers",
 		"docs",
-		"middlwares/configs",
+		"middlewares/configs",
 		"models",
 		"public",
 		"services",
@@ -1,9 +1,18 @@
 package modules
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
-	"time"
+
+	"github.com/adamkali/egg_cli/pkg/templates"
 )
 
 func TestBootstrapDirectoriesModule_Name(t *testing.T) {
@@ -41,6 +50,31 @@ func TestBootstrapDirectoriesModule_LoadFromConfig(t *testing.T) {
 	}
 }
 
+// TestBootstrapDirectoriesModule_TemplateDirectories fails when a template writes into a
+// directory that is not created, e.g. because of a typo in Directories
+func TestBootstrapDirectoriesModule_TemplateDirectories(t *testing.T) {
+	cfg := createTestConfiguration()
+	m := &BootstrapDirectoriesModule{}
+	m.LoadFromConfig(cfg, nil)
+	files, err := templates.Files(cfg)
+	if err != nil {
+		t.Fatalf("Files() error = %v", err)
+	}
+	for _, file := range files {
+		dir := path.Dir(file.Output)
+		if dir == "." {
+			continue
+		}
+		// MkdirAll creates the parents of a directory as well
+		created := slices.ContainsFunc(m.Directories, func(created string) bool {
+			return dir == created || strings.HasPrefix(dir, created+"/") || strings.HasPrefix(created, dir+"/")
+		})
+		if !created {
+			t.Errorf("%s is written into %s, which is not in Directories %v", file.Output, dir, m.Directories)
+		}
+	}
+}
+
 func TestBootstrapDirectoriesModule_IsError(t *testing.T) {
 	m := &BootstrapDirectoriesModule{Error: errors.New("fail")}
 	if m.IsError() == nil {
@@ -57,14 +91,16 @@ func TestBootstrapDirectoriesModule_Run_Success(t *testing.T) {
 	defer logger.Close()
 	m := &BootstrapDirectoriesModule{eggl: logger, Directories: []string{"dir1", "dir2"}}
 
+	var mu sync.Mutex
 	created := make(map[string]bool)
 	m.MkdirFunc = func(dir string) error {
+		mu.Lock()
+		defer mu.Unlock()
 		created[dir] = true
 		return nil
 	}
 
-	m.Run()
-	time.Sleep(50 * time.Millisecond)
+	m.Run(context.Background())
 
 	if m.IsError() != nil {
 		t.Errorf("Run() set unexpected error: %v", m.IsError())
@@ -89,10 +125,129 @@ func TestBootstrapDirectoriesModule_Run_Error(t *testing.T) {
 		return nil
 	}
 
-	m.Run()
-	time.Sleep(50 * time.Millisecond)
+	m.Run(context.Background())
 
 	if m.IsError() == nil {
 		t.Error("Run() did not set error on directory creation failure")
 	}
 }
+
+func TestBootstrapDirectoriesModule_Describe(t *testing.T) {
+	logger := createTestLogger(t)
+	defer logger.Close()
+	m := &BootstrapDirectoriesModule{}
+	m.LoadFromConfig(createTestConfiguration(), logger)
+	m.MkdirFunc = func(dir string) error {
+		t.Errorf("Describe() must not create %s", dir)
+		return nil
+	}
+
+	plan := m.Describe()
+	if len(plan.Directories) != len(m.Directories) {
+		t.Errorf("Describe() Directories = %v, want %v", plan.Directories, m.Directories)
+	}
+}
+
+func TestBootstrapDirectoriesModule_Run_BlocksUntilDone(t *testing.T) {
+	logger := createTestLogger(t)
+	defer logger.Close()
+	root := t.TempDir()
+
+	var directories []string
+	for i := 0; i < 50; i++ {
+		directories = append(directories, filepath.Join(root, fmt.Sprintf("dir%d", i), "nested"))
+	}
+	m := &BootstrapDirectoriesModule{eggl: logger, Directories: directories}
+
+	result, err := m.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	// every directory must exist as soon as Run returns
+	for _, dir := range directories {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			t.Errorf("directory %q does not exist after Run() returned", dir)
+		}
+	}
+	if len(result.Directories) != len(directories) {
+		t.Errorf("Run() result has %d directories, want %d", len(result.Directories), len(directories))
+	}
+	if m.GetProgress() != 1.0 {
+		t.Errorf("GetProgress() = %v after Run(), want 1.0", m.GetProgress())
+	}
+}
+
+func TestBootstrapDirectoriesModule_Run_AggregatesErrors(t *testing.T) {
+	logger := createTestLogger(t)
+	defer logger.Close()
+	m := &BootstrapDirectoriesModule{eggl: logger, Directories: []string{"ok1", "bad1", "ok2", "bad2", "bad3"}}
+	m.MkdirFunc = func(dir string) error {
+		if strings.HasPrefix(dir, "bad") {
+			return errors.New("simulated error for " + dir)
+		}
+		return nil
+	}
+
+	result, err := m.Run(context.Background())
+	if err == nil {
+		t.Fatal("Run() did not return an error")
+	}
+	for _, dir := range []string{"bad1", "bad2", "bad3"} {
+		if !strings.Contains(err.Error(), dir) {
+			t.Errorf("Run() error does not report %s: %v", dir, err)
+		}
+	}
+	if len(result.Directories) != 2 {
+		t.Errorf("Run() result has %d directories, want the 2 that succeeded", len(result.Directories))
+	}
+}
+
+func TestBootstrapDirectoriesModule_Run_Cancelled(t *testing.T) {
+	logger := createTestLogger(t)
+	defer logger.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	m := &BootstrapDirectoriesModule{eggl: logger, Directories: []string{"dir1"}}
+	m.MkdirFunc = func(dir string) error {
+		t.Errorf("Run() created %s after the context was cancelled", dir)
+		return nil
+	}
+	if _, err := m.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestBootstrapDirectoriesModule_Run_Undo(t *testing.T) {
+	logger := createTestLogger(t)
+	defer logger.Close()
+	root := t.TempDir()
+	existing := filepath.Join(root, "existing")
+	if err := os.Mkdir(existing, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	m := &BootstrapDirectoriesModule{eggl: logger, Directories: []string{
+		filepath.Join(root, "db", "migrations"),
+		filepath.Join(root, "db", "queries"),
+		filepath.Join(existing, "controllers"),
+	}}
+
+	result, err := m.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	transaction := new(Transaction)
+	transaction.Add(result)
+	if err := transaction.Rollback(); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+
+	for _, dir := range []string{filepath.Join(root, "db"), filepath.Join(existing, "controllers")} {
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			t.Errorf("%s was not rolled back", dir)
+		}
+	}
+	if _, err := os.Stat(existing); err != nil {
+		t.Error("Rollback() removed a directory that existed before Run()")
+	}
+}
@@ -6,10 +6,17 @@
 package modules
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"text/template"
 
@@ -27,18 +34,42 @@ import (
 // description:
 //
 //	This struct is used to bootstrap the framework files from the templates found in the templates directory
-//	this is done by iterating over the mapping and creating a goroutine for each template
-//	and then waiting for all the goroutines to finish
-//	and collecting the errors
-//	and logging the errors
-//	canceling the context if there is an error
+//	this is done by rendering every template of the mapping with a bounded pool of workers
+//	and then waiting for all of them to finish
+//	and collecting every error
 type BootstrapFrameworkFilesFromTemplatesModule struct {
+	checkpoints
+	projectWorkspace
 	mapping               map[string]*template.Template
+	modes                 map[string]fs.FileMode // modes are the permissions of the manifest, overrides get templates.DefaultPermissions
 	configuration         *configuration.Configuration
 	error                 error
 	progress              int
 	eggl                  *models.EggLog
 	PopulateTemplatesFunc func(name string, template *template.Template) error // For testing - can be injected to mock template population
+	InputFunc             func(prompt string) string                           // For testing - can be injected to mock user input
+	mu                    sync.Mutex
+	// generated are the manifest entries of the templates rendered by this run
+	generated []ManifestEntry
+	// askMu makes the workers ask about their conflicts one after another
+	askMu sync.Mutex
+}
+
+// templateOutcome is what populateTemplate did with a rendered file
+type templateOutcome int
+
+const (
+	templateCreated templateOutcome = iota
+	// templateUnchanged is a file on disk that is the same as the rendered one
+	templateUnchanged
+	templateKept
+	templateOverwritten
+	templateNewFile
+)
+
+// Interactive reports whether Run asks what to do with the files that differ from the templates
+func (m *BootstrapFrameworkFilesFromTemplatesModule) Interactive() bool {
+	return m.workspace.Overwrite == OverwriteMerge && m.workspace.Conflicts == ResolveAsk && m.InputFunc == nil
 }
 
 // Name
@@ -51,7 +82,7 @@ type BootstrapFrameworkFilesFromTemplatesModule struct {
 //
 //	This function returns the name of the module
 func (m *BootstrapFrameworkFilesFromTemplatesModule) Name() string {
-	return "egg::bootstrap_framwork"
+	return BootstrapFrameworkModuleID
 }
 
 // IncrProg
@@ -60,6 +91,8 @@ func (m *BootstrapFrameworkFilesFromTemplatesModule) Name() string {
 //
 //	increments the progress
 func (m *BootstrapFrameworkFilesFromTemplatesModule) IncrProg() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.progress += 1
 	return
 }
@@ -70,6 +103,8 @@ func (m *BootstrapFrameworkFilesFromTemplatesModule) IncrProg() {
 //
 //	float64: the progress
 func (m *BootstrapFrameworkFilesFromTemplatesModule) GetProgress() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	return float64(m.progress) / float64(len(m.mapping))
 }
 
@@ -78,64 +113,145 @@ func (m *BootstrapFrameworkFilesFromTemplatesModule) GetProgress() float64 {
 // description:
 //
 //	This function is used to bootstrap the framework files from the templates found in the templates directory
-//	this is done by iterating over the mapping and creating a goroutine for each template
-//	and then waiting for all the goroutines to finish
-//	and collecting the errors
-//	and logging the errors
-func (m *BootstrapFrameworkFilesFromTemplatesModule) Run() {
+//	the templates are rendered by a bounded pool of workers and Run blocks until every file
+//	has been attempted. Every template that failed is reported in the returned error, not
+//	only the first one. When merging into an existing project, files that are the same as
+//	the rendered ones are skipped and the others are resolved by the workspace.
+func (m *BootstrapFrameworkFilesFromTemplatesModule) Run(ctx context.Context) (Result, error) {
+	result := newResult(m)
+	if err := ctx.Err(); err != nil {
+		m.error = err
+		return result, m.error
+	}
+	m.mu.Lock()
 	m.progress = 0
-	// iterate over the mapping and create a goroutine for each template
-	errChan := make(chan error)
-	logChan := make(chan string)
-	wg := new(sync.WaitGroup)
-	for name, templ := range m.mapping {
-		wg.Add(1)
-		go func(name string, t *template.Template) {
-			defer wg.Done()
-			// Use injected function if available (for testing), otherwise use real implementation
-			var err error
-			if m.PopulateTemplatesFunc != nil {
-				err = m.PopulateTemplatesFunc(name, t)
-			} else {
-				err = m.populateTemplate(name, t)
-			}
-			logChan <- fmt.Sprintf("🥚 %s creating %s", m.Name(), name)
-			if err != nil {
-				errChan <- err
-			}
-		}(name, templ)
+	m.generated = nil
+	m.mu.Unlock()
+
+	// sort the names so that the errors are always reported in the same order
+	names := make([]string, 0, len(m.mapping))
+	for name := range m.mapping {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+
+	m.error = forEachBounded(ctx, names, func(name string) error {
+		if m.isCompleted(path.Clean(name)) {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			m.progress += 1
+			return nil
+		}
+		// a template that failed half way may still have left a file behind,
+		// so the undo is registered whether or not it succeeds
+		undo := []UndoAction{snapshotDirectory(filepath.Dir(m.projectPath(name))), snapshotFile(m.projectPath(name))}
+		if m.workspace.Overwrite == OverwriteMerge {
+			undo = append(undo, snapshotFile(m.projectPath(name)+NewFileSuffix))
+		}
+		baseline := m.projectPath(BaselinePath(name))
+		undo = append(undo, snapshotDirectory(filepath.Dir(baseline)), snapshotFile(baseline))
+		defer func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			result.addUndo(undo...)
+		}()
+
+		// Use injected function if available (for testing), otherwise use real implementation
+		outcome := templateCreated
+		var err error
+		if m.PopulateTemplatesFunc != nil {
+			err = m.PopulateTemplatesFunc(name, m.mapping[name])
+		} else {
+			outcome, err = m.populateTemplate(name, m.mapping[name])
+		}
+		if err != nil {
+			m.eggl.Error("error: %s", err.Error())
+			return err
+		}
 
-	// handle logging
-	go func() {
-		for log := range logChan {
-			m.eggl.Info(log)
-			log = styles.EggProgressInfo.Render(log)
-			fmt.Println(log)
+		var log string
+		written := path.Clean(name)
+		switch outcome {
+		case templateUnchanged:
+			log, written = fmt.Sprintf("🥚 %s %s is unchanged", m.Name(), name), ""
+		case templateKept:
+			log, written = fmt.Sprintf("🥚 %s keeping %s", m.Name(), name), ""
+		case templateOverwritten:
+			log = fmt.Sprintf("🥚 %s overwriting %s", m.Name(), name)
+		case templateNewFile:
+			written += NewFileSuffix
+			log = fmt.Sprintf("🥚 %s writing %s", m.Name(), written)
+		default:
+			log = fmt.Sprintf("🥚 %s creating %s", m.Name(), name)
 		}
-	}()
-
-	handleErrors := func() {
-		for err := range errChan {
-			m.error = err
-			// return if there is an error
-			wg.Wait()
-			close(errChan)
-			close(logChan)
-			return
+		m.eggl.Info(log)
+		fmt.Println(styles.EggProgressInfo.Render(log))
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if written != "" {
+			result.Files = append(result.Files, written)
+		}
+		result.checkpoint(path.Clean(name))
+		m.progress += 1
+		return nil
+	})
+	if len(m.generated) > 0 {
+		if err := m.writeManifest(&result); err != nil {
+			m.eggl.Error("error: %s", err.Error())
+			m.error = errors.Join(m.error, err)
 		}
 	}
+	sort.Strings(result.Files)
+	return result, m.error
+}
 
-	// handle errors
-	go func() {
-		handleErrors()
-	}()
+// writeManifest records the rendered templates in the manifest of the project, keeping
+// the entries of the templates a previous run already rendered
+func (m *BootstrapFrameworkFilesFromTemplatesModule) writeManifest(result *Result) error {
+	manifestFile := m.projectPath(ManifestFile)
+	result.addUndo(snapshotDirectory(filepath.Dir(manifestFile)), snapshotFile(manifestFile))
+	manifest, err := LoadManifest(m.workspace.Dir)
+	if err != nil {
+		return errors.New(m.Name() + " error reading the manifest: " + err.Error())
+	}
+	for _, entry := range m.generated {
+		manifest.Set(entry)
+	}
+	if err := manifest.Save(m.workspace.Dir); err != nil {
+		return errors.New(m.Name() + " error writing the manifest: " + err.Error())
+	}
+	return nil
+}
 
-	go func() {
-		wg.Wait()
-		close(errChan)
-		close(logChan)
-	}()
+// Describe
+//
+// returns:
+//
+//	Plan: every file that would be rendered from templates.Mapping, sorted by path
+func (m *BootstrapFrameworkFilesFromTemplatesModule) Describe() Plan {
+	plan := Plan{Module: m.Name()}
+	for name := range m.mapping {
+		plan.Files = append(plan.Files, path.Clean(name))
+	}
+	sort.Strings(plan.Files)
+	plan.Notes = append(plan.Notes, "a copy of every rendered file is kept in "+BaselineDir+" for egg_cli upgrade")
+	plan.Notes = append(plan.Notes, "every rendered file is recorded with its checksum in "+ManifestFile)
+	if m.workspace.Overwrite == OverwriteMerge {
+		note := "files that are the same as on disk are skipped, "
+		switch m.workspace.Conflicts {
+		case ResolveKeep:
+			note += "files that differ are kept"
+		case ResolveOverwrite:
+			note += "files that differ are overwritten"
+		case ResolveNew:
+			note += "files that differ are written next to the existing ones as <file>" + NewFileSuffix
+		default:
+			note += "asks what to do with every file that differs"
+		}
+		plan.Notes = append(plan.Notes, note)
+	}
+	return plan
 }
 
 // IsError
@@ -156,51 +272,139 @@ func (m *BootstrapFrameworkFilesFromTemplatesModule) IsError() error {
 //
 // returns:
 //
+//	  templateOutcome: whether the file was created, skipped, kept, overwritten or written next to the existing one
 //	  error:
 //	    - if there is an error creating the file
 //		   - if there is an error executing the template
 //	    - if the generater failed to use a correct directory
+//	    - if there was no answer to what to do with a file that differs
 //
 // description:
 //
 //	This function is used to populate the template with the values from the configuration file
 //	and output the file to the correct location. Because template.Mapping is created with keys
 //	that are the same as the file names, this function is used as a single instance the mapping
-//	so that the loop can be split into a goroutine and be ran concurrently. We also return an error
-//	so that we can collect all the errors into a channel and stop the downstream goroutines
-func (m *BootstrapFrameworkFilesFromTemplatesModule) populateTemplate(name string, template *template.Template) error {
-	// create an io writer to write the file to
-	var err error
-	var f *os.File
-	f, err = os.Create(name)
-	if err != nil {
-		// if m.error is PathError then we need to make sure that the directory exists
-		// we seperate the name as the relative directory in which the file is located
-		// Example:
-		//   name: "./cmd/configuration/configuration.go"
-		//   relativeDir: "<configuration.Name>/cmd/configuration"
-		//   fileName: "configuration.go"
-		//   dir: "./cmd/configuration"
-		relativeDir := path.Dir(name)
-		// if relativeDir is not empty then we need to create the directory
-		if relativeDir != "" {
-			err := os.MkdirAll(relativeDir, os.ModePerm)
+//	so that the loop can be split into workers and be ran concurrently. We also return an error
+//	so that every failed template can be collected and reported together. When merging, the
+//	rendered file is compared with the one on disk before anything is written. The rendered
+//	file is always stored as the baseline that `egg_cli upgrade` merges against, and
+//	recorded with its checksum for the manifest.
+func (m *BootstrapFrameworkFilesFromTemplatesModule) populateTemplate(name string, template *template.Template) (templateOutcome, error) {
+	// make sure that the directory exists before creating the file
+	// we seperate the name as the relative directory in which the file is located
+	// Example:
+	//   name: "./cmd/configuration/configuration.go"
+	//   relativeDir: "./cmd/configuration"
+	relativeDir := path.Dir(name)
+	if err := os.MkdirAll(m.projectPath(relativeDir), os.ModePerm); err != nil {
+		return templateCreated, errors.New(m.Name() + " error creating directory: " + relativeDir + " " + err.Error())
+	}
+
+	// execute the template
+	var rendered bytes.Buffer
+	if err := template.Execute(&rendered, m.configuration); err != nil {
+		return templateCreated, errors.New(m.Name() + " error executing template: " + name + " " + err.Error())
+	}
+	// the baseline is what the template rendered, whatever is kept on disk
+	if err := WriteBaseline(m.workspace.Dir, name, rendered.Bytes()); err != nil {
+		return templateCreated, errors.New(m.Name() + " error writing the baseline of: " + name + " " + err.Error())
+	}
+	m.mu.Lock()
+	m.generated = append(m.generated, ManifestEntry{
+		Path:       path.Clean(name),
+		Template:   template.Name(),
+		EggVersion: templates.Version,
+		SHA256:     Checksum(rendered.Bytes()),
+	})
+	m.mu.Unlock()
+
+	target := m.projectPath(name)
+	outcome := templateCreated
+	if m.workspace.Overwrite == OverwriteMerge {
+		existing, err := os.ReadFile(target)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return outcome, errors.New(m.Name() + " error reading file: " + name + " " + err.Error())
+		case bytes.Equal(existing, rendered.Bytes()):
+			return templateUnchanged, nil
+		default:
+			resolution, err := m.resolveConflict(name, existing, rendered.Bytes())
 			if err != nil {
-				err = errors.New(m.Name() + "error creating directory: " + relativeDir + " " + err.Error())
-				return err
+				return outcome, err
+			}
+			switch resolution {
+			case ResolveKeep:
+				return templateKept, nil
+			case ResolveNew:
+				target += NewFileSuffix
+				outcome = templateNewFile
+			default:
+				outcome = templateOverwritten
 			}
-			f, err = os.Create(name)
 		}
 	}
-	defer f.Close()
 
-	// execute the template
-	err = template.Execute(f, m.configuration)
-	if err != nil {
-		err = errors.New(m.Name() + "error executing template: " + name + " " + err.Error())
-		return err
+	mode, ok := m.modes[path.Clean(name)]
+	if !ok {
+		mode = templates.DefaultPermissions
+	}
+	if err := os.WriteFile(target, rendered.Bytes(), mode); err != nil {
+		return outcome, errors.New(m.Name() + " error creating file: " + name + " " + err.Error())
+	}
+	// os.WriteFile only applies the mode to new files
+	if err := os.Chmod(target, mode); err != nil {
+		return outcome, errors.New(m.Name() + " error creating file: " + name + " " + err.Error())
+	}
+	return outcome, nil
+}
+
+// resolveConflict
+//
+// params:
+//
+//	name: string
+//	existing: []byte
+//	rendered: []byte
+//
+// returns:
+//
+//	Resolution: what to do with the rendered file, never ResolveAsk
+//	error: if stdin was closed before there was an answer
+//
+// description:
+//
+//	Returns the resolution of the workspace, or asks for this file when it is
+//	ResolveAsk. Asking for the diff prints it and asks again.
+func (m *BootstrapFrameworkFilesFromTemplatesModule) resolveConflict(name string, existing, rendered []byte) (Resolution, error) {
+	if m.workspace.Conflicts != ResolveAsk {
+		return m.workspace.Conflicts, nil
+	}
+	// the workers render at the same time, but only one of them may ask at a time
+	m.askMu.Lock()
+	defer m.askMu.Unlock()
+	prompt := fmt.Sprintf("%s differs from the template: (k)eep it, (o)verwrite it, write %s (n)ext to it, or show the (d)iff?", name, path.Base(name)+NewFileSuffix)
+	for {
+		var answer string
+		if m.InputFunc != nil {
+			answer = m.InputFunc(prompt)
+		} else {
+			fmt.Println(prompt)
+			if _, err := fmt.Scanln(&answer); errors.Is(err, io.EOF) {
+				return ResolveAsk, errors.New(m.Name() + " no answer for " + name + ", pass --on-conflict to merge without asking")
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "k", "keep":
+			return ResolveKeep, nil
+		case "o", "overwrite":
+			return ResolveOverwrite, nil
+		case "n", "new":
+			return ResolveNew, nil
+		case "d", "diff":
+			fmt.Print(unifiedDiff(name, string(existing), string(rendered)))
+		}
 	}
-	return nil
 }
 
 // LoadFromConfig
@@ -217,6 +421,12 @@ func (m *BootstrapFrameworkFilesFromTemplatesModule) populateTemplate(name strin
 func (m *BootstrapFrameworkFilesFromTemplatesModule) LoadFromConfig(configuration *configuration.Configuration, eggl *models.EggLog) {
 	m.configuration = configuration
 	m.mapping = templates.Mapping(configuration)
+	m.modes = templates.Permissions(configuration)
 	m.eggl = eggl
 	return
 }
+
+// SetTemplates replaces the built-in templates.Mapping, e.g. with the overrides of a template directory
+func (m *BootstrapFrameworkFilesFromTemplatesModule) SetTemplates(mapping map[string]*template.Template) {
+	m.mapping = mapping
+}
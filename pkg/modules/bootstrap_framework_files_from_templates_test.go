@@ -1,16 +1,22 @@
 package modules
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 	"text/template"
-	"time"
+
+	"github.com/adamkali/egg_cli/pkg/templates"
 )
 
 func TestBootstrapFrameworkFilesFromTemplatesModule_Name(t *testing.T) {
 	m := &BootstrapFrameworkFilesFromTemplatesModule{}
-	if got, want := m.Name(), "egg::bootstrap_framwork"; got != want {
+	if got, want := m.Name(), "egg::bootstrap_framework"; got != want {
 		t.Errorf("Name() = %q, want %q", got, want)
 	}
 }
@@ -81,10 +87,7 @@ func TestBootstrapFrameworkFilesFromTemplatesModule_Run(t *testing.T) {
 		},
 	}
 
-	m.Run()
-
-	// Wait a bit for goroutines to complete (since Run() is asynchronous)
-	time.Sleep(100 * time.Millisecond)
+	m.Run(context.Background())
 
 	// Check that the content was generated correctly
 	expectedContent := "Hello, testproject!"
@@ -98,3 +101,209 @@ func TestBootstrapFrameworkFilesFromTemplatesModule_Run(t *testing.T) {
 		t.Errorf("Run() set unexpected error: %v", m.IsError())
 	}
 }
+
+func TestBootstrapFrameworkFilesFromTemplatesModule_Describe(t *testing.T) {
+	logger := createTestLogger(t)
+	defer logger.Close()
+	m := &BootstrapFrameworkFilesFromTemplatesModule{}
+	m.LoadFromConfig(createTestConfiguration(), logger)
+	m.PopulateTemplatesFunc = func(name string, template *template.Template) error {
+		t.Errorf("Describe() must not populate %s", name)
+		return nil
+	}
+
+	plan := m.Describe()
+	if len(plan.Files) != len(m.mapping) {
+		t.Errorf("Describe() returned %d files, want %d", len(plan.Files), len(m.mapping))
+	}
+	for i, file := range plan.Files {
+		if strings.HasPrefix(file, "./") {
+			t.Errorf("Describe() file %q should be cleaned", file)
+		}
+		if i > 0 && plan.Files[i-1] > file {
+			t.Errorf("Describe() files are not sorted: %q before %q", plan.Files[i-1], file)
+		}
+	}
+}
+
+func TestBootstrapFrameworkFilesFromTemplatesModule_Run_WritesEveryFile(t *testing.T) {
+	logger := createTestLogger(t)
+	defer logger.Close()
+	cfg := createTestConfiguration()
+	root := t.TempDir()
+
+	mapping := make(map[string]*template.Template)
+	for i := 0; i < 50; i++ {
+		name := filepath.Join(fmt.Sprintf("pkg%d", i%5), fmt.Sprintf("file%d.go", i))
+		mapping[name] = template.Must(template.New(name).Parse("package {{.Name}}\n"))
+	}
+	m := &BootstrapFrameworkFilesFromTemplatesModule{mapping: mapping, configuration: cfg, eggl: logger}
+	m.SetWorkspace(Workspace{Dir: root})
+
+	result, err := m.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	// every file must be written as soon as Run returns
+	for name := range mapping {
+		content, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			t.Errorf("file %q does not exist after Run() returned", name)
+			continue
+		}
+		if string(content) != "package testproject\n" {
+			t.Errorf("file %q = %q, want %q", name, content, "package testproject\n")
+		}
+		if baseline, err := ReadBaseline(root, name); err != nil || string(baseline) != string(content) {
+			t.Errorf("baseline of %q = %q, %v, want the rendered file", name, baseline, err)
+		}
+	}
+	if len(result.Files) != len(mapping) {
+		t.Errorf("Run() result has %d files, want %d", len(result.Files), len(mapping))
+	}
+	manifest, err := LoadManifest(root)
+	if err != nil {
+		t.Fatalf("LoadManifest() error = %v", err)
+	}
+	if len(manifest.Files) != len(mapping) {
+		t.Errorf("manifest has %d files, want %d", len(manifest.Files), len(mapping))
+	}
+	for _, entry := range manifest.Files {
+		if modified, err := entry.Modified(root); err != nil || modified {
+			t.Errorf("manifest entry %+v does not match the rendered file: %v", entry, err)
+		}
+		if entry.Template != entry.Path || entry.EggVersion != templates.Version {
+			t.Errorf("manifest entry %+v, want the template name and version", entry)
+		}
+	}
+	if m.GetProgress() != 1.0 {
+		t.Errorf("GetProgress() = %v after Run(), want 1.0", m.GetProgress())
+	}
+}
+
+func TestBootstrapFrameworkFilesFromTemplatesModule_Run_AggregatesErrors(t *testing.T) {
+	logger := createTestLogger(t)
+	defer logger.Close()
+	mapping := map[string]*template.Template{
+		"good.go": template.Must(template.New("good.go").Parse("")),
+		"bad1.go": template.Must(template.New("bad1.go").Parse("")),
+		"bad2.go": template.Must(template.New("bad2.go").Parse("")),
+	}
+	m := &BootstrapFrameworkFilesFromTemplatesModule{
+		mapping:       mapping,
+		configuration: createTestConfiguration(),
+		eggl:          logger,
+		PopulateTemplatesFunc: func(name string, template *template.Template) error {
+			if strings.HasPrefix(name, "bad") {
+				return errors.New("simulated error for " + name)
+			}
+			return nil
+		},
+	}
+
+	_, err := m.Run(context.Background())
+	if err == nil {
+		t.Fatal("Run() did not return an error")
+	}
+	if !strings.Contains(err.Error(), "bad1.go") || !strings.Contains(err.Error(), "bad2.go") {
+		t.Errorf("Run() error does not report every failed template: %v", err)
+	}
+	if m.IsError() == nil {
+		t.Error("IsError() = nil after a failed Run()")
+	}
+}
+
+func TestBootstrapFrameworkFilesFromTemplatesModule_Run_Merge(t *testing.T) {
+	tests := []struct {
+		name      string
+		conflicts Resolution
+		answers   []string
+		wantFile  string
+		wantNew   bool
+		wantFiles []string
+	}{
+		{name: "keep", conflicts: ResolveKeep, wantFile: "edited\n", wantFiles: []string{"new.go"}},
+		{name: "overwrite", conflicts: ResolveOverwrite, wantFile: "package testproject\n", wantFiles: []string{"changed.go", "new.go"}},
+		{name: "new", conflicts: ResolveNew, wantFile: "edited\n", wantNew: true, wantFiles: []string{"changed.go.egg-new", "new.go"}},
+		{name: "ask for the diff then overwrite", conflicts: ResolveAsk, answers: []string{"d", "what", "o"}, wantFile: "package testproject\n", wantFiles: []string{"changed.go", "new.go"}},
+		{name: "ask and keep", conflicts: ResolveAsk, answers: []string{"keep"}, wantFile: "edited\n", wantFiles: []string{"new.go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := createTestLogger(t)
+			defer logger.Close()
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "same.go"), []byte("package testproject\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "changed.go"), []byte("edited\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			mapping := make(map[string]*template.Template)
+			for _, name := range []string{"same.go", "changed.go", "new.go"} {
+				mapping[name] = template.Must(template.New(name).Parse("package {{.Name}}\n"))
+			}
+			var prompts []string
+			m := &BootstrapFrameworkFilesFromTemplatesModule{mapping: mapping, configuration: createTestConfiguration(), eggl: logger}
+			if tt.answers != nil {
+				m.InputFunc = func(prompt string) string {
+					prompts = append(prompts, prompt)
+					answer := tt.answers[0]
+					tt.answers = tt.answers[1:]
+					return answer
+				}
+			}
+			m.SetWorkspace(Workspace{Dir: dir, Overwrite: OverwriteMerge, Conflicts: tt.conflicts})
+
+			result, err := m.Run(context.Background())
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			content, err := os.ReadFile(filepath.Join(dir, "changed.go"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != tt.wantFile {
+				t.Errorf("changed.go = %q, want %q", content, tt.wantFile)
+			}
+			_, err = os.Stat(filepath.Join(dir, "changed.go"+NewFileSuffix))
+			if (err == nil) != tt.wantNew {
+				t.Errorf("changed.go%s exists = %v, want %v", NewFileSuffix, err == nil, tt.wantNew)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "new.go")); err != nil {
+				t.Errorf("new.go was not created: %v", err)
+			}
+			sort.Strings(result.Files)
+			if strings.Join(result.Files, ",") != strings.Join(tt.wantFiles, ",") {
+				t.Errorf("Run() result files = %v, want %v", result.Files, tt.wantFiles)
+			}
+			for _, prompt := range prompts {
+				if !strings.Contains(prompt, "changed.go") {
+					t.Errorf("asked about a file that did not differ: %q", prompt)
+				}
+			}
+			if len(tt.answers) != 0 {
+				t.Errorf("%d answers were not asked for", len(tt.answers))
+			}
+			if len(result.Checkpoints) != len(mapping) {
+				t.Errorf("Run() checkpoints = %v, want every template", result.Checkpoints)
+			}
+		})
+	}
+}
+
+func TestBootstrapFrameworkFilesFromTemplatesModule_Interactive(t *testing.T) {
+	m := &BootstrapFrameworkFilesFromTemplatesModule{}
+	if m.Interactive() {
+		t.Error("Interactive() = true without --merge")
+	}
+	m.SetWorkspace(Workspace{Overwrite: OverwriteMerge})
+	if !m.Interactive() {
+		t.Error("Interactive() = false for a merge that asks")
+	}
+	m.SetWorkspace(Workspace{Overwrite: OverwriteMerge, Conflicts: ResolveNew})
+	if m.Interactive() {
+		t.Error("Interactive() = true for a merge with a resolution")
+	}
+}
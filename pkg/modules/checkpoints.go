@@ -0,0 +1,32 @@
+package modules
+
+// IResumable is implemented by modules that can skip the steps that a previous
+// run already completed. The runner calls Resume after LoadFromConfig with the
+// checkpoints stored in the .scrambled file, so that `egg_cli recover` never
+// repeats a step (a tool install, a written template, ...) that already succeeded.
+type IResumable interface {
+	Resume(completed []string)
+}
+
+// checkpoints is embedded by the modules to implement IResumable
+type checkpoints struct {
+	completed map[string]bool
+}
+
+// Resume marks the steps that were completed by a previous run
+func (c *checkpoints) Resume(completed []string) {
+	c.completed = make(map[string]bool, len(completed))
+	for _, step := range completed {
+		c.completed[step] = true
+	}
+}
+
+// isCompleted reports whether a previous run already completed step
+func (c *checkpoints) isCompleted(step string) bool {
+	return c.completed[step]
+}
+
+// checkpoint records that step was completed in this run
+func (r *Result) checkpoint(step string) {
+	r.Checkpoints = append(r.Checkpoints, step)
+}
@@ -0,0 +1,134 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"text/template"
+
+	"github.com/adamkali/egg_cli/pkg/targets"
+)
+
+func TestInstallToolsModule_Run_SkipsCheckpointedTools(t *testing.T) {
+	t.Chdir(t.TempDir())
+	logger := createTestLogger(t)
+	defer logger.Close()
+	m := &InstallToolsModule{eggl: logger}
+	m.Resume(toolTargets(targets.RequiredTools[:1]))
+
+	m.LookPathFunc = func(string) (string, error) { return "", errors.New("not found") }
+	var installed []string
+	m.InstallToolFunc = func(tool string) error {
+		installed = append(installed, tool)
+		return nil
+	}
+
+	result, err := m.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := toolTargets(targets.RequiredTools[1:]); !reflect.DeepEqual(installed, want) {
+		t.Errorf("installed %v, want %v", installed, want)
+	}
+	if !reflect.DeepEqual(result.Checkpoints, installed) {
+		t.Errorf("Checkpoints = %v, want %v", result.Checkpoints, installed)
+	}
+}
+
+func TestInstallToolsModule_Run_CheckpointsBeforeFailure(t *testing.T) {
+	t.Chdir(t.TempDir())
+	logger := createTestLogger(t)
+	defer logger.Close()
+	m := &InstallToolsModule{eggl: logger}
+
+	m.LookPathFunc = func(string) (string, error) { return "", errors.New("not found") }
+	m.InstallToolFunc = func(tool string) error {
+		if tool == targets.RequiredTools[1].Target() {
+			return errors.New("install failed")
+		}
+		return nil
+	}
+
+	result, err := m.Run(context.Background())
+	if err == nil {
+		t.Fatal("Run() error = nil, want the install error")
+	}
+	if want := toolTargets(targets.RequiredTools[:1]); !reflect.DeepEqual(result.Checkpoints, want) {
+		t.Errorf("Checkpoints = %v, want %v", result.Checkpoints, want)
+	}
+}
+
+func TestBootstrapDirectoriesModule_Run_SkipsCheckpointedDirectories(t *testing.T) {
+	logger := createTestLogger(t)
+	defer logger.Close()
+	m := &BootstrapDirectoriesModule{eggl: logger, Directories: []string{"dir1", "dir2"}}
+	m.Resume([]string{"dir1"})
+
+	var mu sync.Mutex
+	var created []string
+	m.MkdirFunc = func(dir string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		created = append(created, dir)
+		return nil
+	}
+
+	result, err := m.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := []string{"dir2"}; !reflect.DeepEqual(created, want) {
+		t.Errorf("created %v, want %v", created, want)
+	}
+	if want := []string{"dir2"}; !reflect.DeepEqual(result.Checkpoints, want) {
+		t.Errorf("Checkpoints = %v, want %v", result.Checkpoints, want)
+	}
+	if m.Progress != len(m.Directories) {
+		t.Errorf("Progress = %d, want %d", m.Progress, len(m.Directories))
+	}
+}
+
+func TestBootstrapFrameworkFilesFromTemplatesModule_Run_SkipsCheckpointedFiles(t *testing.T) {
+	logger := createTestLogger(t)
+	defer logger.Close()
+	m := &BootstrapFrameworkFilesFromTemplatesModule{
+		eggl: logger,
+		mapping: map[string]*template.Template{
+			"main.go":        nil,
+			"./cmd/root.go":  nil,
+			"./cmd/serve.go": nil,
+		},
+	}
+	m.Resume([]string{"cmd/root.go"})
+
+	var mu sync.Mutex
+	written := make(map[string]bool)
+	m.PopulateTemplatesFunc = func(name string, _ *template.Template) error {
+		mu.Lock()
+		defer mu.Unlock()
+		written[name] = true
+		return nil
+	}
+
+	result, err := m.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if written["./cmd/root.go"] || len(written) != 2 {
+		t.Errorf("written %v, want every file but cmd/root.go", written)
+	}
+	if len(result.Checkpoints) != 2 {
+		t.Errorf("Checkpoints = %v, want the two written files", result.Checkpoints)
+	}
+}
+
+func TestResume_ReplacesPreviousCheckpoints(t *testing.T) {
+	var c checkpoints
+	c.Resume([]string{"a"})
+	c.Resume(nil)
+	if c.isCompleted("a") {
+		t.Error("Resume(nil) kept the checkpoints of the previous resume")
+	}
+}
@@ -0,0 +1,152 @@
+package modules
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is how many unchanged lines are shown around every change
+const diffContext = 3
+
+// diffOp is a single line of an edit script
+type diffOp struct {
+	kind byte // ' ' kept, '-' removed, '+' added
+	line string
+}
+
+// unifiedDiff
+//
+// params:
+//
+//	name: string
+//	old: string
+//	new: string
+//
+// returns:
+//
+//	string: the changes from old to new in the unified diff format, empty if they are equal
+//
+// description:
+//
+//	Computes the longest common subsequence of the lines, which is fast enough for the
+//	size of a template, and prints every change with diffContext lines around it.
+func unifiedDiff(name string, old string, new string) string {
+	if old == new {
+		return ""
+	}
+	ops := diffLines(splitLines(old), splitLines(new))
+
+	var builder strings.Builder
+	builder.WriteString("--- " + name + "\n")
+	builder.WriteString("+++ " + name + NewFileSuffix + "\n")
+	for start := 0; start < len(ops); {
+		// find the next change and the end of its hunk
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		end := first
+		for unchanged := 0; end < len(ops) && unchanged <= 2*diffContext; end++ {
+			if ops[end].kind == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+			}
+		}
+		// trim the unchanged lines after the last change down to the context
+		for end > first && ops[end-1].kind == ' ' {
+			end--
+		}
+		from := max(first-diffContext, start)
+		to := min(end+diffContext, len(ops))
+		writeHunk(&builder, ops, from, to)
+		start = to
+	}
+	return builder.String()
+}
+
+// writeHunk writes ops[from:to] with a header of the line numbers it covers
+func writeHunk(builder *strings.Builder, ops []diffOp, from, to int) {
+	oldStart, newStart := 1, 1
+	for _, op := range ops[:from] {
+		if op.kind != '+' {
+			oldStart++
+		}
+		if op.kind != '-' {
+			newStart++
+		}
+	}
+	oldLines, newLines := 0, 0
+	for _, op := range ops[from:to] {
+		if op.kind != '+' {
+			oldLines++
+		}
+		if op.kind != '-' {
+			newLines++
+		}
+	}
+	// an empty range is numbered by the line before it
+	if oldLines == 0 {
+		oldStart--
+	}
+	if newLines == 0 {
+		newStart--
+	}
+	builder.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", oldStart, oldLines, newStart, newLines))
+	for _, op := range ops[from:to] {
+		builder.WriteString(string(op.kind) + op.line + "\n")
+	}
+}
+
+// diffLines returns the edit script from a to b
+func diffLines(a, b []string) []diffOp {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, max(len(a), len(b)))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+// splitLines splits s into its lines without the trailing newline
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,40 @@
+package modules
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want string
+	}{
+		{
+			name: "equal",
+			old:  "a\nb\n",
+			new:  "a\nb\n",
+			want: "",
+		},
+		{
+			name: "separate hunks",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			new:  "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n",
+			want: "--- a.go\n+++ a.go.egg-new\n" +
+				"@@ -1,6 +1,6 @@\n 1\n 2\n-3\n+three\n 4\n 5\n 6\n" +
+				"@@ -8,3 +8,4 @@\n 8\n 9\n 10\n+11\n",
+		},
+		{
+			name: "new file content",
+			old:  "",
+			new:  "package main\n",
+			want: "--- a.go\n+++ a.go.egg-new\n@@ -0,0 +1,1 @@\n+package main\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("a.go", tt.old, tt.new); got != tt.want {
+				t.Errorf("unifiedDiff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
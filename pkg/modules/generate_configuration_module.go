@@ -1,7 +1,9 @@
 package modules
 
 import (
+	"context"
 	"fmt"
+	"sync"
 
 	"github.com/adamkali/egg_cli/pkg/configuration"
 	"github.com/adamkali/egg_cli/pkg/models"
@@ -9,35 +11,69 @@ import (
 )
 
 type GenerateConfigurationModule struct {
+	projectWorkspace
 	Configuration      *configuration.Configuration
 	Error              error
 	Progress           int
+	mu                 sync.Mutex
 	eggl               *models.EggLog
 	GenerateConfigFunc func(environment string) error // For testing - can be injected to mock config generation
 }
 
-func (m *GenerateConfigurationModule) Name() string   { return "egg::generate_configuration" }
+func (m *GenerateConfigurationModule) Name() string   { return GenerateConfigurationModuleID }
 func (m *GenerateConfigurationModule) IsError() error { return m.Error }
 
-func (m *GenerateConfigurationModule) Run() {
+// GetProgress returns 1 once the configuration file was written
+func (m *GenerateConfigurationModule) GetProgress() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return float64(m.Progress)
+}
+
+// IncrProg increments the progress by 1
+func (m *GenerateConfigurationModule) IncrProg() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Progress += 1
+}
+
+// Describe returns the configuration file that would be written
+func (m *GenerateConfigurationModule) Describe() Plan {
+	return Plan{
+		Module: m.Name(),
+		Files:  []string{configuration.ConfigurationDir + "development.yaml"},
+	}
+}
+
+func (m *GenerateConfigurationModule) Run(ctx context.Context) (Result, error) {
+	result := newResult(m)
+	if err := ctx.Err(); err != nil {
+		m.Error = err
+		return result, m.Error
+	}
 	generateConfigurationStart := "🥚 " + m.Name() + " start\n"
 	m.eggl.Info(generateConfigurationStart)
 	generateConfigurationStart = styles.EggProgressInfo.Render(generateConfigurationStart)
 	fmt.Println(generateConfigurationStart)
+	configurationFile := configuration.ConfigurationDir + "development.yaml"
+	result.addUndo(snapshotDirectory(m.projectPath(configuration.ConfigurationDir)), snapshotFile(m.projectPath(configurationFile)))
 	var err error
 	if m.GenerateConfigFunc != nil {
 		err = m.GenerateConfigFunc("development")
 	} else {
-		err = m.Configuration.GenerateConfigurationFile("development")
+		err = m.Configuration.GenerateConfigurationFileIn(m.projectPath("."), "development")
 	}
 	if err != nil {
 		m.Error = err
 		m.eggl.Error("error: %s", m.Error.Error())
-		return
+		return result, m.Error
 	}
+	result.Files = append(result.Files, configurationFile)
+	m.IncrProg()
 	m.eggl.Info("🥚 " + m.Name() + " complete")
 	generateConfigurationComplete := styles.EggProgressInfo.Render("🥚 " + m.Name() + " complete\n")
 	fmt.Println(generateConfigurationComplete)
+	return result, nil
 }
 
 func (m *GenerateConfigurationModule) LoadFromConfig(
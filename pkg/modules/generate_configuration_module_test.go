@@ -1,6 +1,7 @@
 package modules
 
 import (
+	"context"
 	"errors"
 	"testing"
 )
@@ -51,7 +52,7 @@ func TestGenerateConfigurationModule_Run_Success(t *testing.T) {
 		return nil
 	}
 
-	m.Run()
+	m.Run(context.Background())
 	if m.IsError() != nil {
 		t.Errorf("Run() set unexpected error: %v", m.IsError())
 	}
@@ -68,8 +69,20 @@ func TestGenerateConfigurationModule_Run_Error(t *testing.T) {
 		return errors.New("simulated error")
 	}
 
-	m.Run()
+	m.Run(context.Background())
 	if m.IsError() == nil {
 		t.Error("Run() did not set error on failure")
 	}
 }
+
+func TestGenerateConfigurationModule_Describe(t *testing.T) {
+	m := &GenerateConfigurationModule{}
+	m.GenerateConfigFunc = func(environment string) error {
+		t.Error("Describe() must not generate the configuration")
+		return nil
+	}
+	plan := m.Describe()
+	if len(plan.Files) != 1 || plan.Files[0] != "config/development.yaml" {
+		t.Errorf("Describe() Files = %v, want [config/development.yaml]", plan.Files)
+	}
+}
@@ -0,0 +1,73 @@
+package modules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// the oldest go release that can build an egg project
+const (
+	MinimumGoMajor = 1
+	MinimumGoMinor = 23
+)
+
+// ParseGoVersion
+//
+// params:
+//
+//	output: string
+//
+// returns:
+//
+//	int: the major version
+//	int: the minor version
+//	error: if output does not contain a go release
+//
+// description:
+//
+//	Reads the release from the output of `go version`, which looks like
+//	`go version go1.24.4 linux/amd64`. Release candidates (go1.25rc1) count as
+//	their release.
+func ParseGoVersion(output string) (int, int, error) {
+	for _, field := range strings.Fields(output) {
+		release, ok := strings.CutPrefix(field, "go")
+		if !ok || release == "" || release[0] < '0' || release[0] > '9' {
+			continue
+		}
+		parts := strings.SplitN(release, ".", 3)
+		if len(parts) < 2 {
+			break
+		}
+		major, err := strconv.Atoi(parts[0])
+		if err != nil {
+			break
+		}
+		minor, err := strconv.Atoi(leadingDigits(parts[1]))
+		if err != nil {
+			break
+		}
+		return major, minor, nil
+	}
+	return 0, 0, fmt.Errorf("no go release in %q", strings.TrimSpace(output))
+}
+
+// CheckGoVersion returns an error if the output of `go version` is older than the minimum go release
+func CheckGoVersion(output string) error {
+	major, minor, err := ParseGoVersion(output)
+	if err != nil {
+		return err
+	}
+	if major < MinimumGoMajor || (major == MinimumGoMajor && minor < MinimumGoMinor) {
+		return fmt.Errorf("go version must be at least %d.%d current version: %d.%d", MinimumGoMajor, MinimumGoMinor, major, minor)
+	}
+	return nil
+}
+
+func leadingDigits(s string) string {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	return s[:end]
+}
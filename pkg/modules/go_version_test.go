@@ -0,0 +1,32 @@
+package modules
+
+import "testing"
+
+func TestParseGoVersion(t *testing.T) {
+	tests := []struct {
+		output       string
+		major, minor int
+	}{
+		{"go version go1.24.4 linux/amd64\n", 1, 24},
+		{"go version go1.23 darwin/arm64", 1, 23},
+		{"go version go1.25rc1 linux/amd64", 1, 25},
+	}
+	for _, tt := range tests {
+		major, minor, err := ParseGoVersion(tt.output)
+		if err != nil || major != tt.major || minor != tt.minor {
+			t.Errorf("ParseGoVersion(%q) = %d, %d, %v, want %d, %d", tt.output, major, minor, err, tt.major, tt.minor)
+		}
+	}
+	if _, _, err := ParseGoVersion("command not found"); err == nil {
+		t.Error("ParseGoVersion() found a release in an error message")
+	}
+}
+
+func TestCheckGoVersion(t *testing.T) {
+	if err := CheckGoVersion("go version go1.22.5 linux/amd64"); err == nil {
+		t.Error("CheckGoVersion() accepted go1.22")
+	}
+	if err := CheckGoVersion("go version go1.23.0 linux/amd64"); err != nil {
+		t.Errorf("CheckGoVersion() = %v, want go1.23 to be accepted", err)
+	}
+}
@@ -1,34 +1,37 @@
 package modules
 
 import (
+	"context"
+
 	"github.com/adamkali/egg_cli/pkg/configuration"
 	"github.com/adamkali/egg_cli/pkg/models"
 )
 
 type IModule interface {
-	Run()
+	// Run executes the module and stops as soon as possible once ctx is cancelled.
+	// The Result is returned even on error so the runner can report partial work.
+	Run(ctx context.Context) (Result, error)
 	Name() string
-	IsError() error
 	LoadFromConfig(configuration *configuration.Configuration, eggl *models.EggLog)
+	// Describe returns what Run would do without touching the disk or the network
+	Describe() Plan
 }
 
+// The stable IDs of the built in modules. They are returned by Name(), recorded in
+// the .scrambled file and accepted by `egg_cli recover --from/--only`, so they must
+// never change. A renamed module keeps its old ID as an alias in the registry.
+const (
+	InitializeModuleID            = "egg::initialize"
+	InstallToolsModuleID          = "egg::install_tools"
+	InstallLibrariesModuleID      = "egg::install_libraries"
+	BootstrapDirectoriesModuleID  = "egg::bootstrap_directories"
+	GenerateConfigurationModuleID = "egg::generate_configuration"
+	BootstrapFrameworkModuleID    = "egg::bootstrap_framework"
+	RsbuildFrontendModuleID       = "egg::rsbuild_frontend"
+)
+
+// ModuleFactory returns a new instance of the module registered in DefaultRegistry
+// by the given ID or alias, or nil if there is none
 func ModuleFactory(moduleName string) IModule {
-	switch moduleName {
-	case "egg::initialize":
-		return &InitializeModule{}
-	case "egg::install-tools":
-		return &InstallToolsModule{}
-	case "egg::install-libraries":
-		return &InstallLibrariesModule{}
-	case "egg::bootstrap-directories":
-		return &BootstrapDirectoriesModule{}
-	case "egg::generate-configuration":
-		return &GenerateConfigurationModule{}
-	case "egg::bootstrap-framework-files-from-templates":
-		return &BootstrapFrameworkFilesFromTemplatesModule{}
-	case "egg::rsbuild-frontend":
-		return &RsbuildFrontendModule{}
-	default:
-		return nil
-	}
+	return DefaultRegistry.New(moduleName)
 }
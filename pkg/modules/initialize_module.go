@@ -1,12 +1,12 @@
 package modules
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
-	"os/exec"
-	"strconv"
-	"strings"
+	"path/filepath"
+	"sync"
 
 	"github.com/adamkali/egg_cli/pkg/configuration"
 	"github.com/adamkali/egg_cli/pkg/models"
@@ -16,26 +16,33 @@ import (
 //lipgloss "github.com/charmbracelet/lipgloss"
 
 type InitializeModule struct {
+	checkpoints
+	projectWorkspace
 	eggl        *models.EggLog
 	Namespace   string
 	ProjectName string
 	License     string
 	Progress    int
 	Error       error
+	mu          sync.Mutex
 }
 
 func (m *InitializeModule) Name() string {
-	return "egg::initialize"
+	return InitializeModuleID
 }
 
 var maxprog_init = float64(4)
 
 func (m *InitializeModule) GetProgress() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	return float64(m.Progress) / maxprog_init
 }
 
 // incrprog increments the progress by 1
 func (m *InitializeModule) IncrProg() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.Progress += 1
 	return
 }
@@ -52,103 +59,158 @@ func (m *InitializeModule) LoadFromConfig(configuration *configuration.Configura
 // IsError checks if there is an error in the module.
 // if there is none it returns nil
 // if there is an error it returns the stored error
-func (m InitializeModule) IsError() error {
+func (m *InitializeModule) IsError() error {
 	return m.Error
 }
 
-func (m *InitializeModule) Run() {
-	// create a directory for the project
+// Describe returns the directory and go commands used to initialize the project
+func (m *InitializeModule) Describe() Plan {
+	notes := []string{"every following path is relative to " + m.projectDir()}
+	switch m.workspace.Overwrite {
+	case OverwriteForce:
+		notes = append(notes, "moves "+m.projectDir()+" to "+m.projectDir()+PreviousSuffix+" first if it already exists, and removes it once the run is over")
+	case OverwriteMerge:
+		notes = append(notes, "keeps what is already in "+m.projectDir()+", go mod init is skipped if go.mod exists")
+	default:
+		notes = append(notes, "fails if "+m.projectDir()+" already exists and is not empty")
+	}
+	return Plan{
+		Module:      m.Name(),
+		Directories: []string{m.projectDir()},
+		Commands: []string{
+			"go version",
+			"go mod init " + m.Namespace,
+		},
+		Notes: notes,
+	}
+}
+
+// projectDir returns the project directory, the project name when no workspace was set
+func (m *InitializeModule) projectDir() string {
+	if m.workspace.Dir == "" {
+		return m.ProjectName
+	}
+	return m.workspace.Dir
+}
+
+// Run
+//
+// params:
+//
+//	ctx: context.Context
+//
+// returns:
+//
+//	Result: the project directory, go.mod and the go commands that ran
+//	error:
+//	  - a ProjectExistsError if the project directory is not empty and the policy is OverwriteNever
+//	  - if the go release is too old
+//	  - if the directory could not be created or go mod init failed
+//
+// description:
+//
+//	Creates the project directory of the workspace with os.MkdirAll and initializes
+//	the go module inside of it. What happens to an existing project directory is
+//	decided by the overwrite policy of the workspace instead of asking, so the module
+//	never reads from stdin and never changes the working directory of the process.
+func (m *InitializeModule) Run(ctx context.Context) (Result, error) {
+	result := newResult(m)
+	dir := m.projectDir()
 	initModuleStart := styles.EggProgressInfo.Render("🥚 " + m.Name() + " start\n")
-	initModuleMkdirMessage := styles.EggProgressInfo.Render("🥚 " + m.Name() + " creating project root directory\n")
+	initModuleMkdirMessage := styles.EggProgressInfo.Render("🥚 " + m.Name() + " creating project root directory " + dir + "\n")
 	initModuleGoVersionMessage := styles.EggProgressInfo.Render("🥚 " + m.Name() + " checking go version\n")
 	initModuleGoModInitMessage := styles.EggProgressInfo.Render("🥚 " + m.Name() + " initializing go module\n")
-	initModuleChangingDirectoryMessage := styles.EggProgressInfo.Render("🥚 " + m.Name() + " changing directory to " + m.ProjectName + "\n")
 	initModuleCompletSuccessMessage := styles.EggProgressInfo.Render("🥚 " + m.Name() + " initialization complete\n")
 	fmt.Println(initModuleStart)
 
-	fmt.Println(initModuleMkdirMessage)
-	output, err := exec.Command("mkdir", m.ProjectName).Output()
-	if err != nil {
-		if err.Error() == "exit status 1" {
-			// ask the user if they want to overwrite the directory
-			// if they do not want to overwrite the directory, return the error
-			fmt.Println("project directory already exists, do you want to overwrite it? (y/n)")
-			var overwrite string
-			fmt.Scanln(&overwrite)
-			if overwrite == "y" || overwrite == "Y" || overwrite == "yes" || overwrite == "Yes" || overwrite == "YES" {
-				deleteErr := os.RemoveAll(m.ProjectName)
-				if deleteErr != nil {
-					m.Error = errors.New("error deleting project directory: " + m.ProjectName + " " + deleteErr.Error())
-					m.eggl.Error("error: %s", m.Error.Error())
-					return
-				}
-				// create the directory again
-				exec.Command("mkdir", m.ProjectName).Output()
-				m.Error = nil
-			} else {
-				m.Error = errors.New("project directory already exists")
-				m.eggl.Error("error: %s", m.Error.Error())
-				return
-			}
+	if err := ctx.Err(); err != nil {
+		m.Error = err
+		return result, m.Error
+	}
+
+	// a resumed run already created the project directory and may have written
+	// files into it, so the overwrite policy does not apply to it anymore
+	if !m.isCompleted("mkdir") {
+		fmt.Println(initModuleMkdirMessage)
+		if err := m.mkdir(&result, dir); err != nil {
+			m.Error = err
+			m.eggl.Error("error: %s", m.Error.Error())
+			return result, m.Error
 		}
+		result.checkpoint("mkdir")
 	}
+	m.IncrProg()
 
 	fmt.Println(initModuleGoVersionMessage)
-	output, err = exec.Command("go", "version").Output()
+	output, err := result.runCommand(ctx, "go", "version")
 	if err != nil {
 		m.eggl.Error("error: %s", err.Error())
 		m.Error = err
-		return
+		return result, m.Error
 	}
 	m.Error = nil
 	m.IncrProg()
 
-	// go version returns:
-	//go version go1.24.4 linux/amd64
-	goVersion := strings.Split(string(output), " ")[2]
-	goVersion = strings.Split(goVersion, ".")[1]
-
-	goVersionF, err := strconv.ParseFloat(goVersion, 64)
-	if err != nil {
-		m.eggl.Error("error: %s", err.Error())
+	if err := CheckGoVersion(string(output)); err != nil {
 		m.Error = err
-		return
-	}
-	if goVersionF < 23.0 {
-		m.Error = errors.New("go version must be at least 1.23 current version: " + goVersion)
 		m.eggl.Error("error: %s", m.Error.Error())
-		return
+		return result, m.Error
 	}
 	m.Error = nil
 	m.IncrProg()
 
-	fmt.Println(initModuleChangingDirectoryMessage)
-	err = os.Chdir(m.ProjectName)
-	if err != nil {
-		m.eggl.Error("error: %s", err.Error())
-		m.Error = err
-		return
+	if !m.isCompleted("go mod init") {
+		goMod := filepath.Join(dir, "go.mod")
+		if _, err := os.Stat(goMod); err == nil && m.workspace.Overwrite == OverwriteMerge {
+			m.eggl.Info("🥚 %s keeping the existing %s", m.Name(), goMod)
+		} else {
+			fmt.Println(initModuleGoModInitMessage)
+			// put it in the root project
+			result.addUndo(snapshotFile(goMod))
+			if _, err := result.runCommandIn(ctx, dir, nil, "go", "mod", "init", m.Namespace); err != nil {
+				m.eggl.Error("error: %s", err.Error())
+				m.Error = err
+				return result, m.Error
+			}
+			result.Files = append(result.Files, "go.mod")
+		}
+		result.checkpoint("go mod init")
+		m.Error = nil
 	}
-	m.Error = nil
 	m.IncrProg()
 
-	fmt.Println(initModuleGoModInitMessage)
-	// go mod init
-	// put it in the root project
-	output, err = exec.Command(
-		"go",
-		"mod",
-		"init",
-		m.Namespace,
-	).Output()
-	if err != nil {
-		m.eggl.Error("error: %s", err.Error())
-		m.Error = err
-		return
+	fmt.Println(initModuleCompletSuccessMessage)
+	return result, nil
+}
+
+// mkdir creates the project directory according to the overwrite policy of the workspace
+func (m *InitializeModule) mkdir(result *Result, dir string) error {
+	if err := CheckWorkspace(Workspace{Dir: dir, Overwrite: m.workspace.Overwrite}); err != nil {
+		return err
 	}
-	m.Error = nil
-	m.IncrProg()
+	if m.workspace.Overwrite == OverwriteForce {
+		// the old project is removed once the run is over, a rollback puts it back
+		previous, err := moveAside(dir)
+		if err != nil {
+			return err
+		}
+		result.addUndo(previous)
+	}
+	// removing the project directory also removes go.mod
+	result.addUndo(snapshotDirectory(dir))
+	return result.record("mkdir -p "+dir, func() error {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return errors.New("error creating project directory: " + dir + " " + err.Error())
+		}
+		result.Directories = append(result.Directories, dir)
+		return nil
+	})
+}
 
-	fmt.Println(initModuleCompletSuccessMessage)
-	return
+// absPath returns the absolute path of name, or name itself if it can not be made absolute
+func absPath(name string) string {
+	if abs, err := filepath.Abs(name); err == nil {
+		return abs
+	}
+	return name
 }
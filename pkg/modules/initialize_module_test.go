@@ -1,8 +1,11 @@
 package modules
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/adamkali/egg_cli/pkg/models"
@@ -74,11 +77,11 @@ func TestInitializeModule_LoadFromConfig(t *testing.T) {
 func TestInitializeModule_IsError(t *testing.T) {
 	tests := []struct {
 		name     string
-		module   InitializeModule
+		module   *InitializeModule
 		expected error
 	}{
-		{"no error", InitializeModule{Error: nil}, nil},
-		{"with error", InitializeModule{Error: os.ErrNotExist}, os.ErrNotExist},
+		{"no error", &InitializeModule{Error: nil}, nil},
+		{"with error", &InitializeModule{Error: os.ErrNotExist}, os.ErrNotExist},
 	}
 
 	for _, tt := range tests {
@@ -117,20 +120,18 @@ func TestInitializeModule_Run_Success(t *testing.T) {
 	module.LoadFromConfig(config, logger)
 
 	// Run the module
-	module.Run()
+	module.Run(context.Background())
 
 	// Check for errors
 	if err := module.IsError(); err != nil {
 		t.Errorf("InitializeModule.Run() failed with error: %v", err)
 	}
 
-	// The module changes directory to the project directory, so we need to check from the current directory
-	// First, let's check what directory we're in
-	currentDir, _ := os.Getwd()
-	t.Logf("Current directory after module run: %s", currentDir)
-
-	// Check that go.mod file was created in the current directory (which should be the project directory)
-	goModPath := "go.mod"
+	// The module must not change the working directory, go.mod is created inside of the project directory
+	if currentDir, _ := os.Getwd(); filepath.Base(currentDir) != testDir {
+		t.Errorf("InitializeModule.Run() changed the working directory to %s", currentDir)
+	}
+	goModPath := filepath.Join(config.Name, "go.mod")
 	if _, err := os.Stat(goModPath); os.IsNotExist(err) {
 		t.Errorf("go.mod file was not created: %s", goModPath)
 	}
@@ -143,52 +144,101 @@ func TestInitializeModule_Run_Success(t *testing.T) {
 }
 
 func TestInitializeModule_Run_DirectoryExists(t *testing.T) {
-	// Create test directory
-	testDir := "test_data"
-	if err := os.MkdirAll(testDir, 0755); err != nil {
-		t.Fatalf("Failed to create test directory: %v", err)
+	tests := []struct {
+		name      string
+		overwrite OverwritePolicy
+		wantErr   bool
+		// wantKept is whether the file that was already in the project directory is kept
+		wantKept bool
+	}{
+		{"never", OverwriteNever, true, true},
+		{"force", OverwriteForce, false, false},
+		{"merge", OverwriteMerge, false, true},
 	}
-	defer os.RemoveAll(testDir)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+			config := createTestConfiguration()
+			logger := createTestLogger(t)
+			defer logger.Close()
+
+			// Create the project directory first to simulate it already existing
+			dir, _ := filepath.Abs(config.Name)
+			existing := filepath.Join(dir, "test.txt")
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				t.Fatalf("Failed to create existing project directory: %v", err)
+			}
+			if err := os.WriteFile(existing, []byte("test"), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
 
-	// Change to test directory
-	originalDir, err := os.Getwd()
-	if err != nil {
-		t.Fatalf("Failed to get current directory: %v", err)
-	}
-	defer os.Chdir(originalDir)
+			module := &InitializeModule{}
+			module.LoadFromConfig(config, logger)
+			module.SetWorkspace(Workspace{Dir: dir, Overwrite: tt.overwrite})
+			_, err := module.Run(context.Background())
 
-	if err := os.Chdir(testDir); err != nil {
-		t.Fatalf("Failed to change to test directory: %v", err)
+			var existsErr *ProjectExistsError
+			if tt.wantErr != errors.As(err, &existsErr) {
+				t.Fatalf("Run() error = %v, want a ProjectExistsError: %v", err, tt.wantErr)
+			}
+			if _, err := os.Stat(existing); (err == nil) != tt.wantKept {
+				t.Errorf("test.txt kept = %v, want %v", err == nil, tt.wantKept)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "go.mod")); (err == nil) == tt.wantErr {
+				t.Errorf("go.mod created = %v, want %v", err == nil, !tt.wantErr)
+			}
+		})
 	}
+}
 
+func TestInitializeModule_Run_MergeKeepsGoMod(t *testing.T) {
+	t.Chdir(t.TempDir())
 	config := createTestConfiguration()
-	logger, _ := models.NewLogger("test.log")
+	logger := createTestLogger(t)
 	defer logger.Close()
+	dir := t.TempDir()
+	goMod := []byte("module example.com/existing\n\ngo 1.23\n")
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), goMod, 0644); err != nil {
+		t.Fatal(err)
+	}
 
 	module := &InitializeModule{}
 	module.LoadFromConfig(config, logger)
-
-	// Create the project directory first to simulate it already existing
-	projectPath := filepath.Join(testDir, config.Name)
-	if err := os.MkdirAll(projectPath, 0755); err != nil {
-		t.Fatalf("Failed to create existing project directory: %v", err)
+	module.SetWorkspace(Workspace{Dir: dir, Overwrite: OverwriteMerge})
+	result, err := module.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
 	}
-
-	// Create a test file in the directory to verify it gets removed
-	testFile := filepath.Join(projectPath, "test.txt")
-	if err := os.WriteFile(testFile, []byte("test"), 0644); err != nil {
-		t.Fatalf("Failed to create test file: %v", err)
+	if content, _ := os.ReadFile(filepath.Join(dir, "go.mod")); string(content) != string(goMod) {
+		t.Errorf("Run() replaced the existing go.mod:\n%s", content)
+	}
+	for _, command := range result.Commands {
+		if strings.HasPrefix(command.Command, "go mod init") {
+			t.Errorf("Run() ran %s in a project that has a go.mod", command.Command)
+		}
 	}
+	if module.Progress != 4 {
+		t.Errorf("Run() Progress = %d, want 4", module.Progress)
+	}
+}
 
-	// Run the module - this should prompt for overwrite, but in test we can't interact
-	// So we'll just verify the module handles the existing directory gracefully
-	module.Run()
+func TestInitializeModule_Run_ForceRefusesTheWorkingDirectory(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.WriteFile("test.txt", []byte("test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	logger := createTestLogger(t)
+	defer logger.Close()
 
-	// The module should have encountered the existing directory
-	// In a real scenario, this would prompt the user
-	// For testing purposes, we'll just verify the module doesn't crash
-	if module.Progress > 0 {
-		t.Logf("Module progressed %d steps despite existing directory", module.Progress)
+	module := &InitializeModule{}
+	module.LoadFromConfig(createTestConfiguration(), logger)
+	module.SetWorkspace(Workspace{Dir: dir, Overwrite: OverwriteForce})
+	if _, err := module.Run(context.Background()); err == nil {
+		t.Fatal("Run() error = nil, want a refusal to remove the working directory")
+	}
+	if _, err := os.Stat("test.txt"); err != nil {
+		t.Errorf("Run() removed the working directory: %v", err)
 	}
 }
 
@@ -219,7 +269,7 @@ func TestInitializeModule_Run_GoVersionCheck(t *testing.T) {
 	module.LoadFromConfig(config, logger)
 
 	// Run the module
-	module.Run()
+	module.Run(context.Background())
 
 	// Check that Go version check passed (should be at least 1.23)
 	if err := module.IsError(); err != nil {
@@ -259,7 +309,7 @@ func TestInitializeModule_Run_ModuleInitialization(t *testing.T) {
 	module.LoadFromConfig(config, logger)
 
 	// Run the module
-	module.Run()
+	module.Run(context.Background())
 
 	// Check for errors
 	if err := module.IsError(); err != nil {
@@ -267,7 +317,7 @@ func TestInitializeModule_Run_ModuleInitialization(t *testing.T) {
 	}
 
 	// Check that go.mod was created with correct module name
-	goModPath := "go.mod"
+	goModPath := filepath.Join(config.Name, "go.mod")
 
 	if _, err := os.Stat(goModPath); os.IsNotExist(err) {
 		t.Errorf("go.mod file was not created: %s", goModPath)
@@ -307,3 +357,26 @@ func containsSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestInitializeModule_Describe(t *testing.T) {
+	config := createTestConfiguration()
+	logger := createTestLogger(t)
+	defer logger.Close()
+
+	module := &InitializeModule{}
+	module.LoadFromConfig(config, logger)
+	plan := module.Describe()
+
+	if plan.Module != module.Name() {
+		t.Errorf("Describe() Module = %q, want %q", plan.Module, module.Name())
+	}
+	if len(plan.Directories) != 1 || plan.Directories[0] != config.Name {
+		t.Errorf("Describe() Directories = %v, want [%s]", plan.Directories, config.Name)
+	}
+	if !containsSubstring(plan.Commands[len(plan.Commands)-1], "go mod init "+config.Namespace) {
+		t.Errorf("Describe() Commands = %v, want go mod init %s", plan.Commands, config.Namespace)
+	}
+	if _, err := os.Stat(config.Name); !os.IsNotExist(err) {
+		t.Errorf("Describe() must not create %s", config.Name)
+	}
+}
@@ -1,77 +1,478 @@
 package modules
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
 
 	"github.com/adamkali/egg_cli/pkg/configuration"
 	"github.com/adamkali/egg_cli/pkg/models"
 	"github.com/adamkali/egg_cli/pkg/targets"
+	"github.com/adamkali/egg_cli/pkg/templates"
 	"github.com/adamkali/egg_cli/styles"
+	"golang.org/x/mod/modfile"
 )
 
 type InstallLibrariesModule struct {
-	eggl      *models.EggLog
-	Progress  int
-	Error     error
-	GoGetFunc func(pac string) error // For testing - can be injected to mock go get
+	checkpoints
+	offlineMode
+	projectWorkspace
+	configuration *configuration.Configuration
+	// mapping are the templates the required modules are computed from, nil is templates.Mapping
+	mapping  map[string]*template.Template
+	eggl     *models.EggLog
+	Progress int
+	Error    error
+	mu       sync.Mutex
+	// steps is how many steps the running install has
+	steps     int
+	GoModFunc func(args ...string) ([]byte, error) // For testing - can be injected to mock the go mod commands
 }
 
 func (*InstallLibrariesModule) Name() string {
-	return "egg::install_libraries"
+	return InstallLibrariesModuleID
 
 }
 
-var maxprog_modules = float64(len(targets.GolangPackages))
+// the steps of Run, each of them is a checkpoint
+const (
+	librariesStepRequire  = "require"
+	librariesStepDownload = "go mod download"
+	librariesStepTidy     = "go mod tidy"
+	librariesStepVendor   = "vendor"
+)
+
+var maxprog_modules = 3.0
 
 func (m *InstallLibrariesModule) GetProgress() float64 {
-	return float64(m.Progress) / maxprog_modules
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.steps == 0 {
+		return float64(m.Progress) / maxprog_modules
+	}
+	return float64(m.Progress) / float64(m.steps)
+}
+
+// libraryStep is a single step of Run
+type libraryStep struct {
+	name string
+	run  func(ctx context.Context, result *Result) error
+}
+
+// plan returns the steps of Run, a vendor directory replaces the download and the tidy
+func (m *InstallLibrariesModule) plan() []libraryStep {
+	if m.offline.VendorDir != "" {
+		return []libraryStep{
+			{librariesStepRequire, m.require},
+			{librariesStepVendor, m.vendor},
+		}
+	}
+	return []libraryStep{
+		{librariesStepRequire, m.require},
+		{librariesStepDownload, m.download},
+		{librariesStepTidy, m.tidy},
+	}
 }
 
 // incrprog increments the progress by 1
 func (m *InstallLibrariesModule) IncrProg() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.Progress += 1
 	return
 }
 
-func (m *InstallLibrariesModule) Run() {
+// Run
+//
+// params:
+//
+//	ctx: context.Context
+//
+// returns:
+//
+//	Result: go.mod, go.sum and the go mod commands that ran
+//	error:
+//	  - if go.mod could not be read or written
+//	  - a ModuleDownloadError naming every module that could not be downloaded
+//	  - the output of go mod tidy if it failed
+//
+// description:
+//
+//	Requires every pinned module the rendered templates import (see RequiredModules)
+//	in go.mod at its pinned version, downloads all of them with a single `go mod download` and runs `go mod tidy` so
+//	that go.mod only keeps what the rendered templates import and go.sum is complete.
+//	It runs after the templates have been written for that reason. Offline the go
+//	commands only use the module cache, and with a vendor directory go.mod requires
+//	the modules of the vendor directory, which is copied into the project instead of
+//	downloading anything.
+func (m *InstallLibrariesModule) Run(ctx context.Context) (Result, error) {
+	result := newResult(m)
 	installLibrariesStart := styles.EggProgressInfo.Render("🥚 " + m.Name() + " start")
 	fmt.Println(installLibrariesStart)
-	for _, pac := range targets.GolangPackages {
-		installLibrariesMessage := fmt.Sprintf(
-			"🥚 %s installing %s",
-			m.Name(),
-			pac,
-		)
+	// every step rewrites go.mod and go.sum
+	result.addUndo(snapshotFile(m.projectPath("go.mod")), snapshotFile(m.projectPath("go.sum")))
+
+	steps := m.plan()
+	m.mu.Lock()
+	m.steps = len(steps)
+	m.mu.Unlock()
+	for _, step := range steps {
+		if err := ctx.Err(); err != nil {
+			m.Error = err
+			return result, m.Error
+		}
+		if m.isCompleted(step.name) {
+			m.IncrProg()
+			continue
+		}
+		installLibrariesMessage := fmt.Sprintf("🥚 %s %s", m.Name(), step.name)
 		m.eggl.Info(installLibrariesMessage)
-		installLibrariesMessage = styles.EggProgressInfo.Render(installLibrariesMessage)
-		fmt.Println(installLibrariesMessage)
-		err := m.GoGet(pac)
+		fmt.Println(styles.EggProgressInfo.Render(installLibrariesMessage))
+		if err := step.run(ctx, &result); err != nil {
+			m.Error = err
+			m.eggl.Error("error: %s", m.Error.Error())
+			return result, m.Error
+		}
+		result.checkpoint(step.name)
+		m.IncrProg()
+	}
+	result.Files = append(result.Files, "go.mod")
+	if m.offline.VendorDir == "" {
+		result.Files = append(result.Files, "go.sum")
+	}
+	m.Error = nil
+	return result, nil
+}
+
+// require adds every required module, or every module of the vendor directory, to go.mod
+func (m *InstallLibrariesModule) require(_ context.Context, result *Result) error {
+	required, err := m.requiredModules()
+	if err != nil {
+		return err
+	}
+	if m.offline.VendorDir != "" {
+		vendored, err := ReadVendorModules(m.offline.VendorDir)
+		if err != nil {
+			return err
+		}
+		// go build -mod=vendor checks that go.mod requires exactly the explicit modules
+		required = nil
+		for _, module := range vendored {
+			if module.Explicit {
+				required = append(required, targets.Module{Path: module.Path, Version: module.Version})
+			}
+		}
+	}
+	return result.record("require "+strconv.Itoa(len(required))+" modules in go.mod", func() error {
+		content, err := os.ReadFile(m.projectPath("go.mod"))
+		if err != nil {
+			return fmt.Errorf("failed to read go.mod: %w", err)
+		}
+		rendered, err := RequireModules(content, required)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(m.projectPath("go.mod"), rendered, 0644)
+	})
+}
+
+// RequiredModules
+//
+// params:
+//
+//	cfg: *configuration.Configuration
+//	mapping: map[string]*template.Template
+//
+// returns:
+//
+//	[]targets.Module: the pinned modules the project of the configuration imports
+//	error:
+//	  - a templates.DriftError, together with the modules, if the templates and the pins disagree
+//	  - if the templates could not be rendered
+//
+// description:
+//
+//	Computes the modules from the imports of the rendered templates, see
+//	templates.Dependencies. Without a configuration every pinned module is required, without
+//	a mapping the modules are computed from templates.Mapping.
+func RequiredModules(cfg *configuration.Configuration, mapping map[string]*template.Template) ([]targets.Module, error) {
+	if cfg == nil {
+		return append(append([]targets.Module(nil), targets.GolangModules...), targets.GeneratedModules...), nil
+	}
+	if mapping == nil {
+		mapping = templates.Mapping(cfg)
+	}
+	return templates.Dependencies(cfg, mapping)
+}
+
+// SetTemplates sets the templates the required modules are computed from
+func (m *InstallLibrariesModule) SetTemplates(mapping map[string]*template.Template) {
+	m.mapping = mapping
+}
+
+// requiredModules returns the modules to require, a drift is only reported because
+// go mod tidy resolves an import that is not pinned
+func (m *InstallLibrariesModule) requiredModules() ([]targets.Module, error) {
+	required, err := RequiredModules(m.configuration, m.mapping)
+	var drift *templates.DriftError
+	if errors.As(err, &drift) {
+		m.eggl.Info(fmt.Sprintf("🥚 %s warning: %s", m.Name(), drift.Error()))
+		return required, nil
+	}
+	return required, err
+}
+
+// RequireModules returns the go.mod content with every module required at its version
+func RequireModules(content []byte, required []targets.Module) ([]byte, error) {
+	file, err := modfile.Parse("go.mod", content, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse go.mod: %w", err)
+	}
+	for _, module := range required {
+		if err := file.AddRequire(module.Path, module.Version); err != nil {
+			return nil, fmt.Errorf("failed to require %s: %w", module, err)
+		}
+	}
+	file.SortBlocks()
+	file.Cleanup()
+	rendered, err := file.Format()
+	if err != nil {
+		return nil, fmt.Errorf("failed to format go.mod: %w", err)
+	}
+	return rendered, nil
+}
+
+// ModuleDownloadError lists the modules `go mod download` could not download
+type ModuleDownloadError struct {
+	// Failed maps path@version to the error go reported for it
+	Failed map[string]string
+}
+
+func (e *ModuleDownloadError) Error() string {
+	modules := make([]string, 0, len(e.Failed))
+	for module := range e.Failed {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+	lines := make([]string, len(modules))
+	for i, module := range modules {
+		lines[i] = module + ": " + e.Failed[module]
+	}
+	return fmt.Sprintf("failed to download %d modules:\n%s", len(modules), strings.Join(lines, "\n"))
+}
+
+// downloadedModule is a single object of the `go mod download -json` output
+type downloadedModule struct {
+	Path    string
+	Version string
+	Error   string
+}
+
+// download downloads every module of go.mod with a single `go mod download`
+func (m *InstallLibrariesModule) download(ctx context.Context, result *Result) error {
+	output, err := m.goMod(ctx, result, "download", "-json")
+	if err == nil {
+		return nil
+	}
+	if failed := parseDownloadErrors(output, err.Error()); len(failed.Failed) > 0 {
+		return failed
+	}
+	return fmt.Errorf("go mod download: %w", err)
+}
+
+// parseDownloadErrors
+//
+// params:
+//
+//	output: []byte
+//	stderr: string
+//
+// returns:
+//
+//	*ModuleDownloadError: every module that could not be downloaded
+//
+// description:
+//
+//	A module that can not be downloaded is reported in two ways: with an Error in its
+//	object of the `go mod download -json` output, or, when go could not even load the
+//	module graph, as a `go: path@version: reason` line on stderr.
+func parseDownloadErrors(output []byte, stderr string) *ModuleDownloadError {
+	failed := &ModuleDownloadError{Failed: map[string]string{}}
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for {
+		var module downloadedModule
+		if err := decoder.Decode(&module); err != nil {
+			// the output ends, or go printed something that is not a module
+			break
+		}
+		if module.Error != "" {
+			failed.Failed[module.Path+"@"+module.Version] = module.Error
+		}
+	}
+	for _, line := range strings.Split(stderr, "\n") {
+		i := strings.Index(line, "go: ")
+		if i < 0 {
+			continue
+		}
+		module, reason, ok := strings.Cut(line[i+len("go: "):], ": ")
+		if ok && strings.Contains(module, "@") && !strings.ContainsAny(module, " \t") {
+			failed.Failed[module] = reason
+		}
+	}
+	return failed
+}
+
+// tidy keeps the modules the rendered templates import and completes go.sum. -e keeps
+// going when a package is not there yet, e.g. the repository sqlc generates. Nothing
+// imports targets.GeneratedModules before their code is generated, so tidy drops them
+// and they are required again at their pinned version, together with their go.sum.
+func (m *InstallLibrariesModule) tidy(ctx context.Context, result *Result) error {
+	_, err := m.goMod(ctx, result, "tidy", "-e")
+	if err != nil {
+		return fmt.Errorf("go mod tidy: %w", err)
+	}
+	err = result.record("require "+strconv.Itoa(len(targets.GeneratedModules))+" generated modules in go.mod", func() error {
+		content, err := os.ReadFile(m.projectPath("go.mod"))
+		if err != nil {
+			return fmt.Errorf("failed to read go.mod: %w", err)
+		}
+		rendered, err := RequireModules(content, targets.GeneratedModules)
 		if err != nil {
-			return
+			return err
 		}
+		return os.WriteFile(m.projectPath("go.mod"), rendered, 0644)
+	})
+	if err != nil {
+		return err
+	}
+	// downloading a required module adds its hashes to go.sum
+	if _, err := m.goMod(ctx, result, append([]string{"download"}, generatedPaths()...)...); err != nil {
+		return fmt.Errorf("go mod download: %w", err)
 	}
+	return nil
 }
 
-func (m *InstallLibrariesModule) GoGet(pac string) error {
+// generatedPaths returns the paths of targets.GeneratedModules
+func generatedPaths() []string {
+	paths := make([]string, len(targets.GeneratedModules))
+	for i, module := range targets.GeneratedModules {
+		paths[i] = module.Path
+	}
+	return paths
+}
+
+// vendor copies the vendor directory into the project
+func (m *InstallLibrariesModule) vendor(_ context.Context, result *Result) error {
+	result.addUndo(snapshotDirectory(m.projectPath("vendor")))
+	return result.record("copy "+m.offline.VendorDir+" to vendor", func() error {
+		if err := copyDir(m.offline.VendorDir, m.projectPath("vendor")); err != nil {
+			return err
+		}
+		result.Directories = append(result.Directories, "vendor")
+		return nil
+	})
+}
+
+// copyDir copies every file of src into dst, keeping their permissions
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, content, info.Mode().Perm())
+	})
+}
+
+func (m *InstallLibrariesModule) goMod(ctx context.Context, result *Result, args ...string) ([]byte, error) {
+	args = append([]string{"mod"}, args...)
 	// Use injected function if available (for testing), otherwise use real implementation
-	if m.GoGetFunc != nil {
-		return m.GoGetFunc(pac)
+	if m.GoModFunc != nil {
+		var output []byte
+		err := result.record("go "+strings.Join(args, " "), func() error {
+			var err error
+			output, err = m.GoModFunc(args...)
+			return err
+		})
+		return output, err
+	}
+	output, err := result.runCommandIn(ctx, m.workspace.Dir, m.offline.Env(), "go", args...)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		// go explains what went wrong on stderr
+		err = fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
 	}
+	return output, err
+}
 
-	_, err := exec.Command(
-		"go", "get", pac).Output()
+// Describe returns the pinned modules and the go mod commands used to install them
+func (m *InstallLibrariesModule) Describe() Plan {
+	if m.offline.VendorDir != "" {
+		return Plan{
+			Module:      m.Name(),
+			Directories: []string{"vendor"},
+			Files:       []string{"go.mod"},
+			Notes: []string{
+				"requires the modules of " + filepath.Join(m.offline.VendorDir, VendorModulesFile),
+				"copies " + m.offline.VendorDir + " to vendor, nothing is downloaded",
+			},
+		}
+	}
+	env := strings.Join(m.offline.Env(), " ")
+	if env != "" {
+		env += " "
+	}
+	plan := Plan{
+		Module: m.Name(),
+		Files:  []string{"go.mod", "go.sum"},
+		Commands: []string{
+			env + "go mod download -json",
+			env + "go mod tidy -e",
+			env + "go mod download " + strings.Join(generatedPaths(), " "),
+		},
+	}
+	required, err := RequiredModules(m.configuration, m.mapping)
 	if err != nil {
-		m.Error = err
+		plan.Notes = append(plan.Notes, err.Error())
 	}
-	return m.Error
+	for _, module := range required {
+		plan.Notes = append(plan.Notes, "requires "+module.String())
+	}
+	return plan
 }
 
 func (m *InstallLibrariesModule) IsError() error {
 	return m.Error
 }
 
-func (m *InstallLibrariesModule) LoadFromConfig(_ *configuration.Configuration, eggl *models.EggLog) {
+func (m *InstallLibrariesModule) LoadFromConfig(configuration *configuration.Configuration, eggl *models.EggLog) {
+	m.configuration = configuration
 	m.eggl = eggl
 	m.Progress = 0
 	m.eggl.Info("Installing libraries")
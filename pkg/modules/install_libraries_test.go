@@ -1,10 +1,16 @@
 package modules
 
 import (
+	"context"
 	"errors"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/adamkali/egg_cli/pkg/configuration"
+	"github.com/adamkali/egg_cli/pkg/targets"
+	"golang.org/x/mod/modfile"
 )
 
 func TestInstallLibrariesModule_Name(t *testing.T) {
@@ -49,41 +55,244 @@ func TestInstallLibrariesModule_IsError(t *testing.T) {
 	}
 }
 
+// writeGoMod writes the go.mod `go mod init` creates into the current directory
+func writeGoMod(t *testing.T) {
+	t.Helper()
+	if err := os.WriteFile("go.mod", []byte("module github.com/testuser/testproject\n\ngo 1.24\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestInstallLibrariesModule_Run_Simulated(t *testing.T) {
-	// Use a test logger and inject GoGetFunc to avoid real installs
+	t.Chdir(t.TempDir())
+	writeGoMod(t)
+	// Use a test logger and inject GoModFunc to avoid real downloads
 	logger := createTestLogger(t)
 	defer logger.Close()
 	m := &InstallLibrariesModule{eggl: logger}
 
-	// Simulate GoGet always succeeds
-	m.GoGetFunc = func(pac string) error {
-		m.IncrProg()
-		return nil
+	var commands []string
+	m.GoModFunc = func(args ...string) ([]byte, error) {
+		commands = append(commands, strings.Join(args, " "))
+		return nil, nil
 	}
 
-	m.Run()
-	if m.IsError() != nil {
-		t.Errorf("Run() set unexpected error: %v", m.IsError())
+	result, err := m.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := []string{"mod download -json", "mod tidy -e", "mod download github.com/swaggo/swag"}; !reflect.DeepEqual(commands, want) {
+		t.Errorf("ran %v, want a single download and tidy %v", commands, want)
+	}
+	if m.GetProgress() != 1 {
+		t.Errorf("GetProgress() = %v, want 1", m.GetProgress())
+	}
+	if want := []string{"require", "go mod download", "go mod tidy"}; !reflect.DeepEqual(result.Checkpoints, want) {
+		t.Errorf("Checkpoints = %v, want %v", result.Checkpoints, want)
+	}
+
+	content, err := os.ReadFile("go.mod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := modfile.Parse("go.mod", content, nil)
+	if err != nil {
+		t.Fatalf("Run() wrote an invalid go.mod: %v", err)
+	}
+	required := map[string]string{}
+	for _, require := range file.Require {
+		required[require.Mod.Path] = require.Mod.Version
+	}
+	for _, module := range targets.GolangModules {
+		if required[module.Path] != module.Version {
+			t.Errorf("go.mod requires %s %q, want %s", module.Path, required[module.Path], module.Version)
+		}
+	}
+}
+
+// TestInstallLibrariesModule_Run_KeepsGeneratedModules checks that the pins of
+// targets.GeneratedModules survive go mod tidy, which drops them as nothing imports them yet
+func TestInstallLibrariesModule_Run_KeepsGeneratedModules(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeGoMod(t)
+	logger := createTestLogger(t)
+	defer logger.Close()
+	m := &InstallLibrariesModule{eggl: logger}
+	m.GoModFunc = func(args ...string) ([]byte, error) {
+		if args[1] == "tidy" {
+			// like go mod tidy before swag init wrote docs/docs.go
+			return nil, os.WriteFile("go.mod", []byte("module github.com/testuser/testproject\n\ngo 1.24\n"), 0644)
+		}
+		return nil, nil
+	}
+	if _, err := m.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	content, err := os.ReadFile("go.mod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, module := range targets.GeneratedModules {
+		if !strings.Contains(string(content), module.Path+" "+module.Version) {
+			t.Errorf("go.mod does not require %s after go mod tidy:\n%s", module, content)
+		}
+	}
+}
+
+func TestInstallLibrariesModule_Run_ReportsFailedModules(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeGoMod(t)
+	logger := createTestLogger(t)
+	defer logger.Close()
+	m := &InstallLibrariesModule{eggl: logger}
+
+	tidied := false
+	m.GoModFunc = func(args ...string) ([]byte, error) {
+		if args[1] == "tidy" {
+			tidied = true
+			return nil, nil
+		}
+		output := `{"Path": "github.com/google/uuid", "Version": "v1.6.0"}
+{"Path": "github.com/labstack/echo/v4", "Version": "v4.13.3", "Error": "unrecognized import path"}
+{"Path": "golang.org/x/crypto", "Version": "v0.38.0", "Error": "connection refused"}
+`
+		return []byte(output), errors.New("exit status 1")
+	}
+
+	_, err := m.Run(context.Background())
+	var downloadErr *ModuleDownloadError
+	if !errors.As(err, &downloadErr) {
+		t.Fatalf("Run() error = %v, want a ModuleDownloadError", err)
+	}
+	want := map[string]string{
+		"github.com/labstack/echo/v4@v4.13.3": "unrecognized import path",
+		"golang.org/x/crypto@v0.38.0":         "connection refused",
+	}
+	if !reflect.DeepEqual(downloadErr.Failed, want) {
+		t.Errorf("Failed = %v, want %v", downloadErr.Failed, want)
+	}
+	if !strings.Contains(err.Error(), "github.com/labstack/echo/v4@v4.13.3: unrecognized import path") {
+		t.Errorf("Error() = %q, want every failed module", err.Error())
+	}
+	if tidied {
+		t.Error("Run() ran go mod tidy after the download failed")
+	}
+}
+
+func TestParseDownloadErrors_ModuleGraph(t *testing.T) {
+	stderr := "exit status 1: go: github.com/minio/minio-go/v7@v7.0.91: module lookup disabled by GOPROXY=off"
+	failed := parseDownloadErrors(nil, stderr)
+	want := map[string]string{"github.com/minio/minio-go/v7@v7.0.91": "module lookup disabled by GOPROXY=off"}
+	if !reflect.DeepEqual(failed.Failed, want) {
+		t.Errorf("Failed = %v, want %v", failed.Failed, want)
 	}
-	if m.Progress == 0 {
-		t.Error("Run() did not increment Progress")
+	if failed := parseDownloadErrors(nil, "exit status 1: go: updates to go.mod needed"); len(failed.Failed) != 0 {
+		t.Errorf("Failed = %v, want no module", failed.Failed)
 	}
 }
 
 func TestInstallLibrariesModule_Run_SimulatedError(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeGoMod(t)
 	logger := createTestLogger(t)
 	defer logger.Close()
 	m := &InstallLibrariesModule{eggl: logger}
 
-	// Simulate GoGet fails on first package
-	m.GoGetFunc = func(pac string) error {
-		m.Error = errors.New("simulated error")
-		return m.Error
+	// Simulate a failure that is not reported per module
+	m.GoModFunc = func(args ...string) ([]byte, error) {
+		return nil, errors.New("simulated error")
 	}
 
-	m.Run()
+	m.Run(context.Background())
 	if m.IsError() == nil {
-		t.Error("Run() did not set error on GoGet failure")
+		t.Error("Run() did not set error on go mod download failure")
 	}
+}
+
+func TestInstallLibrariesModule_Run_WithoutGoMod(t *testing.T) {
+	t.Chdir(t.TempDir())
+	logger := createTestLogger(t)
+	defer logger.Close()
+	m := &InstallLibrariesModule{eggl: logger}
+	m.GoModFunc = func(args ...string) ([]byte, error) {
+		t.Error("Run() ran go mod without a go.mod")
+		return nil, nil
+	}
+	if _, err := m.Run(context.Background()); err == nil {
+		t.Error("Run() error = nil, want the missing go.mod")
+	}
+}
+
+func TestInstallLibrariesModule_Run_SkipsCheckpointedSteps(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeGoMod(t)
+	logger := createTestLogger(t)
+	defer logger.Close()
+	m := &InstallLibrariesModule{eggl: logger}
+	m.Resume([]string{"require", "go mod download"})
+
+	var commands []string
+	m.GoModFunc = func(args ...string) ([]byte, error) {
+		commands = append(commands, strings.Join(args, " "))
+		return nil, nil
+	}
+	if _, err := m.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := []string{"mod tidy -e", "mod download github.com/swaggo/swag"}; !reflect.DeepEqual(commands, want) {
+		t.Errorf("ran %v, want %v", commands, want)
+	}
+}
+
+func TestInstallLibrariesModule_Describe(t *testing.T) {
+	m := &InstallLibrariesModule{}
+	m.GoModFunc = func(args ...string) ([]byte, error) {
+		t.Error("Describe() must not run go mod")
+		return nil, nil
+	}
+	plan := m.Describe()
+	if len(plan.Commands) != 3 {
+		t.Errorf("Describe() returned %d commands, want a download, a tidy and the download of the generated modules", len(plan.Commands))
+	}
+	if len(plan.Notes) != len(targets.GolangModules)+len(targets.GeneratedModules) {
+		t.Errorf("Describe() returned %d notes, want one per module", len(plan.Notes))
+	}
+}
+
+func TestInstallLibrariesModule_Run_Cancelled(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeGoMod(t)
+	logger := createTestLogger(t)
+	defer logger.Close()
+	m := &InstallLibrariesModule{eggl: logger}
 
+	ctx, cancel := context.WithCancel(context.Background())
+	ran := 0
+	m.GoModFunc = func(args ...string) ([]byte, error) {
+		ran++
+		cancel()
+		return nil, nil
+	}
+
+	result, err := m.Run(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+	if ran != 1 {
+		t.Errorf("Run() ran %d go mod commands after cancel, want 1", ran)
+	}
+	if n := len(result.Commands); n != 2 || result.Commands[1].Command != "go mod download -json" {
+		t.Errorf("Run() result commands = %+v, want the require and the download", result.Commands)
+	}
+}
+
+func TestRequireModules_UpdatesExistingRequirements(t *testing.T) {
+	content := []byte("module example.com/app\n\ngo 1.24\n\nrequire github.com/google/uuid v1.3.0\n")
+	rendered, err := RequireModules(content, []targets.Module{{Path: "github.com/google/uuid", Version: "v1.6.0"}})
+	if err != nil {
+		t.Fatalf("RequireModules() error = %v", err)
+	}
+	if strings.Contains(string(rendered), "v1.3.0") || !strings.Contains(string(rendered), "github.com/google/uuid v1.6.0") {
+		t.Errorf("RequireModules() = %s, want uuid pinned to v1.6.0", rendered)
+	}
 }
@@ -1,9 +1,13 @@
 package modules
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/adamkali/egg_cli/pkg/configuration"
 	"github.com/adamkali/egg_cli/pkg/models"
@@ -12,61 +16,135 @@ import (
 )
 
 type InstallToolsModule struct {
-	eggl            *models.EggLog
-	Progress        int
-	Error           error
-	LookPathFunc    func(file string) (string, error) // For testing - can be injected to mock tool lookup
-	InstallToolFunc func(tool string) error           // For testing - can be injected to mock tool installation
+	checkpoints
+	offlineMode
+	projectWorkspace
+	eggl     *models.EggLog
+	Progress int
+	Error    error
+	mu       sync.Mutex
+	// tools are the pinned tools of the running install
+	tools []targets.Tool
+	// replaceTools installs the pinned version of a tool that is outdated outside of GOBIN
+	replaceTools    bool
+	LookPathFunc    func(file string) (string, error)                    // For testing - can be injected to mock tool lookup
+	InstallToolFunc func(tool string) error                              // For testing - can be injected to mock tool installation
+	VersionFunc     func(tool targets.Tool, path string) (string, error) // For testing - can be injected to mock reading the version of a binary
+	GoBinFunc       func(ctx context.Context) (string, error)            // For testing - can be injected to mock the directory go install writes to
 }
 
-func (m *InstallToolsModule) Name() string {
-	return "egg::install_tools"
+// IReplaceTools is implemented by modules that can install tools over the ones on the PATH
+type IReplaceTools interface {
+	SetReplaceTools(replace bool)
+}
+
+func (m *InstallToolsModule) SetReplaceTools(replace bool) {
+	m.replaceTools = replace
 }
 
-var maxprog_tools = float64(len(targets.RequiredTools))
+func (m *InstallToolsModule) Name() string {
+	return InstallToolsModuleID
+}
 
 func (m *InstallToolsModule) IncrProg() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.Progress += 1
 	return
 }
 
 func (m *InstallToolsModule) GetProgress() float64 {
-	return float64(m.Progress) / maxprog_tools
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.tools) == 0 {
+		return float64(m.Progress) / float64(len(targets.RequiredTools))
+	}
+	return float64(m.Progress) / float64(len(m.tools))
 }
 
-func (m *InstallToolsModule) Run() {
+// Run
+//
+// params:
+//
+//	ctx: context.Context
+//
+// returns:
+//
+//	Result: the egg.lock file and every go install that ran
+//	error: if egg.lock could not be read or written, or a tool could not be installed
+//
+// description:
+//
+//	Installs the tools pinned in egg.lock, which is written with the default versions
+//	when the project does not have one yet. Every tool is looked up by its binary name
+//	and a report of the present, missing and outdated tools is printed. A tool on the
+//	PATH with the pinned version (or whose version can not be read) is skipped. A tool
+//	with another version is reported, and the pinned version is installed so that every
+//	teammate generates the same code when the binary is in GOBIN, where go install
+//	replaces it. A binary outside of GOBIN (e.g. from a package manager) is kept unless
+//	the tools are replaced explicitly (see SetReplaceTools), because the pinned one
+//	would be installed next to it.
+func (m *InstallToolsModule) Run(ctx context.Context) (Result, error) {
+	result := newResult(m)
 	installToolsStart := styles.EggProgressInfo.Render("🥚 " + m.Name() + " start")
 	fmt.Println(installToolsStart)
 
-	// install go tools
-	for _, tool := range targets.RequiredTools {
-		toolStr := tool[strings.LastIndex(tool, "/")+1:]
-		toolStr = toolStr[:strings.Index(toolStr, "@")]
+	lockFile := m.projectPath(targets.LockFileName)
+	lock, err := targets.LoadLock(lockFile)
+	if err != nil {
+		m.Error = err
+		m.eggl.Error("error: %s", m.Error.Error())
+		return result, m.Error
+	}
+	result.addUndo(snapshotFile(lockFile))
+	if err := lock.Write(lockFile); err != nil {
+		m.Error = err
+		m.eggl.Error("error: %s", m.Error.Error())
+		return result, m.Error
+	}
+	result.Files = append(result.Files, targets.LockFileName)
+	m.mu.Lock()
+	m.tools = lock.Tools
+	m.mu.Unlock()
 
-		// Use injected function if available, otherwise use real implementation
-		var err error
-		if m.LookPathFunc != nil {
-			_, err = m.LookPathFunc(tool)
-		} else {
-			_, err = exec.LookPath(tool)
-		}
+	statuses := m.Inspect(ctx, lock.Tools)
+	report := RenderToolReport(statuses)
+	m.eggl.Info("%s", report)
+	fmt.Print(styles.EggProgressInfo.Render(report) + "\n")
 
-		if err == nil {
-			installToolInstalledMessage := fmt.Sprintf(
-				"🥚 %s %s is already installed",
-				m.Name(),
-				toolStr,
-			)
-			m.eggl.Info(installToolInstalledMessage)
-			styles.EggProgressInfo.Render(installToolInstalledMessage)
-			fmt.Println(installToolInstalledMessage)
+	// install go tools
+	for _, status := range statuses {
+		tool := status.Tool
+		if err := ctx.Err(); err != nil {
+			m.Error = err
+			return result, m.Error
+		}
+		if m.isCompleted(tool.Target()) {
+			m.eggl.Info(fmt.Sprintf("🥚 %s %s was installed by a previous run", m.Name(), tool.Name))
+			continue
+		}
+		if !status.NeedsInstall() {
+			// a binary without a readable version (e.g. from a package manager) is kept
 			continue
 		}
+		if status.State == ToolOutdated {
+			warning := fmt.Sprintf("🥚 %s warning: %s %s on the PATH (%s) differs from %s pinned in %s",
+				m.Name(), tool.Name, status.Version, status.Path, tool.Version, targets.LockFileName)
+			if !m.replaceTools && !m.inGoBin(ctx, status.Path) {
+				warning += ", it is outside of GOBIN and kept, pass --replace-tools to install the pinned version"
+				m.eggl.Info(warning)
+				fmt.Println(styles.EggProgressError.Render(warning))
+				continue
+			}
+			warning += ", it is replaced"
+			m.eggl.Info(warning)
+			fmt.Println(styles.EggProgressError.Render(warning))
+		}
 
 		installToolsMessage := fmt.Sprintf(
 			"🥚 %s installing %s",
 			m.Name(),
-			tool,
+			tool.Target(),
 		)
 		m.eggl.Info(installToolsMessage)
 		installToolsMessage = styles.EggProgressInfo.Render(installToolsMessage)
@@ -74,23 +152,86 @@ func (m *InstallToolsModule) Run() {
 
 		// Use injected function if available, otherwise use real implementation
 		if m.InstallToolFunc != nil {
-			err = m.InstallToolFunc(tool)
+			err = result.record("go install "+tool.Target(), func() error {
+				return m.InstallToolFunc(tool.Target())
+			})
 		} else {
-			output, err := exec.Command("go", "install", tool).Output()
-			if err != nil {
-				m.Error = err
-				return
+			var output []byte
+			// offline the module cache is the only source
+			output, err = result.runCommandIn(ctx, "", m.offline.Env(), "go", "install", tool.Target())
+			if err == nil {
+				fmt.Println(string(output))
 			}
-			fmt.Println(string(output))
 		}
 
 		if err != nil {
 			m.Error = err
-			return
+			return result, m.Error
 		}
+		result.checkpoint(tool.Target())
 		m.IncrProg()
 		m.Error = nil
 	}
+	return result, nil
+}
+
+// Describe returns egg.lock and the pinned tools that would be installed with go install
+func (m *InstallToolsModule) Describe() Plan {
+	lock, err := targets.LoadLock(m.projectPath(targets.LockFileName))
+	if err != nil {
+		lock = targets.DefaultLock()
+	}
+	plan := Plan{
+		Module: m.Name(),
+		Files:  []string{targets.LockFileName},
+		Notes: []string{
+			"tools already found on the PATH with the version pinned in " + targets.LockFileName + " are skipped",
+			"tools found outside of GOBIN with another version are kept unless --replace-tools is passed",
+		},
+	}
+	for _, tool := range lock.Tools {
+		plan.Tools = append(plan.Tools, tool.Target())
+		plan.Commands = append(plan.Commands, strings.TrimSpace(strings.Join(m.offline.Env(), " ")+" go install "+tool.Target()))
+	}
+	return plan
+}
+
+// inGoBin reports whether the binary is in GOBIN, where go install would replace it
+func (m *InstallToolsModule) inGoBin(ctx context.Context, path string) bool {
+	goBin, err := m.goBin(ctx)
+	if err != nil {
+		m.eggl.Error("error: failed to find GOBIN: %s", err.Error())
+		return false
+	}
+	dir := filepath.Dir(path)
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(goBin); err == nil {
+		goBin = resolved
+	}
+	return filepath.Clean(dir) == filepath.Clean(goBin)
+}
+
+// goBin returns the directory go install writes to, GOBIN or the bin directory of the first GOPATH
+func (m *InstallToolsModule) goBin(ctx context.Context) (string, error) {
+	// Use injected function if available, otherwise use real implementation
+	if m.GoBinFunc != nil {
+		return m.GoBinFunc(ctx)
+	}
+	output, err := exec.CommandContext(ctx, "go", "env", "GOBIN", "GOPATH").Output()
+	if err != nil {
+		return "", err
+	}
+	// an empty GOBIN is an empty first line
+	lines := strings.Split(string(output), "\n")
+	if goBin := strings.TrimSpace(lines[0]); goBin != "" {
+		return goBin, nil
+	}
+	if len(lines) < 2 || strings.TrimSpace(lines[1]) == "" {
+		return "", errors.New("GOBIN and GOPATH are empty")
+	}
+	return filepath.Join(filepath.SplitList(strings.TrimSpace(lines[1]))[0], "bin"), nil
 }
 
 func (m *InstallToolsModule) IsError() error {
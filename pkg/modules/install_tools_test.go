@@ -1,10 +1,15 @@
 package modules
 
 import (
+	"context"
 	"errors"
+	"path/filepath"
+	"reflect"
+	"slices"
 	"testing"
 
 	"github.com/adamkali/egg_cli/pkg/configuration"
+	"github.com/adamkali/egg_cli/pkg/targets"
 )
 
 func TestInstallToolsModule_Name(t *testing.T) {
@@ -50,6 +55,7 @@ func TestInstallToolsModule_IsError(t *testing.T) {
 }
 
 func TestInstallToolsModule_Run_AllToolsAlreadyInstalled(t *testing.T) {
+	t.Chdir(t.TempDir())
 	logger := createTestLogger(t)
 	defer logger.Close()
 	m := &InstallToolsModule{eggl: logger}
@@ -59,7 +65,7 @@ func TestInstallToolsModule_Run_AllToolsAlreadyInstalled(t *testing.T) {
 		return "/usr/local/bin/" + file, nil // Simulate tool found
 	}
 
-	m.Run()
+	m.Run(context.Background())
 	if m.IsError() != nil {
 		t.Errorf("Run() set unexpected error: %v", m.IsError())
 	}
@@ -70,6 +76,7 @@ func TestInstallToolsModule_Run_AllToolsAlreadyInstalled(t *testing.T) {
 }
 
 func TestInstallToolsModule_Run_InstallSomeTools(t *testing.T) {
+	t.Chdir(t.TempDir())
 	logger := createTestLogger(t)
 	defer logger.Close()
 	m := &InstallToolsModule{eggl: logger}
@@ -89,7 +96,7 @@ func TestInstallToolsModule_Run_InstallSomeTools(t *testing.T) {
 		return nil
 	}
 
-	m.Run()
+	m.Run(context.Background())
 	if m.IsError() != nil {
 		t.Errorf("Run() set unexpected error: %v", m.IsError())
 	}
@@ -100,6 +107,7 @@ func TestInstallToolsModule_Run_InstallSomeTools(t *testing.T) {
 }
 
 func TestInstallToolsModule_Run_InstallError(t *testing.T) {
+	t.Chdir(t.TempDir())
 	logger := createTestLogger(t)
 	defer logger.Close()
 	m := &InstallToolsModule{eggl: logger}
@@ -114,13 +122,14 @@ func TestInstallToolsModule_Run_InstallError(t *testing.T) {
 		return errors.New("installation failed")
 	}
 
-	m.Run()
+	m.Run(context.Background())
 	if m.IsError() == nil {
 		t.Error("Run() did not set error on installation failure")
 	}
 }
 
 func TestInstallToolsModule_Run_MixedScenario(t *testing.T) {
+	t.Chdir(t.TempDir())
 	logger := createTestLogger(t)
 	defer logger.Close()
 	m := &InstallToolsModule{eggl: logger}
@@ -142,7 +151,7 @@ func TestInstallToolsModule_Run_MixedScenario(t *testing.T) {
 		return nil
 	}
 
-	m.Run()
+	m.Run(context.Background())
 	if m.IsError() != nil {
 		t.Errorf("Run() set unexpected error: %v", m.IsError())
 	}
@@ -151,3 +160,177 @@ func TestInstallToolsModule_Run_MixedScenario(t *testing.T) {
 		t.Errorf("Progress should be incremented for installed tools, got %d", m.Progress)
 	}
 }
+
+func TestInstallToolsModule_Describe(t *testing.T) {
+	m := &InstallToolsModule{}
+	m.LookPathFunc = func(file string) (string, error) {
+		t.Error("Describe() must not look up tools")
+		return "", nil
+	}
+	plan := m.Describe()
+	if len(plan.Tools) != len(targets.RequiredTools) {
+		t.Errorf("Describe() Tools = %v, want %v", plan.Tools, targets.RequiredTools)
+	}
+	if len(plan.Commands) != len(targets.RequiredTools) {
+		t.Errorf("Describe() returned %d commands, want %d", len(plan.Commands), len(targets.RequiredTools))
+	}
+}
+
+// toolTargets returns the go install targets of tools
+func toolTargets(tools []targets.Tool) []string {
+	installs := make([]string, len(tools))
+	for i, tool := range tools {
+		installs[i] = tool.Target()
+	}
+	return installs
+}
+
+func TestInstallToolsModule_Run_WritesLockAndInstallsPinnedVersions(t *testing.T) {
+	t.Chdir(t.TempDir())
+	logger := createTestLogger(t)
+	defer logger.Close()
+	m := &InstallToolsModule{eggl: logger}
+	m.LookPathFunc = func(string) (string, error) { return "", errors.New("not found") }
+	var installed []string
+	m.InstallToolFunc = func(tool string) error {
+		installed = append(installed, tool)
+		return nil
+	}
+
+	result, err := m.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := toolTargets(targets.RequiredTools); !reflect.DeepEqual(installed, want) {
+		t.Errorf("installed %v, want %v", installed, want)
+	}
+	lock, err := targets.LoadLock(targets.LockFileName)
+	if err != nil {
+		t.Fatalf("LoadLock() error = %v", err)
+	}
+	if !reflect.DeepEqual(lock.Tools, targets.RequiredTools) {
+		t.Errorf("egg.lock tools = %v, want %v", lock.Tools, targets.RequiredTools)
+	}
+	if !slices.Contains(result.Files, targets.LockFileName) {
+		t.Errorf("Files = %v, want %s", result.Files, targets.LockFileName)
+	}
+}
+
+func TestInstallToolsModule_Run_HonorsExistingLock(t *testing.T) {
+	t.Chdir(t.TempDir())
+	logger := createTestLogger(t)
+	defer logger.Close()
+	lock := targets.DefaultLock()
+	lock.Tools[0].Version = "v1.0.0"
+	if err := lock.Write(targets.LockFileName); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &InstallToolsModule{eggl: logger}
+	m.LookPathFunc = func(string) (string, error) { return "", errors.New("not found") }
+	var installed []string
+	m.InstallToolFunc = func(tool string) error {
+		installed = append(installed, tool)
+		return nil
+	}
+	if _, err := m.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := lock.Tools[0].Package + "@v1.0.0"; installed[0] != want {
+		t.Errorf("installed %s, want %s", installed[0], want)
+	}
+}
+
+func TestInstallToolsModule_Run_ReinstallsMismatchedVersions(t *testing.T) {
+	t.Chdir(t.TempDir())
+	logger := createTestLogger(t)
+	defer logger.Close()
+	m := &InstallToolsModule{eggl: logger}
+	m.LookPathFunc = func(file string) (string, error) { return "/usr/local/bin/" + file, nil }
+	// go install replaces the binaries in GOBIN
+	m.GoBinFunc = func(context.Context) (string, error) { return "/usr/local/bin", nil }
+	outdated := targets.RequiredTools[0]
+	m.VersionFunc = func(_ targets.Tool, path string) (string, error) {
+		if path == "/usr/local/bin/"+outdated.Name {
+			return "v0.0.1", nil
+		}
+		for _, tool := range targets.RequiredTools {
+			if path == "/usr/local/bin/"+tool.Name {
+				return tool.Version, nil
+			}
+		}
+		return "", errors.New("unknown binary")
+	}
+	var installed []string
+	m.InstallToolFunc = func(tool string) error {
+		installed = append(installed, tool)
+		return nil
+	}
+
+	if _, err := m.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := []string{outdated.Target()}; !reflect.DeepEqual(installed, want) {
+		t.Errorf("installed %v, want %v", installed, want)
+	}
+}
+
+func TestInstallToolsModule_Run_KeepsOutdatedOutsideGoBin(t *testing.T) {
+	outdated := targets.RequiredTools[0]
+	tests := []struct {
+		name    string
+		replace bool
+		want    []string
+	}{
+		{"kept", false, nil},
+		{"replaced with --replace-tools", true, []string{outdated.Target()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+			logger := createTestLogger(t)
+			defer logger.Close()
+			m := &InstallToolsModule{eggl: logger}
+			m.SetReplaceTools(tt.replace)
+			// installed by a package manager, not by go install
+			m.LookPathFunc = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+			m.GoBinFunc = func(context.Context) (string, error) { return "/home/egg/go/bin", nil }
+			m.VersionFunc = func(tool targets.Tool, _ string) (string, error) {
+				if tool.Name == outdated.Name {
+					return "v0.0.1", nil
+				}
+				return tool.Version, nil
+			}
+			var installed []string
+			m.InstallToolFunc = func(tool string) error {
+				installed = append(installed, tool)
+				return nil
+			}
+
+			if _, err := m.Run(context.Background()); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if !reflect.DeepEqual(installed, tt.want) {
+				t.Errorf("installed %v, want %v", installed, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstallToolsModule_Describe_ProjectLock(t *testing.T) {
+	dir := t.TempDir()
+	lock := targets.DefaultLock()
+	lock.Tools[0].Version = "v1.0.0"
+	if err := lock.Write(filepath.Join(dir, targets.LockFileName)); err != nil {
+		t.Fatal(err)
+	}
+	// the working directory has no egg.lock, the project directory does
+	t.Chdir(t.TempDir())
+
+	m := &InstallToolsModule{}
+	m.SetWorkspace(Workspace{Dir: dir})
+	plan := m.Describe()
+	if want := lock.Tools[0].Package + "@v1.0.0"; plan.Tools[0] != want {
+		t.Errorf("Describe() Tools[0] = %s, want %s", plan.Tools[0], want)
+	}
+}
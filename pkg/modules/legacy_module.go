@@ -0,0 +1,73 @@
+package modules
+
+import (
+	"context"
+
+	"github.com/adamkali/egg_cli/pkg/configuration"
+	"github.com/adamkali/egg_cli/pkg/models"
+)
+
+// ILegacyModule is the original module interface, where Run stores its error
+// on the module to be read back with IsError. Modules written against it can
+// still be used in the pipeline by wrapping them with FromLegacy.
+type ILegacyModule interface {
+	Run()
+	Name() string
+	IsError() error
+	LoadFromConfig(configuration *configuration.Configuration, eggl *models.EggLog)
+}
+
+// legacyModule adapts an ILegacyModule to IModule
+type legacyModule struct {
+	module ILegacyModule
+}
+
+// FromLegacy
+//
+// params:
+//
+//	module: ILegacyModule
+//
+// returns:
+//
+//	IModule: the module adapted to the context aware interface
+//
+// description:
+//
+//	A legacy module cannot be interrupted, so the context is only checked before
+//	it starts. Its Result is empty because legacy modules do not report what
+//	they created. If the module also has a Describe() Plan method it
+//	is used for dry runs.
+func FromLegacy(module ILegacyModule) IModule {
+	return &legacyModule{module: module}
+}
+
+func (m *legacyModule) Name() string { return m.module.Name() }
+
+func (m *legacyModule) LoadFromConfig(configuration *configuration.Configuration, eggl *models.EggLog) {
+	m.module.LoadFromConfig(configuration, eggl)
+}
+
+func (m *legacyModule) Describe() Plan {
+	if describer, ok := m.module.(interface{ Describe() Plan }); ok {
+		return describer.Describe()
+	}
+	return Plan{Module: m.Name(), Notes: []string{"this module does not describe its plan"}}
+}
+
+func (m *legacyModule) Run(ctx context.Context) (Result, error) {
+	result := newResult(m)
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+	m.module.Run()
+	return result, m.module.IsError()
+}
+
+// GetProgress forwards the progress of modules that report it
+func (m *legacyModule) GetProgress() float64 {
+	if progress, ok := m.module.(IProgress); ok {
+		return progress.GetProgress()
+	}
+	return 0
+}
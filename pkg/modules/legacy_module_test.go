@@ -0,0 +1,67 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/adamkali/egg_cli/pkg/configuration"
+	"github.com/adamkali/egg_cli/pkg/models"
+)
+
+type fakeLegacyModule struct {
+	ran    bool
+	loaded bool
+	err    error
+}
+
+func (m *fakeLegacyModule) Run()           { m.ran = true }
+func (m *fakeLegacyModule) Name() string   { return "egg::fake_legacy" }
+func (m *fakeLegacyModule) IsError() error { return m.err }
+func (m *fakeLegacyModule) LoadFromConfig(_ *configuration.Configuration, _ *models.EggLog) {
+	m.loaded = true
+}
+
+func TestFromLegacy_Run(t *testing.T) {
+	legacy := &fakeLegacyModule{}
+	m := FromLegacy(legacy)
+	m.LoadFromConfig(createTestConfiguration(), nil)
+
+	result, err := m.Run(context.Background())
+	if err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+	if !legacy.loaded || !legacy.ran {
+		t.Error("FromLegacy did not forward LoadFromConfig and Run")
+	}
+	if result.Module != legacy.Name() || m.Name() != legacy.Name() {
+		t.Errorf("FromLegacy did not keep the module name, got %q", result.Module)
+	}
+}
+
+func TestFromLegacy_Run_Error(t *testing.T) {
+	legacy := &fakeLegacyModule{err: errors.New("fail")}
+	if _, err := FromLegacy(legacy).Run(context.Background()); err == nil {
+		t.Error("Run() did not return the IsError() of the legacy module")
+	}
+}
+
+func TestFromLegacy_Run_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	legacy := &fakeLegacyModule{}
+	if _, err := FromLegacy(legacy).Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+	if legacy.ran {
+		t.Error("Run() started a legacy module after the context was cancelled")
+	}
+}
+
+func TestFromLegacy_Describe(t *testing.T) {
+	plan := FromLegacy(&fakeLegacyModule{}).Describe()
+	if plan.Module != "egg::fake_legacy" || len(plan.Notes) == 0 {
+		t.Errorf("Describe() = %+v, want a note for a module without a plan", plan)
+	}
+}
@@ -0,0 +1,112 @@
+package modules
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// ManifestFile lists every file egg_cli generated in the project, relative to the project
+const ManifestFile = ".egg/manifest.json"
+
+// ManifestEntry is a single generated file
+type ManifestEntry struct {
+	// Path is the output path of the file, relative to the project
+	Path string `json:"path"`
+	// Template is the name of the template the file was rendered from
+	Template string `json:"template"`
+	// EggVersion is the release of the templates, see templates.Version
+	EggVersion string `json:"egg_version"`
+	// SHA256 is the checksum of the rendered content, hex encoded
+	SHA256 string `json:"sha256"`
+}
+
+// Manifest
+//
+// description:
+//
+//	Manifest records which files egg_cli generated, from which template and release,
+//	and the checksum of what was rendered. A file whose checksum still matches was not
+//	touched since it was generated, so upgrades and other commands can replace it
+//	without losing edits.
+type Manifest struct {
+	Files []ManifestEntry `json:"files"`
+}
+
+// Checksum returns the hex encoded SHA-256 of content, as recorded in the manifest
+func Checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadManifest reads the manifest of the project in dir, a project without one has an empty manifest
+func LoadManifest(dir string) (*Manifest, error) {
+	manifest := new(Manifest)
+	content, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ManifestFile, err)
+	}
+	return manifest, nil
+}
+
+// Save writes the manifest into the project in dir, sorted by path
+func (m *Manifest) Save(dir string) error {
+	sort.Slice(m.Files, func(i, j int) bool { return m.Files[i].Path < m.Files[j].Path })
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	manifestFile := filepath.Join(dir, ManifestFile)
+	if err := os.MkdirAll(filepath.Dir(manifestFile), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(manifestFile, append(content, '\n'), 0666)
+}
+
+// Set records entry, replacing the entry of the same path
+func (m *Manifest) Set(entry ManifestEntry) {
+	entry.Path = path.Clean(entry.Path)
+	for i := range m.Files {
+		if m.Files[i].Path == entry.Path {
+			m.Files[i] = entry
+			return
+		}
+	}
+	m.Files = append(m.Files, entry)
+}
+
+// Lookup returns the entry of the output path name
+func (m *Manifest) Lookup(name string) (ManifestEntry, bool) {
+	name = path.Clean(name)
+	for _, entry := range m.Files {
+		if entry.Path == name {
+			return entry, true
+		}
+	}
+	return ManifestEntry{}, false
+}
+
+// Modified reports whether the file of the entry in the project in dir was changed
+// since it was generated, a file that was removed counts as modified
+func (e ManifestEntry) Modified(dir string) (bool, error) {
+	content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(e.Path)))
+	if errors.Is(err, os.ErrNotExist) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return Checksum(content) != e.SHA256, nil
+}
@@ -0,0 +1,68 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManifest_SaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	empty, err := LoadManifest(dir)
+	if err != nil {
+		t.Fatalf("LoadManifest() error = %v without a manifest", err)
+	}
+	if len(empty.Files) != 0 {
+		t.Errorf("LoadManifest() = %v without a manifest, want it empty", empty.Files)
+	}
+
+	manifest := &Manifest{}
+	manifest.Set(ManifestEntry{Path: "./main.go", Template: "main.go", EggVersion: "v0.0.1", SHA256: Checksum([]byte("old"))})
+	manifest.Set(ManifestEntry{Path: "Makefile", Template: "Makefile", EggVersion: "v0.0.1", SHA256: Checksum([]byte("make"))})
+	manifest.Set(ManifestEntry{Path: "main.go", Template: "main.go", EggVersion: "v0.0.2", SHA256: Checksum([]byte("new"))})
+	if len(manifest.Files) != 2 {
+		t.Fatalf("Set() did not replace the entry of the same path: %v", manifest.Files)
+	}
+	if err := manifest.Save(dir); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadManifest(dir)
+	if err != nil {
+		t.Fatalf("LoadManifest() error = %v", err)
+	}
+	if len(loaded.Files) != 2 || loaded.Files[0].Path != "Makefile" {
+		t.Errorf("LoadManifest() = %v, want 2 entries sorted by path", loaded.Files)
+	}
+	entry, ok := loaded.Lookup("./main.go")
+	if !ok || entry.EggVersion != "v0.0.2" || entry.SHA256 != Checksum([]byte("new")) {
+		t.Errorf("Lookup(main.go) = %+v, %v", entry, ok)
+	}
+}
+
+func TestManifestEntry_Modified(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		entry ManifestEntry
+		want  bool
+	}{
+		{name: "untouched", entry: ManifestEntry{Path: "main.go", SHA256: Checksum([]byte("package main\n"))}, want: false},
+		{name: "edited", entry: ManifestEntry{Path: "main.go", SHA256: Checksum([]byte("package app\n"))}, want: true},
+		{name: "removed", entry: ManifestEntry{Path: "gone.go", SHA256: Checksum([]byte("package main\n"))}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.entry.Modified(dir)
+			if err != nil {
+				t.Fatalf("Modified() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Modified() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package modules
+
+import (
+	"slices"
+	"strings"
+)
+
+// the labels of the conflict markers written by MergeThreeWay
+const (
+	conflictOurs   = "<<<<<<< yours"
+	conflictBase   = "||||||| baseline"
+	conflictSplit  = "======="
+	conflictTheirs = ">>>>>>> egg_cli"
+)
+
+// MergeThreeWay
+//
+// params:
+//
+//	base: string
+//	ours: string
+//	theirs: string
+//
+// returns:
+//
+//	string: the merged content, with conflict markers around every conflicting change
+//	int: the number of conflicts
+//
+// description:
+//
+//	Merges the changes from base to ours and from base to theirs line by line, like
+//	diff3. The lines of base that both sides kept split the files into chunks, a chunk
+//	that only one side changed takes that change and a chunk that both sides changed
+//	in the same way is taken once. Any other chunk is a conflict and is written with
+//	the yours, baseline and egg_cli versions between git style markers.
+func MergeThreeWay(base, ours, theirs string) (string, int) {
+	baseLines, ourLines, theirLines := splitLines(base), splitLines(ours), splitLines(theirs)
+	ourMatches := matchLines(diffLines(baseLines, ourLines), len(baseLines))
+	theirMatches := matchLines(diffLines(baseLines, theirLines), len(baseLines))
+
+	var merged []string
+	conflicts := 0
+	b, o, t := 0, 0, 0
+	for {
+		// the next line of base that is kept by both sides
+		i := b
+		for i < len(baseLines) && (ourMatches[i] < 0 || theirMatches[i] < 0) {
+			i++
+		}
+		oEnd, tEnd := len(ourLines), len(theirLines)
+		if i < len(baseLines) {
+			oEnd, tEnd = ourMatches[i], theirMatches[i]
+		}
+
+		baseChunk, ourChunk, theirChunk := baseLines[b:i], ourLines[o:oEnd], theirLines[t:tEnd]
+		switch {
+		case slices.Equal(ourChunk, baseChunk):
+			merged = append(merged, theirChunk...)
+		case slices.Equal(theirChunk, baseChunk), slices.Equal(ourChunk, theirChunk):
+			merged = append(merged, ourChunk...)
+		default:
+			conflicts++
+			merged = append(merged, conflictOurs)
+			merged = append(merged, ourChunk...)
+			merged = append(merged, conflictBase)
+			merged = append(merged, baseChunk...)
+			merged = append(merged, conflictSplit)
+			merged = append(merged, theirChunk...)
+			merged = append(merged, conflictTheirs)
+		}
+
+		if i == len(baseLines) {
+			break
+		}
+		merged = append(merged, baseLines[i])
+		b, o, t = i+1, oEnd+1, tEnd+1
+	}
+	if len(merged) == 0 {
+		return "", conflicts
+	}
+	return strings.Join(merged, "\n") + "\n", conflicts
+}
+
+// matchLines returns the line of the other side every line of a was kept as, or -1 if it was removed
+func matchLines(ops []diffOp, n int) []int {
+	matches := make([]int, n)
+	i, j := 0, 0
+	for _, op := range ops {
+		switch op.kind {
+		case ' ':
+			matches[i] = j
+			i++
+			j++
+		case '-':
+			matches[i] = -1
+			i++
+		default:
+			j++
+		}
+	}
+	return matches
+}
@@ -0,0 +1,59 @@
+package modules
+
+import "testing"
+
+func TestMergeThreeWay(t *testing.T) {
+	base := "package main\n\nfunc a() {}\n\nfunc b() {}\n\nfunc c() {}\n"
+	tests := []struct {
+		name          string
+		ours          string
+		theirs        string
+		want          string
+		wantConflicts int
+	}{
+		{
+			name:   "only the template changed",
+			ours:   base,
+			theirs: "package main\n\nfunc a() { fixed() }\n\nfunc b() {}\n\nfunc c() {}\n",
+			want:   "package main\n\nfunc a() { fixed() }\n\nfunc b() {}\n\nfunc c() {}\n",
+		},
+		{
+			name:   "only the project changed",
+			ours:   "package main\n\nfunc a() {}\n\nfunc b() { mine() }\n\nfunc c() {}\n",
+			theirs: base,
+			want:   "package main\n\nfunc a() {}\n\nfunc b() { mine() }\n\nfunc c() {}\n",
+		},
+		{
+			name:   "separate changes",
+			ours:   "package main\n\nfunc a() {}\n\nfunc b() { mine() }\n\nfunc c() {}\n",
+			theirs: "package main\n\nfunc a() { fixed() }\n\nfunc b() {}\n\nfunc c() {}\n\nfunc d() {}\n",
+			want:   "package main\n\nfunc a() { fixed() }\n\nfunc b() { mine() }\n\nfunc c() {}\n\nfunc d() {}\n",
+		},
+		{
+			name:   "the same change",
+			ours:   "package main\n\nfunc a() { fixed() }\n\nfunc b() {}\n\nfunc c() {}\n",
+			theirs: "package main\n\nfunc a() { fixed() }\n\nfunc b() {}\n\nfunc c() {}\n",
+			want:   "package main\n\nfunc a() { fixed() }\n\nfunc b() {}\n\nfunc c() {}\n",
+		},
+		{
+			name:   "conflict",
+			ours:   "package main\n\nfunc a() { mine() }\n\nfunc b() {}\n\nfunc c() {}\n",
+			theirs: "package main\n\nfunc a() { fixed() }\n\nfunc b() {}\n\nfunc c() {}\n",
+			want: "package main\n\n" +
+				"<<<<<<< yours\nfunc a() { mine() }\n||||||| baseline\nfunc a() {}\n=======\nfunc a() { fixed() }\n>>>>>>> egg_cli\n" +
+				"\nfunc b() {}\n\nfunc c() {}\n",
+			wantConflicts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := MergeThreeWay(base, tt.ours, tt.theirs)
+			if got != tt.want {
+				t.Errorf("MergeThreeWay() = %q, want %q", got, tt.want)
+			}
+			if conflicts != tt.wantConflicts {
+				t.Errorf("MergeThreeWay() conflicts = %d, want %d", conflicts, tt.wantConflicts)
+			}
+		})
+	}
+}
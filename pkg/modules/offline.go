@@ -0,0 +1,377 @@
+package modules
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/adamkali/egg_cli/pkg/targets"
+	"golang.org/x/mod/module"
+)
+
+// VendorModulesFile lists the modules of a vendor directory, written by `go mod vendor`
+const VendorModulesFile = "modules.txt"
+
+// Offline
+//
+// description:
+//
+//	Offline describes a run without network access. The go tools and libraries are
+//	taken from the local module cache (GOPROXY=off), or the libraries from a vendor
+//	directory made with `go mod vendor` in another egg project, and the frontend is
+//	not created. The zero value is a normal run with network access.
+type Offline struct {
+	Enabled bool
+	// VendorDir is copied into the project as its vendor directory, empty uses the module cache
+	VendorDir string
+	// ModCacheFunc returns the module cache, for testing - defaults to `go env GOMODCACHE`
+	ModCacheFunc func(ctx context.Context) (string, error)
+	// GoFunc runs go in dir with env added to the environment, for testing - defaults to exec
+	GoFunc func(ctx context.Context, dir string, env []string, args ...string) ([]byte, error)
+}
+
+// Env returns the environment of the go commands of an offline run
+func (o Offline) Env() []string {
+	if !o.Enabled {
+		return nil
+	}
+	return []string{"GOPROXY=off"}
+}
+
+// IOffline is implemented by modules that work differently without network access
+type IOffline interface {
+	SetOffline(offline Offline)
+}
+
+// offlineMode is embedded by the modules that implement IOffline
+type offlineMode struct {
+	offline Offline
+}
+
+func (o *offlineMode) SetOffline(offline Offline) {
+	o.offline = offline
+}
+
+// OfflineError lists everything an offline run needs that is not available locally
+type OfflineError struct {
+	// Source is the module cache or the vendor directory that was checked
+	Source  string
+	Missing []string
+}
+
+func (e *OfflineError) Error() string {
+	return fmt.Sprintf("%d modules are not available in %s:\n  %s", len(e.Missing), e.Source, strings.Join(e.Missing, "\n  "))
+}
+
+// CheckOffline
+//
+// params:
+//
+//	ctx: context.Context
+//	offline: Offline
+//	required: []targets.Module
+//	imports: []string
+//	tools: []ToolStatus
+//
+// returns:
+//
+//	error:
+//	  - an OfflineError listing every library and tool that is not available locally
+//	  - if the module cache could not be found or the vendor directory could not be read
+//
+// description:
+//
+//	Verifies before anything is created that an offline run has every required module
+//	(see RequiredModules) and every tool that still has to be
+//	installed. Libraries have to be in the vendor directory when one is used and in
+//	the module cache otherwise, tools are always installed from the module cache.
+//	Once the pinned modules are cached, the modules they depend on are resolved
+//	from the module cache as well (see resolveGraph): the module graph of go.mod,
+//	the packages the templates import (imports, see templates.Imports) and the
+//	packages of every tool, so that a missing dependency is reported up front
+//	instead of failing `go mod download` or `go install` half way.
+func CheckOffline(ctx context.Context, offline Offline, required []targets.Module, imports []string, tools []ToolStatus) error {
+	cache, err := offline.modCache(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to find the module cache: %w", err)
+	}
+
+	var missing []string
+	source := cache
+	if offline.VendorDir != "" {
+		vendored, err := ReadVendorModules(offline.VendorDir)
+		if err != nil {
+			return err
+		}
+		for _, module := range required {
+			if _, ok := vendored[module.Path]; !ok {
+				missing = append(missing, module.Path)
+			}
+		}
+		source = offline.VendorDir + " and " + cache
+	} else {
+		for _, module := range required {
+			if !inModCache(cache, module.Path, module.Version) {
+				missing = append(missing, module.String())
+			}
+		}
+	}
+	var installs []ToolStatus
+	for _, status := range tools {
+		if !status.NeedsInstall() {
+			continue
+		}
+		if !inModCache(cache, status.Tool.ModulePath(), status.Tool.Version) {
+			missing = append(missing, status.Tool.ModulePath()+"@"+status.Tool.Version+" (tool "+status.Tool.Name+")")
+			continue
+		}
+		installs = append(installs, status)
+	}
+	if len(missing) > 0 {
+		return &OfflineError{Source: source, Missing: missing}
+	}
+
+	// the pinned modules are there, so go can tell which of their dependencies are not
+	if offline.VendorDir == "" && len(required) > 0 {
+		unresolved, err := offline.resolveGraph(ctx, cache, required, imports)
+		if err != nil {
+			return err
+		}
+		missing = append(missing, unresolved...)
+	}
+	for _, status := range installs {
+		tool := status.Tool
+		unresolved, err := offline.resolveGraph(ctx, cache, []targets.Module{{Path: tool.ModulePath(), Version: tool.Version}}, []string{tool.Package})
+		if err != nil {
+			return err
+		}
+		for _, problem := range unresolved {
+			missing = append(missing, problem+" (tool "+tool.Name+")")
+		}
+	}
+	if len(missing) > 0 {
+		return &OfflineError{Source: source, Missing: missing}
+	}
+	return nil
+}
+
+// listedPackage is a single object of the `go list -e -json` output
+type listedPackage struct {
+	ImportPath string
+	Error      *struct {
+		Err string
+	}
+}
+
+// resolveGraph
+//
+// params:
+//
+//	ctx: context.Context
+//	cache: string, the module cache
+//	required: []targets.Module
+//	packages: []string
+//
+// returns:
+//
+//	[]string: every module or package that could not be loaded from the module cache
+//	error:
+//	  - if the scratch module could not be written
+//
+// description:
+//
+//	Writes a scratch module that requires the modules and runs the go commands of the
+//	run against the module cache with GOPROXY=off: `go mod download -json`, which has
+//	to load the whole module graph, and `go list -e -deps` of the packages, which has
+//	to load every package they are built from.
+func (o Offline) resolveGraph(ctx context.Context, cache string, required []targets.Module, packages []string) ([]string, error) {
+	dir, err := os.MkdirTemp("", "egg-offline-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the scratch module: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	goMod, err := RequireModules([]byte(fmt.Sprintf("module egg.offline/check\n\ngo %d.%d\n", MinimumGoMajor, MinimumGoMinor)), required)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), goMod, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write the scratch module: %w", err)
+	}
+	env := []string{"GOPROXY=off", "GOFLAGS=-mod=mod", "GOWORK=off", "GOMODCACHE=" + cache}
+
+	output, err := o.goCommand(ctx, dir, env, "mod", "download", "-json")
+	if err != nil {
+		failed := parseDownloadErrors(output, err.Error())
+		if len(failed.Failed) == 0 {
+			return []string{"go mod download: " + err.Error()}, nil
+		}
+		var missing []string
+		for module, reason := range failed.Failed {
+			missing = append(missing, module+": "+reason)
+		}
+		sort.Strings(missing)
+		// the packages can not be loaded without the module graph
+		return missing, nil
+	}
+	if len(packages) == 0 {
+		return nil, nil
+	}
+
+	args := append([]string{"list", "-e", "-deps", "-json=ImportPath,Error"}, packages...)
+	output, err = o.goCommand(ctx, dir, env, args...)
+	if err != nil {
+		return []string{"go list: " + err.Error()}, nil
+	}
+	var failed []listedPackage
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for {
+		var listed listedPackage
+		if err := decoder.Decode(&listed); err != nil {
+			break
+		}
+		if listed.Error != nil {
+			failed = append(failed, listed)
+		}
+	}
+	if len(failed) == 0 {
+		return nil, nil
+	}
+
+	// go only names the package, the module graph tells which module provides it
+	output, err = o.goCommand(ctx, dir, env, "list", "-m", "-json=Path,Version", "all")
+	if err != nil {
+		return []string{"go list -m: " + err.Error()}, nil
+	}
+	var graph []targets.Module
+	decoder = json.NewDecoder(bytes.NewReader(output))
+	for {
+		var module targets.Module
+		if err := decoder.Decode(&module); err != nil {
+			break
+		}
+		graph = append(graph, module)
+	}
+	seen := make(map[string]bool)
+	var missing []string
+	for _, listed := range failed {
+		name := listed.ImportPath
+		if module, ok := providingModule(listed.ImportPath, graph); ok {
+			name = module.String()
+		}
+		if !seen[name] {
+			seen[name] = true
+			missing = append(missing, name+": "+listed.Error.Err)
+		}
+	}
+	return missing, nil
+}
+
+// providingModule returns the module of the graph with the longest path that contains the package
+func providingModule(importPath string, graph []targets.Module) (targets.Module, bool) {
+	var found targets.Module
+	for _, module := range graph {
+		if (importPath == module.Path || strings.HasPrefix(importPath, module.Path+"/")) && len(module.Path) > len(found.Path) {
+			found = module
+		}
+	}
+	return found, found.Path != ""
+}
+
+func (o Offline) goCommand(ctx context.Context, dir string, env []string, args ...string) ([]byte, error) {
+	// Use injected function if available, otherwise use real implementation
+	if o.GoFunc != nil {
+		return o.GoFunc(ctx, dir, env, args...)
+	}
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	output, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		// go explains what went wrong on stderr
+		err = fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return output, err
+}
+
+func (o Offline) modCache(ctx context.Context) (string, error) {
+	// Use injected function if available, otherwise use real implementation
+	if o.ModCacheFunc != nil {
+		return o.ModCacheFunc(ctx)
+	}
+	output, err := exec.CommandContext(ctx, "go", "env", "GOMODCACHE").Output()
+	if err != nil {
+		return "", err
+	}
+	cache := strings.TrimSpace(string(output))
+	if cache == "" {
+		return "", errors.New("GOMODCACHE is empty")
+	}
+	return cache, nil
+}
+
+// inModCache reports whether the module was downloaded into the module cache
+func inModCache(cache, path, version string) bool {
+	escapedPath, err := module.EscapePath(path)
+	if err != nil {
+		return false
+	}
+	escapedVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(cache, "cache", "download", escapedPath, "@v", escapedVersion+".zip"))
+	return err == nil
+}
+
+// ReadVendorModules returns the version of every module listed in modules.txt of a vendor
+// directory, and whether go.mod has to require it explicitly
+func ReadVendorModules(dir string) (map[string]VendorModule, error) {
+	file, err := os.Open(filepath.Join(dir, VendorModulesFile))
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a vendor directory made by go mod vendor: %w", dir, err)
+	}
+	defer file.Close()
+
+	vendored := make(map[string]VendorModule)
+	var current string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "# "):
+			// # path version, or # path version => replacement
+			fields := strings.Fields(line)
+			if len(fields) < 3 || fields[2] == "=>" {
+				current = ""
+				continue
+			}
+			current = fields[1]
+			vendored[current] = VendorModule{Path: fields[1], Version: fields[2]}
+		case strings.HasPrefix(line, "## ") && current != "":
+			if strings.Contains(line, "explicit") {
+				vendored[current] = VendorModule{Path: current, Version: vendored[current].Version, Explicit: true}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file.Name(), err)
+	}
+	return vendored, nil
+}
+
+// VendorModule is a module listed in the modules.txt of a vendor directory
+type VendorModule struct {
+	Path    string
+	Version string
+	// Explicit modules are required by go.mod, go build -mod=vendor checks that they match
+	Explicit bool
+}
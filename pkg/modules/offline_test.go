@@ -0,0 +1,241 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/adamkali/egg_cli/pkg/targets"
+	"golang.org/x/mod/module"
+)
+
+// fakeModCache returns a module cache that contains the given modules
+func fakeModCache(t *testing.T, modules ...targets.Module) string {
+	t.Helper()
+	cache := t.TempDir()
+	for _, m := range modules {
+		path, _ := module.EscapePath(m.Path)
+		dir := filepath.Join(cache, "cache", "download", path, "@v")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, m.Version+".zip"), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return cache
+}
+
+// writeVendorDir writes a vendor directory whose modules.txt lists the given modules as explicit
+func writeVendorDir(t *testing.T, modules ...targets.Module) string {
+	t.Helper()
+	dir := t.TempDir()
+	var builder strings.Builder
+	for _, m := range modules {
+		builder.WriteString("# " + m.Path + " " + m.Version + "\n## explicit; go 1.23\n" + m.Path + "\n")
+	}
+	// a dependency of the explicit modules
+	builder.WriteString("# github.com/mattn/go-isatty v0.0.20\n## explicit\n# golang.org/x/sys v0.33.0\ngolang.org/x/sys/unix\n")
+	if err := os.WriteFile(filepath.Join(dir, VendorModulesFile), []byte(builder.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestCheckOffline_ModCache(t *testing.T) {
+	cache := fakeModCache(t, targets.GolangModules[1:]...)
+	offline := Offline{Enabled: true, ModCacheFunc: func(context.Context) (string, error) { return cache, nil }}
+	tools := []ToolStatus{
+		{Tool: targets.RequiredTools[0], State: ToolMissing},
+		{Tool: targets.RequiredTools[1], State: ToolPresent},
+	}
+
+	err := CheckOffline(context.Background(), offline, targets.GolangModules, nil, tools)
+	var offlineErr *OfflineError
+	if !errors.As(err, &offlineErr) {
+		t.Fatalf("CheckOffline() error = %v, want an OfflineError", err)
+	}
+	want := []string{
+		targets.GolangModules[0].String(),
+		targets.RequiredTools[0].ModulePath() + "@" + targets.RequiredTools[0].Version + " (tool air)",
+	}
+	if !reflect.DeepEqual(offlineErr.Missing, want) {
+		t.Errorf("Missing = %v, want %v", offlineErr.Missing, want)
+	}
+	if !strings.Contains(err.Error(), targets.GolangModules[0].String()) {
+		t.Errorf("Error() = %q, want the missing module", err.Error())
+	}
+
+	// once everything is cached nothing is missing
+	cache = fakeModCache(t, append(append([]targets.Module(nil), targets.GolangModules...),
+		targets.Module{Path: targets.RequiredTools[0].ModulePath(), Version: targets.RequiredTools[0].Version})...)
+	offline.GoFunc = func(context.Context, string, []string, ...string) ([]byte, error) { return nil, nil }
+	if err := CheckOffline(context.Background(), offline, targets.GolangModules, nil, tools); err != nil {
+		t.Errorf("CheckOffline() error = %v, want nil", err)
+	}
+}
+
+func TestCheckOffline_Graph(t *testing.T) {
+	tool := targets.RequiredTools[0]
+	required := targets.GolangModules[:2]
+	cache := fakeModCache(t, append(append([]targets.Module(nil), required...),
+		targets.Module{Path: tool.ModulePath(), Version: tool.Version})...)
+	var commands []string
+	offline := Offline{
+		Enabled:      true,
+		ModCacheFunc: func(context.Context) (string, error) { return cache, nil },
+		GoFunc: func(_ context.Context, dir string, env []string, args ...string) ([]byte, error) {
+			commands = append(commands, strings.Join(args, " "))
+			if !slices.Contains(env, "GOPROXY=off") || !slices.Contains(env, "GOMODCACHE="+cache) {
+				t.Errorf("go %s ran with %v, want the module cache with GOPROXY=off", strings.Join(args, " "), env)
+			}
+			if args[0] == "mod" {
+				content, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+				if err != nil {
+					t.Errorf("go mod download ran without the scratch go.mod: %v", err)
+				}
+				if strings.Contains(string(content), tool.ModulePath()) {
+					// the graph of the tool misses a module
+					return nil, errors.New("exit status 1: go: golang.org/x/tools@v0.33.0: module lookup disabled by GOPROXY=off")
+				}
+				return nil, nil
+			}
+			if args[1] == "-m" {
+				return []byte(`{"Path": "egg.offline/check"}
+{"Path": "github.com/labstack/echo/v4", "Version": "v4.13.3"}
+{"Path": "github.com/labstack/gommon", "Version": "v0.4.2"}
+`), nil
+			}
+			// a package of the libraries is built from a module that is not cached
+			return []byte(`{"ImportPath": "github.com/labstack/echo/v4"}
+{"ImportPath": "github.com/labstack/gommon/color", "Error": {"Err": "module lookup disabled by GOPROXY=off"}}
+{"ImportPath": "github.com/labstack/gommon/log", "Error": {"Err": "module lookup disabled by GOPROXY=off"}}
+`), nil
+		},
+	}
+
+	err := CheckOffline(context.Background(), offline, required, []string{"github.com/labstack/echo/v4"}, []ToolStatus{{Tool: tool, State: ToolMissing}})
+	var offlineErr *OfflineError
+	if !errors.As(err, &offlineErr) {
+		t.Fatalf("CheckOffline() error = %v, want an OfflineError", err)
+	}
+	want := []string{
+		"github.com/labstack/gommon@v0.4.2: module lookup disabled by GOPROXY=off",
+		"golang.org/x/tools@v0.33.0: module lookup disabled by GOPROXY=off (tool " + tool.Name + ")",
+	}
+	if !reflect.DeepEqual(offlineErr.Missing, want) {
+		t.Errorf("Missing = %v, want %v", offlineErr.Missing, want)
+	}
+	wantCommands := []string{
+		"mod download -json",
+		"list -e -deps -json=ImportPath,Error github.com/labstack/echo/v4",
+		"list -m -json=Path,Version all",
+		"mod download -json",
+	}
+	if !reflect.DeepEqual(commands, wantCommands) {
+		t.Errorf("ran %v, want %v", commands, wantCommands)
+	}
+}
+
+func TestCheckOffline_Vendor(t *testing.T) {
+	cache := fakeModCache(t)
+	offline := Offline{
+		Enabled:      true,
+		VendorDir:    writeVendorDir(t, targets.GolangModules[:len(targets.GolangModules)-1]...),
+		ModCacheFunc: func(context.Context) (string, error) { return cache, nil },
+	}
+	err := CheckOffline(context.Background(), offline, targets.GolangModules, nil, nil)
+	var offlineErr *OfflineError
+	if !errors.As(err, &offlineErr) {
+		t.Fatalf("CheckOffline() error = %v, want an OfflineError", err)
+	}
+	if want := []string{targets.GolangModules[len(targets.GolangModules)-1].Path}; !reflect.DeepEqual(offlineErr.Missing, want) {
+		t.Errorf("Missing = %v, want %v", offlineErr.Missing, want)
+	}
+
+	offline.VendorDir = t.TempDir()
+	if err := CheckOffline(context.Background(), offline, targets.GolangModules, nil, nil); err == nil || errors.As(err, &offlineErr) {
+		t.Errorf("CheckOffline() error = %v, want that the directory is not a vendor directory", err)
+	}
+}
+
+func TestReadVendorModules(t *testing.T) {
+	dir := writeVendorDir(t, targets.Module{Path: "github.com/google/uuid", Version: "v1.6.0"})
+	vendored, err := ReadVendorModules(dir)
+	if err != nil {
+		t.Fatalf("ReadVendorModules() error = %v", err)
+	}
+	want := map[string]VendorModule{
+		"github.com/google/uuid":     {Path: "github.com/google/uuid", Version: "v1.6.0", Explicit: true},
+		"github.com/mattn/go-isatty": {Path: "github.com/mattn/go-isatty", Version: "v0.0.20", Explicit: true},
+		"golang.org/x/sys":           {Path: "golang.org/x/sys", Version: "v0.33.0"},
+	}
+	if !reflect.DeepEqual(vendored, want) {
+		t.Errorf("ReadVendorModules() = %v, want %v", vendored, want)
+	}
+}
+
+func TestInstallLibrariesModule_Run_Vendor(t *testing.T) {
+	vendor := writeVendorDir(t, targets.Module{Path: "github.com/google/uuid", Version: "v1.6.0"})
+	if err := os.MkdirAll(filepath.Join(vendor, "github.com", "google", "uuid"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(vendor, "github.com", "google", "uuid", "uuid.go"), []byte("package uuid\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(t.TempDir())
+	writeGoMod(t)
+	logger := createTestLogger(t)
+	defer logger.Close()
+	m := &InstallLibrariesModule{eggl: logger}
+	m.SetOffline(Offline{Enabled: true, VendorDir: vendor})
+	m.GoModFunc = func(args ...string) ([]byte, error) {
+		t.Errorf("Run() ran go %s with a vendor directory", strings.Join(args, " "))
+		return nil, nil
+	}
+
+	result, err := m.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join("vendor", "github.com", "google", "uuid", "uuid.go")); err != nil {
+		t.Errorf("Run() did not copy the vendor directory: %v", err)
+	}
+	content, _ := os.ReadFile("go.mod")
+	for _, want := range []string{"github.com/google/uuid v1.6.0", "github.com/mattn/go-isatty v0.0.20"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("go.mod does not require %s:\n%s", want, content)
+		}
+	}
+	if strings.Contains(string(content), "golang.org/x/sys") {
+		t.Errorf("go.mod requires a module that is not explicit in modules.txt:\n%s", content)
+	}
+	if m.GetProgress() != 1 {
+		t.Errorf("GetProgress() = %v, want 1", m.GetProgress())
+	}
+	if !reflect.DeepEqual(result.Directories, []string{"vendor"}) {
+		t.Errorf("Directories = %v, want vendor", result.Directories)
+	}
+}
+
+func TestRsbuildFrontendModule_Run_Offline(t *testing.T) {
+	logger := createTestLogger(t)
+	defer logger.Close()
+	m := &RsbuildFrontendModule{eggl: logger}
+	m.SetOffline(Offline{Enabled: true})
+	if m.Interactive() {
+		t.Error("Interactive() = true, an offline run does not ask anything")
+	}
+	m.ExecFunc = func(cmd string, args ...string) ([]byte, error) {
+		t.Errorf("Run() ran %s offline", cmd)
+		return nil, nil
+	}
+	if _, err := m.Run(context.Background()); err != nil {
+		t.Errorf("Run() error = %v, want the frontend to be skipped", err)
+	}
+}
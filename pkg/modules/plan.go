@@ -0,0 +1,59 @@
+package modules
+
+// Plan
+//
+// description:
+//
+//	Plan describes what a module would do when it is run without doing any of it.
+//	Every path is relative to the project root, which is the directory of the
+//	Workspace that InitializeModule creates. Plans are built by IModule.Describe()
+//	after LoadFromConfig and are used by `egg_cli init --dry-run`.
+type Plan struct {
+	Module      string
+	Directories []string
+	Files       []string
+	Commands    []string
+	Tools       []string
+	Notes       []string
+}
+
+// IsEmpty reports whether the plan has nothing to show
+func (p Plan) IsEmpty() bool {
+	return len(p.Directories) == 0 &&
+		len(p.Files) == 0 &&
+		len(p.Commands) == 0 &&
+		len(p.Tools) == 0 &&
+		len(p.Notes) == 0
+}
+
+// Sections
+//
+// returns:
+//
+//	[]PlanSection: the non-empty parts of the plan in a stable order
+//
+// description:
+//
+//	Groups the plan into named sections so that it can be printed as a tree
+func (p Plan) Sections() []PlanSection {
+	all := []PlanSection{
+		{Title: "directories", Items: p.Directories},
+		{Title: "files", Items: p.Files},
+		{Title: "tools", Items: p.Tools},
+		{Title: "commands", Items: p.Commands},
+		{Title: "notes", Items: p.Notes},
+	}
+	sections := make([]PlanSection, 0, len(all))
+	for _, section := range all {
+		if len(section.Items) > 0 {
+			sections = append(sections, section)
+		}
+	}
+	return sections
+}
+
+// PlanSection is a titled list of plan entries
+type PlanSection struct {
+	Title string
+	Items []string
+}
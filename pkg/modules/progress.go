@@ -0,0 +1,15 @@
+package modules
+
+// IProgress is implemented by modules that report how far Run has come, from 0 to 1.
+// It is called from another goroutine while Run is executing, so it must be safe
+// for concurrent use.
+type IProgress interface {
+	GetProgress() float64
+}
+
+// IInteractive is implemented by modules that may ask the user a question during
+// Run. The runner never runs them alongside other modules and releases the terminal
+// from the progress view while they run.
+type IInteractive interface {
+	Interactive() bool
+}
@@ -0,0 +1,266 @@
+package modules
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Constructor returns a new instance of a module
+type Constructor func() IModule
+
+type registration struct {
+	name        string
+	constructor Constructor
+	dependsOn   []string
+}
+
+// Registry
+//
+// description:
+//
+//	Registry holds every module that `egg_cli init` runs together with the modules
+//	it depends on. The built in modules are registered in DefaultRegistry, and any
+//	other package can add its own pipeline steps to it with Register (usually from
+//	an init function) without changing egg_cli. It is safe to use from multiple
+//	goroutines.
+type Registry struct {
+	mu            sync.RWMutex
+	registrations []registration
+	byName        map[string]int
+	// aliases maps the legacy names of a module to its ID
+	aliases map[string]string
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{byName: make(map[string]int), aliases: make(map[string]string)}
+}
+
+// DefaultRegistry is the registry used by `egg_cli init` and `egg_cli recover`
+var DefaultRegistry = NewRegistry()
+
+// Register
+//
+// params:
+//
+//	name: string
+//	constructor: Constructor
+//	dependsOn: ...string
+//
+// description:
+//
+//	Adds a module to DefaultRegistry, see Registry.Register
+func Register(name string, constructor Constructor, dependsOn ...string) {
+	DefaultRegistry.Register(name, constructor, dependsOn...)
+}
+
+// RegisterAlias adds a legacy name for a module of DefaultRegistry, see Registry.RegisterAlias
+func RegisterAlias(alias, name string) {
+	DefaultRegistry.RegisterAlias(alias, name)
+}
+
+// Register
+//
+// params:
+//
+//	name: string
+//	constructor: Constructor
+//	dependsOn: ...string
+//
+// description:
+//
+//	Adds a module that runs after every module in dependsOn. The name has to be the
+//	Name() of the modules returned by constructor, since it is what the .scrambled
+//	file records and what `egg_cli recover` looks the module up by. Like sql.Register
+//	it panics when the name is empty, already registered or does not match, because
+//	that is a programming error of the package registering the module.
+func (r *Registry) Register(name string, constructor Constructor, dependsOn ...string) {
+	if name == "" {
+		panic("modules: Register with an empty name")
+	}
+	if constructor == nil {
+		panic("modules: Register constructor is nil for " + name)
+	}
+	if got := constructor().Name(); got != name {
+		panic(fmt.Sprintf("modules: Register %s constructs a module named %s", name, got))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.byName[name]; ok {
+		panic("modules: Register called twice for " + name)
+	}
+	if _, ok := r.aliases[name]; ok {
+		panic("modules: Register " + name + " is already an alias")
+	}
+	r.byName[name] = len(r.registrations)
+	r.registrations = append(r.registrations, registration{
+		name:        name,
+		constructor: constructor,
+		dependsOn:   slices.Clone(dependsOn),
+	})
+}
+
+// RegisterAlias
+//
+// params:
+//
+//	alias: string
+//	name: string
+//
+// description:
+//
+//	Makes alias another name of the registered module name, so that a renamed module
+//	is still found by the name older .scrambled files recorded for it. It panics when
+//	name is not registered or alias is already in use.
+func (r *Registry) RegisterAlias(alias, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.byName[name]; !ok {
+		panic("modules: RegisterAlias " + alias + " for " + name + " which is not registered")
+	}
+	if _, ok := r.byName[alias]; ok {
+		panic("modules: RegisterAlias " + alias + " is already a module")
+	}
+	if _, ok := r.aliases[alias]; ok {
+		panic("modules: RegisterAlias called twice for " + alias)
+	}
+	r.aliases[alias] = name
+}
+
+// Resolve returns the ID of the module registered by the given ID or alias
+func (r *Registry) Resolve(name string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	i, ok := r.index(name)
+	if !ok {
+		return "", false
+	}
+	return r.registrations[i].name, true
+}
+
+// index returns the position of the module registered by the given ID or alias
+func (r *Registry) index(name string) (int, bool) {
+	if id, ok := r.aliases[name]; ok {
+		name = id
+	}
+	i, ok := r.byName[name]
+	return i, ok
+}
+
+// New returns a new instance of the named module, or nil if it is not registered
+func (r *Registry) New(name string) IModule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	i, ok := r.index(name)
+	if !ok {
+		return nil
+	}
+	return r.registrations[i].constructor()
+}
+
+// DependsOn returns the names of the modules the named module depends on
+func (r *Registry) DependsOn(name string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	i, ok := r.index(name)
+	if !ok {
+		return nil
+	}
+	return slices.Clone(r.registrations[i].dependsOn)
+}
+
+// Names returns the names of the registered modules in the order they were registered
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, len(r.registrations))
+	for i, registration := range r.registrations {
+		names[i] = registration.name
+	}
+	return names
+}
+
+// Modules
+//
+// returns:
+//
+//	[]IModule: a new instance of every registered module in execution order
+//	error:
+//	  - if a module depends on a module that is not registered
+//	  - if the dependencies contain a cycle
+//
+// description:
+//
+//	Orders the modules so that every module comes after the modules it depends on.
+//	Modules that do not depend on each other keep the order they were registered in,
+//	so a module added by another package runs as late as its dependencies allow.
+func (r *Registry) Modules() ([]IModule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// dependents[i] are the registrations that have to wait for registration i
+	waiting := make([]int, len(r.registrations))
+	dependents := make([][]int, len(r.registrations))
+	for i, registration := range r.registrations {
+		for _, dependency := range registration.dependsOn {
+			j, ok := r.index(dependency)
+			if !ok {
+				return nil, fmt.Errorf("module %s depends on %s which is not registered", registration.name, dependency)
+			}
+			waiting[i]++
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+
+	ordered := make([]IModule, 0, len(r.registrations))
+	done := make([]bool, len(r.registrations))
+	for len(ordered) < len(r.registrations) {
+		// always take the earliest registered module that is ready
+		next := -1
+		for i := range r.registrations {
+			if !done[i] && waiting[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next == -1 {
+			var cycle []string
+			for i, registration := range r.registrations {
+				if !done[i] {
+					cycle = append(cycle, registration.name)
+				}
+			}
+			return nil, fmt.Errorf("module dependencies contain a cycle between %s", strings.Join(cycle, ", "))
+		}
+		done[next] = true
+		ordered = append(ordered, r.registrations[next].constructor())
+		for _, dependent := range dependents[next] {
+			waiting[dependent]--
+		}
+	}
+	return ordered, nil
+}
+
+func init() {
+	Register(InitializeModuleID, func() IModule { return &InitializeModule{} })
+	Register(InstallToolsModuleID, func() IModule { return &InstallToolsModule{} }, InitializeModuleID)
+	// go mod tidy has to see the imports of the rendered templates
+	Register(InstallLibrariesModuleID, func() IModule { return &InstallLibrariesModule{} }, BootstrapFrameworkModuleID)
+	Register(BootstrapDirectoriesModuleID, func() IModule { return &BootstrapDirectoriesModule{} }, InitializeModuleID)
+	Register(GenerateConfigurationModuleID, func() IModule { return &GenerateConfigurationModule{} }, BootstrapDirectoriesModuleID)
+	Register(BootstrapFrameworkModuleID, func() IModule { return &BootstrapFrameworkFilesFromTemplatesModule{} }, BootstrapDirectoriesModuleID)
+	Register(RsbuildFrontendModuleID, func() IModule { return &RsbuildFrontendModule{} }, BootstrapFrameworkModuleID)
+
+	// the names ModuleFactory used to accept, and the misspelled name the
+	// framework module recorded in .scrambled files before it was renamed
+	RegisterAlias("egg::install-tools", InstallToolsModuleID)
+	RegisterAlias("egg::install-libraries", InstallLibrariesModuleID)
+	RegisterAlias("egg::bootstrap-directories", BootstrapDirectoriesModuleID)
+	RegisterAlias("egg::generate-configuration", GenerateConfigurationModuleID)
+	RegisterAlias("egg::bootstrap-framework-files-from-templates", BootstrapFrameworkModuleID)
+	RegisterAlias("egg::bootstrap_framwork", BootstrapFrameworkModuleID)
+	RegisterAlias("egg::rsbuild-frontend", RsbuildFrontendModuleID)
+}
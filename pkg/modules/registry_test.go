@@ -0,0 +1,193 @@
+package modules
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/adamkali/egg_cli/pkg/configuration"
+	"github.com/adamkali/egg_cli/pkg/models"
+)
+
+// namedModule does nothing but report its name
+type namedModule struct{ name string }
+
+func (m *namedModule) Name() string                                                { return m.name }
+func (m *namedModule) LoadFromConfig(*configuration.Configuration, *models.EggLog) {}
+func (m *namedModule) Describe() Plan                                              { return Plan{Module: m.name} }
+func (m *namedModule) Run(context.Context) (Result, error)                         { return Result{Module: m.name}, nil }
+
+func named(name string) Constructor {
+	return func() IModule { return &namedModule{name: name} }
+}
+
+func moduleNames(modules []IModule) []string {
+	names := make([]string, len(modules))
+	for i, module := range modules {
+		names[i] = module.Name()
+	}
+	return names
+}
+
+func TestDefaultRegistry_BuiltinOrder(t *testing.T) {
+	ordered, err := DefaultRegistry.Modules()
+	if err != nil {
+		t.Fatalf("Modules() error = %v", err)
+	}
+	want := []string{
+		"egg::initialize",
+		"egg::install_tools",
+		"egg::bootstrap_directories",
+		"egg::generate_configuration",
+		"egg::bootstrap_framework",
+		"egg::install_libraries",
+		"egg::rsbuild_frontend",
+	}
+	if got := moduleNames(ordered); !reflect.DeepEqual(got, want) {
+		t.Errorf("Modules() = %v, want %v", got, want)
+	}
+}
+
+func TestRegistry_OrdersByDependencies(t *testing.T) {
+	r := NewRegistry()
+	r.Register("egg::ci", named("egg::ci"), "egg::auth")
+	r.Register("egg::auth", named("egg::auth"), "egg::initialize")
+	r.Register("egg::initialize", named("egg::initialize"))
+	r.Register("egg::docs", named("egg::docs"))
+
+	ordered, err := r.Modules()
+	if err != nil {
+		t.Fatalf("Modules() error = %v", err)
+	}
+	want := []string{"egg::initialize", "egg::auth", "egg::ci", "egg::docs"}
+	if got := moduleNames(ordered); !reflect.DeepEqual(got, want) {
+		t.Errorf("Modules() = %v, want %v", got, want)
+	}
+}
+
+func TestRegistry_ModulesReturnsNewInstances(t *testing.T) {
+	r := NewRegistry()
+	r.Register("egg::first", named("egg::first"))
+
+	first, _ := r.Modules()
+	second, _ := r.Modules()
+	if first[0] == second[0] {
+		t.Error("Modules() returned the same instance twice")
+	}
+}
+
+func TestRegistry_UnknownDependency(t *testing.T) {
+	r := NewRegistry()
+	r.Register("egg::ci", named("egg::ci"), "egg::missing")
+
+	_, err := r.Modules()
+	if err == nil || !strings.Contains(err.Error(), "egg::missing") {
+		t.Errorf("Modules() error = %v, want the missing dependency", err)
+	}
+}
+
+func TestRegistry_Cycle(t *testing.T) {
+	r := NewRegistry()
+	r.Register("egg::first", named("egg::first"), "egg::second")
+	r.Register("egg::second", named("egg::second"), "egg::first")
+
+	_, err := r.Modules()
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("Modules() error = %v, want a cycle error", err)
+	}
+}
+
+func TestRegistry_RegisterPanics(t *testing.T) {
+	tests := map[string]func(r *Registry){
+		"empty name":     func(r *Registry) { r.Register("", named("")) },
+		"nil":            func(r *Registry) { r.Register("egg::nil", nil) },
+		"name mismatch":  func(r *Registry) { r.Register("egg::first", named("egg::second")) },
+		"duplicate name": func(r *Registry) { r.Register("egg::first", named("egg::first")) },
+	}
+	for name, register := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := NewRegistry()
+			r.Register("egg::first", named("egg::first"))
+			defer func() {
+				if recover() == nil {
+					t.Error("Register() did not panic")
+				}
+			}()
+			register(r)
+		})
+	}
+}
+
+func TestRegistry_NewAndDependsOn(t *testing.T) {
+	r := NewRegistry()
+	r.Register("egg::first", named("egg::first"))
+	r.Register("egg::second", named("egg::second"), "egg::first")
+
+	if module := r.New("egg::second"); module == nil || module.Name() != "egg::second" {
+		t.Errorf("New() = %v, want egg::second", module)
+	}
+	if module := r.New("egg::missing"); module != nil {
+		t.Errorf("New() = %v, want nil for an unknown module", module)
+	}
+	if got, want := r.DependsOn("egg::second"), []string{"egg::first"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DependsOn() = %v, want %v", got, want)
+	}
+}
+
+func TestModuleFactory_FindsRegisteredModules(t *testing.T) {
+	for _, name := range DefaultRegistry.Names() {
+		if module := ModuleFactory(name); module == nil || module.Name() != name {
+			t.Errorf("ModuleFactory(%q) = %v", name, module)
+		}
+	}
+}
+
+func TestRegistry_Aliases(t *testing.T) {
+	r := NewRegistry()
+	r.Register("egg::first", named("egg::first"))
+	r.RegisterAlias("egg::old-first", "egg::first")
+
+	if id, ok := r.Resolve("egg::old-first"); !ok || id != "egg::first" {
+		t.Errorf("Resolve() = %q, %v, want egg::first", id, ok)
+	}
+	if module := r.New("egg::old-first"); module == nil || module.Name() != "egg::first" {
+		t.Errorf("New() = %v, want egg::first for its alias", module)
+	}
+	if _, ok := r.Resolve("egg::missing"); ok {
+		t.Error("Resolve() found an unknown module")
+	}
+
+	for name, register := range map[string]func(){
+		"unknown module":  func() { r.RegisterAlias("egg::old-missing", "egg::missing") },
+		"alias is module": func() { r.RegisterAlias("egg::first", "egg::first") },
+		"duplicate alias": func() { r.RegisterAlias("egg::old-first", "egg::first") },
+		"module is alias": func() { r.Register("egg::old-first", named("egg::old-first")) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("did not panic")
+				}
+			}()
+			register()
+		})
+	}
+}
+
+func TestModuleFactory_LegacyNames(t *testing.T) {
+	legacy := map[string]string{
+		"egg::install-tools":                            InstallToolsModuleID,
+		"egg::install-libraries":                        InstallLibrariesModuleID,
+		"egg::bootstrap-directories":                    BootstrapDirectoriesModuleID,
+		"egg::generate-configuration":                   GenerateConfigurationModuleID,
+		"egg::bootstrap-framework-files-from-templates": BootstrapFrameworkModuleID,
+		"egg::bootstrap_framwork":                       BootstrapFrameworkModuleID,
+		"egg::rsbuild-frontend":                         RsbuildFrontendModuleID,
+	}
+	for name, id := range legacy {
+		if module := ModuleFactory(name); module == nil || module.Name() != id {
+			t.Errorf("ModuleFactory(%q) = %v, want %s", name, module, id)
+		}
+	}
+}
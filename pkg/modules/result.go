@@ -0,0 +1,94 @@
+package modules
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Result
+//
+// description:
+//
+//	Result records what a module actually did during Run, as opposed to Plan
+//	which records what it would do. The runner collects one Result per module
+//	to print a summary at the end of `egg_cli init`, even when a module failed
+//	or the run was cancelled part way through.
+type Result struct {
+	Module      string
+	Directories []string
+	Files       []string
+	Commands    []CommandResult
+	Duration    time.Duration
+	// Undo reverts what the module changed, used by transactional runs
+	Undo []UndoAction
+	// Checkpoints are the steps that completed, stored in .scrambled for recover
+	Checkpoints []string
+}
+
+// CommandResult is a single command that was executed by a module
+type CommandResult struct {
+	Command  string
+	Duration time.Duration
+	Error    error
+}
+
+// newResult starts the result of a module, Duration is stamped by the runner
+func newResult(m IModule) Result {
+	return Result{Module: m.Name()}
+}
+
+// record times fn and appends it to the executed commands as name
+func (r *Result) record(name string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	r.Commands = append(r.Commands, CommandResult{
+		Command:  name,
+		Duration: time.Since(start),
+		Error:    err,
+	})
+	return err
+}
+
+// runCommand
+//
+// params:
+//
+//	ctx: context.Context
+//	name: string
+//	args: ...string
+//
+// returns:
+//
+//	[]byte: the stdout of the command
+//	error: if the command could not be run or exited with an error
+//
+// description:
+//
+//	Runs a command bound to ctx, so that cancelling the context (Ctrl-C during
+//	`egg_cli init`) kills the subprocess, and records it in the result.
+func (r *Result) runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return r.runCommandIn(ctx, "", nil, name, args...)
+}
+
+// runCommandIn is runCommand inside of dir, an empty dir is the working directory, with
+// env added to the environment of the command, e.g. GOPROXY=off
+func (r *Result) runCommandIn(ctx context.Context, dir string, env []string, name string, args ...string) ([]byte, error) {
+	var output []byte
+	err := r.record(strings.Join(append(append([]string(nil), env...), append([]string{name}, args...)...), " "), func() error {
+		var err error
+		cmd := exec.CommandContext(ctx, name, args...)
+		cmd.Dir = dir
+		if len(env) > 0 {
+			cmd.Env = append(os.Environ(), env...)
+		}
+		output, err = cmd.Output()
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return err
+	})
+	return output, err
+}
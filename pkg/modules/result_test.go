@@ -0,0 +1,60 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestResult_Record(t *testing.T) {
+	result := Result{Module: "egg::test"}
+	err := result.record("go version", func() error {
+		return errors.New("simulated error")
+	})
+	if err == nil {
+		t.Error("record() did not return the error of fn")
+	}
+	if len(result.Commands) != 1 {
+		t.Fatalf("record() recorded %d commands, want 1", len(result.Commands))
+	}
+	if result.Commands[0].Command != "go version" || result.Commands[0].Error == nil {
+		t.Errorf("record() = %+v, want the failed go version command", result.Commands[0])
+	}
+}
+
+func TestResult_RunCommand(t *testing.T) {
+	if _, err := exec.LookPath("echo"); err != nil {
+		t.Skip("echo is not available")
+	}
+	result := Result{Module: "egg::test"}
+	output, err := result.runCommand(context.Background(), "echo", "egg")
+	if err != nil {
+		t.Fatalf("runCommand() error = %v", err)
+	}
+	if string(output) != "egg\n" {
+		t.Errorf("runCommand() output = %q, want %q", output, "egg\n")
+	}
+	if result.Commands[0].Command != "echo egg" {
+		t.Errorf("runCommand() recorded %q, want %q", result.Commands[0].Command, "echo egg")
+	}
+}
+
+func TestResult_RunCommand_Cancelled(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep is not available")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	result := Result{Module: "egg::test"}
+	start := time.Now()
+	_, err := result.runCommand(ctx, "sleep", "10")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("runCommand() error = %v, want context.DeadlineExceeded", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Error("runCommand() did not stop the subprocess when the context was cancelled")
+	}
+}
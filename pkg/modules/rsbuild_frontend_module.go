@@ -15,9 +15,11 @@
 package modules
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"strings"
+	"sync"
 
 	"github.com/adamkali/egg_cli/pkg/configuration"
 	"github.com/adamkali/egg_cli/pkg/models"
@@ -39,9 +41,12 @@ const (
 //
 //	This struct is used to build the frontend if the user chooses to do so
 type RsbuildFrontendModule struct {
+	offlineMode
+	projectWorkspace
 	configuration *configuration.Configuration
 	error         error
 	progress      int
+	mu            sync.Mutex
 	eggl          *models.EggLog
 	InputFunc     func(prompt string) string                       // For testing - can be injected to mock user input
 	ExecFunc      func(cmd string, args ...string) ([]byte, error) // For testing - can be injected to mock command execution
@@ -57,7 +62,7 @@ type RsbuildFrontendModule struct {
 //
 //	This function returns the name of the module
 func (m *RsbuildFrontendModule) Name() string {
-	return "egg::rsbuild_frontend"
+	return RsbuildFrontendModuleID
 }
 
 // IncrProg
@@ -66,15 +71,24 @@ func (m *RsbuildFrontendModule) Name() string {
 //
 //	increments the progress
 func (m *RsbuildFrontendModule) IncrProg() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.progress += 1
 }
 
+// Interactive reports that Run asks which frontend and package manager to use
+func (m *RsbuildFrontendModule) Interactive() bool {
+	return m.InputFunc == nil && !m.offline.Enabled && m.configuration.HasFeature(configuration.FeatureFrontend)
+}
+
 // GetProgress
 //
 // returns:
 //
 //	float64: the progress
 func (m *RsbuildFrontendModule) GetProgress() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	return float64(m.progress)
 }
 
@@ -86,7 +100,27 @@ func (m *RsbuildFrontendModule) GetProgress() float64 {
 //	  pnpm create rsbuild@latest || npm create rsbuild@latest || yarn create rsbuild || bun create rsbuild
 //	  we firrst ask the user which frontend framework they are using and then we build the frontend and
 //	  passing off the control flow to rsbuild for the user to interact with the cli interface
-func (m *RsbuildFrontendModule) Run() {
+func (m *RsbuildFrontendModule) Run(ctx context.Context) (Result, error) {
+	result := newResult(m)
+	if err := ctx.Err(); err != nil {
+		m.error = err
+		return result, m.error
+	}
+	if !m.configuration.HasFeature(configuration.FeatureFrontend) {
+		skipMessage := fmt.Sprintf("🥚 %s skipped, the frontend feature is disabled", m.Name())
+		m.eggl.Info(skipMessage)
+		fmt.Println(skipMessage)
+		m.IncrProg()
+		return result, nil
+	}
+	if m.offline.Enabled {
+		// every package manager downloads the frontend template
+		skipMessage := fmt.Sprintf("🥚 %s skipped, the frontend can not be created offline", m.Name())
+		m.eggl.Info(skipMessage)
+		fmt.Println(skipMessage)
+		m.IncrProg()
+		return result, nil
+	}
 	// ask the user if they want to use js for the frontend
 	// if they do not want to use js for the frontend, exit
 	var useJs string
@@ -97,7 +131,7 @@ func (m *RsbuildFrontendModule) Run() {
 		fmt.Scanln(&useJs)
 	}
 	if useJs == "n" || useJs == "N" || useJs == "no" || useJs == "No" || useJs == "NO" {
-		return
+		return result, nil
 	}
 
 	// ask which package manager do they want to use?
@@ -110,51 +144,52 @@ func (m *RsbuildFrontendModule) Run() {
 	}
 	switch packageManager {
 	case "pnpm":
-		m.installAndWaitForRsBuild(PnpmInstall)
+		m.installAndWaitForRsBuild(ctx, &result, PnpmInstall)
 	case "p":
-		m.installAndWaitForRsBuild(PnpmInstall)
+		m.installAndWaitForRsBuild(ctx, &result, PnpmInstall)
 	case "npm":
-		m.installAndWaitForRsBuild(NpmInstall)
+		m.installAndWaitForRsBuild(ctx, &result, NpmInstall)
 	case "n":
-		m.installAndWaitForRsBuild(NpmInstall)
+		m.installAndWaitForRsBuild(ctx, &result, NpmInstall)
 	case "yarn":
-		m.installAndWaitForRsBuild(YarnInstall)
+		m.installAndWaitForRsBuild(ctx, &result, YarnInstall)
 	case "y":
-		m.installAndWaitForRsBuild(YarnInstall)
+		m.installAndWaitForRsBuild(ctx, &result, YarnInstall)
 	case "bun":
-		m.installAndWaitForRsBuild(BunInstall)
+		m.installAndWaitForRsBuild(ctx, &result, BunInstall)
 	case "b":
-		m.installAndWaitForRsBuild(BunInstall)
+		m.installAndWaitForRsBuild(ctx, &result, BunInstall)
 	default:
 		m.error = fmt.Errorf("invalid package manager: %s", packageManager)
 		m.eggl.Error("error: %s", m.error.Error())
-		return
+		return result, m.error
 	}
-	return
+	return result, m.error
 }
 
 // installAndWaitForRsBuild
 //
 // params:
 //
+//	context.Context: cancels the package manager when done
+//	*Result: the result the command is recorded in
 //	string: the package manager
 //
-// returns:
-//
-//	error: any errors that occur to install the frontend
-//
 // description:
 //
 //			This function is used to install the frontend framework and wait for rsbuild
 //	     to finish building the frontend. It will wait for rsbuild to take control of
 //	     stdin and stdout and then pass off the control flow to rsbuild. then when it
-//	     is done, it will return the error
-func (m *RsbuildFrontendModule) installAndWaitForRsBuild(packageManager string) {
+//	     is done, it will store the error on the module
+func (m *RsbuildFrontendModule) installAndWaitForRsBuild(ctx context.Context, result *Result, packageManager string) {
 	p := strings.Split(packageManager, " ")[0]
 	rest := strings.Split(packageManager, " ")[1:]
 	var err error
 	if m.ExecFunc != nil {
-		_, err = m.ExecFunc(p, rest...)
+		err = result.record(packageManager, func() error {
+			_, err := m.ExecFunc(p, rest...)
+			return err
+		})
 	} else {
 		_, err = exec.LookPath(p)
 		if err != nil {
@@ -163,7 +198,7 @@ func (m *RsbuildFrontendModule) installAndWaitForRsBuild(packageManager string)
 			return
 		}
 		var output []byte
-		output, m.error = exec.Command(packageManager, rest...).Output()
+		output, m.error = result.runCommandIn(ctx, m.workspace.Dir, nil, p, rest...)
 		if m.error != nil {
 			m.eggl.Error("error: %s", m.error.Error())
 			return
@@ -175,9 +210,38 @@ func (m *RsbuildFrontendModule) installAndWaitForRsBuild(packageManager string)
 		m.eggl.Error("error: %s", m.error.Error())
 		return
 	}
+	m.IncrProg()
 	return
 }
 
+// Describe
+//
+// returns:
+//
+//	Plan: the frontend commands the user can choose from
+func (m *RsbuildFrontendModule) Describe() Plan {
+	if !m.configuration.HasFeature(configuration.FeatureFrontend) {
+		return Plan{
+			Module: m.Name(),
+			Notes:  []string{"skipped, the frontend feature is disabled"},
+		}
+	}
+	if m.offline.Enabled {
+		return Plan{
+			Module: m.Name(),
+			Notes:  []string{"skipped, the frontend can not be created offline"},
+		}
+	}
+	return Plan{
+		Module:   m.Name(),
+		Commands: []string{PnpmInstall, NpmInstall, YarnInstall, BunInstall},
+		Notes: []string{
+			"asks whether to use a JavaScript framework for the frontend",
+			"runs only the command of the chosen package manager",
+		},
+	}
+}
+
 // IsError
 //
 // returns:
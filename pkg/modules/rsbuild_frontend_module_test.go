@@ -1,8 +1,11 @@
 package modules
 
 import (
+	"context"
 	"errors"
 	"testing"
+
+	"github.com/adamkali/egg_cli/pkg/configuration"
 )
 
 func TestRsbuildFrontendModule_Name(t *testing.T) {
@@ -50,12 +53,35 @@ func TestRsbuildFrontendModule_Run_UserSaysNo(t *testing.T) {
 		return ""
 	}
 
-	m.Run()
+	m.Run(context.Background())
 	if m.IsError() != nil {
 		t.Errorf("Run() set unexpected error: %v", m.IsError())
 	}
 }
 
+func TestRsbuildFrontendModule_Run_FrontendDisabled(t *testing.T) {
+	logger := createTestLogger(t)
+	defer logger.Close()
+	cfg := createTestConfiguration()
+	cfg.SetFeature(configuration.FeatureFrontend, false)
+	m := &RsbuildFrontendModule{configuration: cfg, eggl: logger}
+	m.InputFunc = func(prompt string) string {
+		t.Errorf("Run() must not prompt without the frontend feature: %s", prompt)
+		return ""
+	}
+
+	result, err := m.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(result.Commands) != 0 {
+		t.Errorf("Run() Commands = %v, want none", result.Commands)
+	}
+	if plan := m.Describe(); len(plan.Commands) != 0 {
+		t.Errorf("Describe() Commands = %v, want none", plan.Commands)
+	}
+}
+
 func TestRsbuildFrontendModule_Run_Success(t *testing.T) {
 	logger := createTestLogger(t)
 	defer logger.Close()
@@ -77,7 +103,7 @@ func TestRsbuildFrontendModule_Run_Success(t *testing.T) {
 		return []byte("rsbuild success"), nil
 	}
 
-	m.Run()
+	m.Run(context.Background())
 	if m.IsError() != nil {
 		t.Errorf("Run() set unexpected error: %v", m.IsError())
 	}
@@ -105,7 +131,7 @@ func TestRsbuildFrontendModule_Run_ExecError(t *testing.T) {
 		return nil, errors.New("simulated exec error")
 	}
 
-	m.Run()
+	m.Run(context.Background())
 	if m.IsError() == nil {
 		t.Error("Run() did not set error on exec failure")
 	}
@@ -126,8 +152,20 @@ func TestRsbuildFrontendModule_Run_InvalidPackageManager(t *testing.T) {
 		return "invalid" // Invalid package manager
 	}
 
-	m.Run()
+	m.Run(context.Background())
 	if m.IsError() == nil {
 		t.Error("Run() did not set error on invalid package manager")
 	}
 }
+
+func TestRsbuildFrontendModule_Describe(t *testing.T) {
+	m := &RsbuildFrontendModule{}
+	m.InputFunc = func(prompt string) string {
+		t.Errorf("Describe() must not prompt: %s", prompt)
+		return ""
+	}
+	plan := m.Describe()
+	if len(plan.Commands) != 4 {
+		t.Errorf("Describe() Commands = %v, want one per package manager", plan.Commands)
+	}
+}
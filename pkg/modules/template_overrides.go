@@ -0,0 +1,11 @@
+package modules
+
+import "text/template"
+
+// ITemplates is implemented by modules that render or inspect the templates of the project.
+// The runner calls SetTemplates after LoadFromConfig with templates.Mapping and the
+// overrides of the template directory (see templates.Load), so every module sees the
+// same templates.
+type ITemplates interface {
+	SetTemplates(mapping map[string]*template.Template)
+}
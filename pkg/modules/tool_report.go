@@ -0,0 +1,191 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/adamkali/egg_cli/pkg/targets"
+)
+
+// versionProbeTimeout bounds `<tool> --version`, a binary that does not know the flag
+// could otherwise start doing its actual work
+const versionProbeTimeout = 5 * time.Second
+
+// ToolState is what was found on the PATH for a pinned tool
+type ToolState int
+
+const (
+	// ToolMissing is a tool that is not on the PATH
+	ToolMissing ToolState = iota
+	// ToolPresent is a tool on the PATH with the version pinned in egg.lock
+	ToolPresent
+	// ToolOutdated is a tool on the PATH with another version than the pinned one
+	ToolOutdated
+	// ToolUnverified is a tool on the PATH whose version could not be read
+	ToolUnverified
+)
+
+func (s ToolState) String() string {
+	switch s {
+	case ToolPresent:
+		return "present"
+	case ToolOutdated:
+		return "outdated"
+	case ToolUnverified:
+		return "unverified"
+	default:
+		return "missing"
+	}
+}
+
+// ToolStatus is the state of a single pinned tool
+type ToolStatus struct {
+	Tool targets.Tool
+	// Path is where the binary was found, empty if it is missing
+	Path string
+	// Version is the version of the binary, empty if it could not be read
+	Version string
+	State   ToolState
+	// Err is why the version could not be read
+	Err error
+}
+
+// NeedsInstall reports whether the pinned version has to be installed
+func (s ToolStatus) NeedsInstall() bool {
+	return s.State == ToolMissing || s.State == ToolOutdated
+}
+
+// Inspect
+//
+// params:
+//
+//	ctx: context.Context
+//	tools: []targets.Tool
+//
+// returns:
+//
+//	[]ToolStatus: the status of every tool, in the order of tools
+//
+// description:
+//
+//	Looks up every tool by its binary name and reads the version of the binary,
+//	first from the build information `go install` records (`go version -m`) and
+//	otherwise by running it with its version flag. Nothing is installed, so this
+//	is safe to use without network access.
+func (m *InstallToolsModule) Inspect(ctx context.Context, tools []targets.Tool) []ToolStatus {
+	statuses := make([]ToolStatus, len(tools))
+	for i, tool := range tools {
+		statuses[i] = m.inspect(ctx, tool)
+	}
+	return statuses
+}
+
+func (m *InstallToolsModule) inspect(ctx context.Context, tool targets.Tool) ToolStatus {
+	status := ToolStatus{Tool: tool}
+
+	// Use injected function if available, otherwise use real implementation
+	var err error
+	if m.LookPathFunc != nil {
+		status.Path, err = m.LookPathFunc(tool.Name)
+	} else {
+		status.Path, err = exec.LookPath(tool.Name)
+	}
+	if err != nil {
+		status.Path = ""
+		return status
+	}
+
+	// Use injected function if available, otherwise use real implementation
+	if m.VersionFunc != nil {
+		status.Version, err = m.VersionFunc(tool, status.Path)
+	} else {
+		status.Version, err = binaryVersion(ctx, tool, status.Path)
+	}
+	switch {
+	case err != nil:
+		status.State = ToolUnverified
+		status.Err = err
+	case status.Version == tool.Version:
+		status.State = ToolPresent
+	default:
+		status.State = ToolOutdated
+	}
+	return status
+}
+
+// binaryVersion returns the version of the binary at path
+func binaryVersion(ctx context.Context, tool targets.Tool, path string) (string, error) {
+	if version, err := goBinaryVersion(ctx, path); err == nil {
+		return version, nil
+	}
+	return probeVersion(ctx, tool, path)
+}
+
+// goBinaryVersion returns the module version a go binary was built from, using `go version -m`
+func goBinaryVersion(ctx context.Context, path string) (string, error) {
+	output, err := exec.CommandContext(ctx, "go", "version", "-m", path).Output()
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		// binaries built from a checkout report (devel) instead of a version
+		if len(fields) >= 3 && fields[0] == "mod" && fields[2] != "(devel)" {
+			return fields[2], nil
+		}
+	}
+	return "", fmt.Errorf("%s does not record its module version", path)
+}
+
+var versionPattern = regexp.MustCompile(`\bv?(\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?)`)
+
+// probeVersion runs the binary with its version flag and returns the first version in the output
+func probeVersion(ctx context.Context, tool targets.Tool, path string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, versionProbeTimeout)
+	defer cancel()
+	args := tool.VersionArgs()
+	output, err := exec.CommandContext(ctx, path, args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %w", tool.Name, strings.Join(args, " "), err)
+	}
+	return parseVersion(string(output))
+}
+
+// parseVersion finds the first semantic version in the output of a version flag
+func parseVersion(output string) (string, error) {
+	match := versionPattern.FindStringSubmatch(output)
+	if match == nil {
+		return "", fmt.Errorf("no version in %q", strings.TrimSpace(output))
+	}
+	return "v" + match[1], nil
+}
+
+// RenderToolReport renders the statuses as a table of present, missing and outdated tools
+func RenderToolReport(statuses []ToolStatus) string {
+	width := 0
+	for _, status := range statuses {
+		width = max(width, len(status.Tool.Name))
+	}
+
+	var builder strings.Builder
+	builder.WriteString("🥚 tools\n")
+	for _, status := range statuses {
+		var icon, detail string
+		switch status.State {
+		case ToolPresent:
+			icon, detail = "✓", fmt.Sprintf("%s %s", status.Version, status.Path)
+		case ToolOutdated:
+			icon, detail = "!", fmt.Sprintf("%s %s, %s is pinned in %s", status.Version, status.Path, status.Tool.Version, targets.LockFileName)
+		case ToolUnverified:
+			icon, detail = "?", fmt.Sprintf("%s, the version could not be checked against %s", status.Path, status.Tool.Version)
+		default:
+			icon, detail = "✗", fmt.Sprintf("missing, %s is pinned in %s", status.Tool.Version, targets.LockFileName)
+		}
+		builder.WriteString(fmt.Sprintf("%s %-*s %-10s %s\n", icon, width, status.Tool.Name, status.State, detail))
+	}
+	return builder.String()
+}
@@ -0,0 +1,88 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adamkali/egg_cli/pkg/targets"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := map[string]string{
+		"swag version v1.16.4\n":                 "v1.16.4",
+		"goose version: v3.24.3":                 "v3.24.3",
+		"1.29.0":                                 "v1.29.0",
+		"air v1.61.7, built with Go go1.24.2":    "v1.61.7",
+		"tool version v2.0.0-rc.1 (linux/amd64)": "v2.0.0-rc.1",
+	}
+	for output, want := range tests {
+		if got, err := parseVersion(output); err != nil || got != want {
+			t.Errorf("parseVersion(%q) = %q, %v, want %q", output, got, err, want)
+		}
+	}
+	if _, err := parseVersion("unknown flag: --version"); err == nil {
+		t.Error("parseVersion() found a version in an error message")
+	}
+}
+
+func TestInstallToolsModule_Inspect(t *testing.T) {
+	tools := []targets.Tool{
+		{Name: "present", Version: "v1.0.0"},
+		{Name: "outdated", Version: "v1.0.0"},
+		{Name: "unverified", Version: "v1.0.0"},
+		{Name: "missing", Version: "v1.0.0"},
+	}
+	m := &InstallToolsModule{}
+	m.LookPathFunc = func(file string) (string, error) {
+		if file == "missing" {
+			return "", errors.New("not found")
+		}
+		return "/usr/local/bin/" + file, nil
+	}
+	m.VersionFunc = func(tool targets.Tool, _ string) (string, error) {
+		switch tool.Name {
+		case "outdated":
+			return "v0.9.0", nil
+		case "unverified":
+			return "", errors.New("no version")
+		}
+		return tool.Version, nil
+	}
+
+	statuses := m.Inspect(context.Background(), tools)
+	want := []ToolState{ToolPresent, ToolOutdated, ToolUnverified, ToolMissing}
+	for i, status := range statuses {
+		if status.State != want[i] {
+			t.Errorf("%s State = %s, want %s", status.Tool.Name, status.State, want[i])
+		}
+	}
+	if statuses[3].Path != "" {
+		t.Errorf("missing tool Path = %q, want empty", statuses[3].Path)
+	}
+
+	report := RenderToolReport(statuses)
+	for _, line := range []string{"✓ present", "! outdated", "? unverified", "✗ missing"} {
+		if !strings.Contains(report, line) {
+			t.Errorf("RenderToolReport() does not contain %q:\n%s", line, report)
+		}
+	}
+}
+
+func TestInstallToolsModule_Inspect_ProbesTheVersionFlag(t *testing.T) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\n[ \"$1\" = \"--version\" ] && echo \"swag version v1.16.4\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "swag"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	m := &InstallToolsModule{}
+	status := m.inspect(context.Background(), targets.Tool{Name: "swag", Version: "v1.16.4"})
+	if status.State != ToolPresent || status.Version != "v1.16.4" {
+		t.Errorf("inspect() = %s %q (%v), want present v1.16.4", status.State, status.Version, status.Err)
+	}
+}
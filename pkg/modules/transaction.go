@@ -0,0 +1,266 @@
+package modules
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// UndoAction
+//
+// description:
+//
+//	UndoAction reverts a single change made by a module, e.g. removing a directory
+//	it created or restoring a file it overwrote. Modules add them to their Result
+//	before making the change, and the runner only uses them when the project is
+//	created in transactional mode. Paths are made absolute when the action is
+//	created, so it does not depend on the working directory.
+type UndoAction struct {
+	Description string
+	Undo        func() error
+	// Discard runs instead of Undo when the change is kept, e.g. to remove a backup
+	// that Undo would have restored. It is optional.
+	Discard func() error
+}
+
+// addUndo appends the actions that have something to undo to the result
+func (r *Result) addUndo(actions ...UndoAction) {
+	for _, action := range actions {
+		if action.Undo != nil {
+			r.Undo = append(r.Undo, action)
+		}
+	}
+}
+
+// snapshotFile
+//
+// params:
+//
+//	name: string
+//
+// returns:
+//
+//	UndoAction: restores the current content of the file, or removes the file if it does not exist yet
+//
+// description:
+//
+//	Must be called before a file is written so that it can be put back exactly as it was
+func snapshotFile(name string) UndoAction {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return UndoAction{}
+	}
+	info, err := os.Stat(abs)
+	if errors.Is(err, os.ErrNotExist) {
+		return UndoAction{
+			Description: "remove " + abs,
+			Undo: func() error {
+				if err := os.Remove(abs); err != nil && !errors.Is(err, os.ErrNotExist) {
+					return err
+				}
+				return nil
+			},
+		}
+	}
+	if err != nil || info.IsDir() {
+		return UndoAction{}
+	}
+	previous, err := os.ReadFile(abs)
+	if err != nil {
+		return UndoAction{}
+	}
+	return UndoAction{
+		Description: "restore " + abs,
+		Undo: func() error {
+			return os.WriteFile(abs, previous, info.Mode().Perm())
+		},
+	}
+}
+
+// snapshotDirectory
+//
+// params:
+//
+//	dir: string
+//
+// returns:
+//
+//	UndoAction: removes the outermost directory of dir that does not exist yet, nothing if dir exists
+//
+// description:
+//
+//	Must be called before a directory is created. For "db/migrations" where "db" does not
+//	exist yet the whole "db" directory is removed, so nested directories leave nothing behind.
+//	The working directory is never changed, a directory that contains it is not removed
+//	and the undo returns an error instead (see checkNotWorkingDirectory).
+func snapshotDirectory(dir string) UndoAction {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return UndoAction{}
+	}
+	missing := ""
+	for current := abs; ; current = filepath.Dir(current) {
+		if _, err := os.Stat(current); err == nil {
+			break
+		}
+		missing = current
+		if filepath.Dir(current) == current {
+			break
+		}
+	}
+	if missing == "" {
+		return UndoAction{}
+	}
+	return UndoAction{
+		Description: "remove " + missing,
+		Undo: func() error {
+			if err := checkNotWorkingDirectory(missing); err != nil {
+				return err
+			}
+			return os.RemoveAll(missing)
+		},
+	}
+}
+
+// moveAside
+//
+// params:
+//
+//	dir: string
+//
+// returns:
+//
+//	UndoAction: puts dir back in place of whatever was created there, Discard removes it
+//	error:
+//	  - if the working directory is inside of dir (see checkNotWorkingDirectory)
+//	  - if dir could not be renamed, e.g. because a previous backup was left behind
+//
+// description:
+//
+//	Renames an existing directory to dir + PreviousSuffix next to it instead of removing
+//	it, so that a failed run can be rolled back to the old directory. Nothing is moved
+//	and nothing has to be undone when dir does not exist.
+func moveAside(dir string) (UndoAction, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return UndoAction{}, err
+	}
+	if _, err := os.Stat(abs); errors.Is(err, os.ErrNotExist) {
+		return UndoAction{}, nil
+	}
+	if err := checkNotWorkingDirectory(dir); err != nil {
+		return UndoAction{}, err
+	}
+	previous := abs + PreviousSuffix
+	if _, err := os.Stat(previous); err == nil {
+		return UndoAction{}, fmt.Errorf("%s is left from a previous run, remove it or move it back to %s", previous, dir)
+	}
+	if err := os.Rename(abs, previous); err != nil {
+		return UndoAction{}, fmt.Errorf("failed to move %s aside: %w", dir, err)
+	}
+	return UndoAction{
+		Description: "restore " + abs + " from " + previous,
+		Undo: func() error {
+			if err := os.RemoveAll(abs); err != nil {
+				return err
+			}
+			return os.Rename(previous, abs)
+		},
+		Discard: func() error {
+			return os.RemoveAll(previous)
+		},
+	}, nil
+}
+
+// checkNotWorkingDirectory returns an error when the working directory is dir or inside of it,
+// removing dir would leave the process in a directory that no longer exists
+func checkNotWorkingDirectory(dir string) error {
+	if wd, err := os.Getwd(); err == nil && isWithin(wd, absPath(dir)) {
+		return errors.New("refusing to remove " + dir + ", the working directory is inside of it")
+	}
+	return nil
+}
+
+// isWithin reports whether path is dir or inside of it
+func isWithin(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+// Transaction
+//
+// description:
+//
+//	Transaction collects the undo actions of every module that ran, so that a failed
+//	project can be rolled back to the state before `egg_cli init` started. It is safe
+//	to use from multiple goroutines.
+type Transaction struct {
+	mu      sync.Mutex
+	actions []UndoAction
+}
+
+// Add records the undo actions of a module result
+func (t *Transaction) Add(result Result) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.actions = append(t.actions, result.Undo...)
+}
+
+// Len returns how many actions would be undone by Rollback
+func (t *Transaction) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.actions)
+}
+
+// Rollback
+//
+// returns:
+//
+//	error: every action that could not be undone joined together
+//
+// description:
+//
+//	Runs every undo action in reverse order, so the last change is reverted first.
+//	A failing action does not stop the rest of the rollback.
+func (t *Transaction) Rollback() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var errs []error
+	for i := len(t.actions) - 1; i >= 0; i-- {
+		if err := t.actions[i].Undo(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", t.actions[i].Description, err))
+		}
+	}
+	t.actions = nil
+	return errors.Join(errs...)
+}
+
+// Commit
+//
+// returns:
+//
+//	error: every action that could not be discarded joined together
+//
+// description:
+//
+//	Keeps every change, the actions with a Discard (such as the backup of a replaced
+//	project directory) drop what they kept for the rollback. Nothing can be rolled
+//	back afterwards.
+func (t *Transaction) Commit() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var errs []error
+	for _, action := range t.actions {
+		if action.Discard == nil {
+			continue
+		}
+		if err := action.Discard(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", action.Description, err))
+		}
+	}
+	t.actions = nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,169 @@
+package modules
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshotFile_RestoresPreviousContent(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "go.mod")
+	if err := os.WriteFile(name, []byte("module before\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	undo := snapshotFile(name)
+	if err := os.WriteFile(name, []byte("module after\n"), 0644); err != nil {
+		t.Fatalf("Failed to overwrite file: %v", err)
+	}
+	if err := undo.Undo(); err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+
+	content, _ := os.ReadFile(name)
+	if string(content) != "module before\n" {
+		t.Errorf("Undo() restored %q, want %q", content, "module before\n")
+	}
+}
+
+func TestSnapshotFile_RemovesNewFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "main.go")
+	undo := snapshotFile(name)
+	if err := os.WriteFile(name, []byte("package main\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := undo.Undo(); err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Error("Undo() did not remove the new file")
+	}
+	// undoing twice is not an error
+	if err := undo.Undo(); err != nil {
+		t.Errorf("second Undo() error = %v", err)
+	}
+}
+
+func TestSnapshotDirectory_RemovesOutermostNewDirectory(t *testing.T) {
+	root := t.TempDir()
+	undo := snapshotDirectory(filepath.Join(root, "db", "migrations"))
+	if err := os.MkdirAll(filepath.Join(root, "db", "migrations"), 0755); err != nil {
+		t.Fatalf("Failed to create directories: %v", err)
+	}
+	if err := undo.Undo(); err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "db")); !os.IsNotExist(err) {
+		t.Error("Undo() did not remove the outermost new directory")
+	}
+	if _, err := os.Stat(root); err != nil {
+		t.Error("Undo() removed a directory that already existed")
+	}
+}
+
+func TestSnapshotDirectory_ExistingDirectory(t *testing.T) {
+	if undo := snapshotDirectory(t.TempDir()); undo.Undo != nil {
+		t.Error("snapshotDirectory() of an existing directory should have nothing to undo")
+	}
+}
+
+func TestSnapshotDirectory_RefusesWorkingDirectory(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "testproject")
+	undo := snapshotDirectory(project)
+	if err := os.Mkdir(project, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	t.Chdir(project)
+
+	if err := undo.Undo(); err == nil {
+		t.Fatal("Undo() error = nil, want a refusal to remove the working directory")
+	}
+	if _, err := os.Stat(project); err != nil {
+		t.Errorf("Undo() removed the working directory: %v", err)
+	}
+	if wd, _ := os.Getwd(); wd != project {
+		t.Errorf("working directory = %q after Undo(), want it unchanged %q", wd, project)
+	}
+}
+
+func TestMoveAside(t *testing.T) {
+	project := filepath.Join(t.TempDir(), "testproject")
+	if err := os.MkdirAll(project, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	undo, err := moveAside(project)
+	if err != nil {
+		t.Fatalf("moveAside() error = %v", err)
+	}
+	if _, err := os.Stat(project); !os.IsNotExist(err) {
+		t.Error("moveAside() left the directory in place")
+	}
+	// the new project is created in its place and rolled back
+	if err := os.MkdirAll(project, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := undo.Undo(); err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if content, err := os.ReadFile(filepath.Join(project, "main.go")); err != nil || string(content) != "package main\n" {
+		t.Errorf("main.go = %q, %v after Undo(), want the previous content", content, err)
+	}
+
+	undo, err = moveAside(project)
+	if err != nil {
+		t.Fatalf("moveAside() error = %v", err)
+	}
+	if _, err := moveAside(project + "-other"); err != nil {
+		t.Errorf("moveAside() of a missing directory error = %v", err)
+	}
+	if err := undo.Discard(); err != nil {
+		t.Fatalf("Discard() error = %v", err)
+	}
+	if _, err := os.Stat(project + PreviousSuffix); !os.IsNotExist(err) {
+		t.Error("Discard() kept the previous directory")
+	}
+}
+
+func TestTransaction_RollbackInReverseOrder(t *testing.T) {
+	var order []string
+	action := func(name string, err error) UndoAction {
+		return UndoAction{Description: name, Undo: func() error {
+			order = append(order, name)
+			return err
+		}}
+	}
+	transaction := new(Transaction)
+	transaction.Add(Result{Undo: []UndoAction{action("first", nil), action("second", errors.New("fail"))}})
+	transaction.Add(Result{Undo: []UndoAction{action("third", nil)}})
+
+	if transaction.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", transaction.Len())
+	}
+	err := transaction.Rollback()
+	if err == nil {
+		t.Error("Rollback() did not report the failed action")
+	}
+	want := []string{"third", "second", "first"}
+	for i := range want {
+		if i >= len(order) || order[i] != want[i] {
+			t.Fatalf("Rollback() order = %v, want %v", order, want)
+		}
+	}
+	if transaction.Len() != 0 {
+		t.Errorf("Len() = %d after Rollback(), want 0", transaction.Len())
+	}
+}
+
+func TestResult_AddUndoSkipsEmptyActions(t *testing.T) {
+	result := Result{}
+	result.addUndo(UndoAction{}, UndoAction{Description: "x", Undo: func() error { return nil }})
+	if len(result.Undo) != 1 {
+		t.Errorf("addUndo() kept %d actions, want 1", len(result.Undo))
+	}
+}
@@ -0,0 +1,57 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"runtime"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// maxWorkers bounds how many files or directories are written at the same time
+var maxWorkers = runtime.NumCPU()
+
+// forEachBounded
+//
+// params:
+//
+//	ctx: context.Context
+//	items: []string
+//	fn: func(item string) error
+//
+// returns:
+//
+//	error:
+//	  - every error returned by fn joined in the order of items
+//	  - the context error if ctx was cancelled before every item was started
+//
+// description:
+//
+//	Runs fn for every item with at most maxWorkers goroutines and blocks until all
+//	of them are done. A failing item does not stop the others, so that a single run
+//	reports every file or directory that could not be written instead of the first.
+func forEachBounded(ctx context.Context, items []string, fn func(item string) error) error {
+	g := new(errgroup.Group)
+	g.SetLimit(max(maxWorkers, 1))
+
+	// every worker only writes to its own index, so no lock is needed
+	errs := make([]error, len(items))
+	for i, item := range items {
+		if ctx.Err() != nil {
+			break
+		}
+		g.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				return nil
+			}
+			errs[i] = fn(item)
+			return nil
+		})
+	}
+	g.Wait()
+
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,54 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestForEachBounded_RespectsLimit(t *testing.T) {
+	previous := maxWorkers
+	maxWorkers = 3
+	defer func() { maxWorkers = previous }()
+
+	var running, peak atomic.Int32
+	items := make([]string, 20)
+	for i := range items {
+		items[i] = fmt.Sprintf("item%d", i)
+	}
+
+	err := forEachBounded(context.Background(), items, func(item string) error {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("forEachBounded() error = %v", err)
+	}
+	if peak.Load() > 3 {
+		t.Errorf("forEachBounded() ran %d workers at once, want at most 3", peak.Load())
+	}
+}
+
+func TestForEachBounded_JoinsErrorsInOrder(t *testing.T) {
+	items := []string{"a", "b", "c", "d"}
+	err := forEachBounded(context.Background(), items, func(item string) error {
+		if item == "b" || item == "d" {
+			return errors.New(item)
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "b\nd" {
+		t.Errorf("forEachBounded() error = %q, want %q", err, "b\nd")
+	}
+}
@@ -0,0 +1,164 @@
+package modules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// OverwritePolicy is what a run does when the project directory already has files in it
+type OverwritePolicy int
+
+const (
+	// OverwriteNever fails before anything is created, the default
+	OverwriteNever OverwritePolicy = iota
+	// OverwriteForce removes the project directory and creates the project from scratch
+	OverwriteForce
+	// OverwriteMerge keeps the project directory and creates the project inside of it
+	OverwriteMerge
+)
+
+func (p OverwritePolicy) String() string {
+	switch p {
+	case OverwriteForce:
+		return "force"
+	case OverwriteMerge:
+		return "merge"
+	default:
+		return "never"
+	}
+}
+
+// NewFileSuffix is appended to a rendered file that is written next to the existing one
+const NewFileSuffix = ".egg-new"
+
+// PreviousSuffix is appended to a project directory replaced with OverwriteForce until the run
+// is over, so that a rollback can put it back
+const PreviousSuffix = ".egg-old"
+
+// Resolution is what a merge does with a rendered file that differs from the file on disk
+type Resolution int
+
+const (
+	// ResolveAsk asks for every file that differs, the default
+	ResolveAsk Resolution = iota
+	// ResolveKeep keeps the file on disk
+	ResolveKeep
+	// ResolveOverwrite replaces the file on disk with the rendered one
+	ResolveOverwrite
+	// ResolveNew keeps the file on disk and writes the rendered one next to it with NewFileSuffix
+	ResolveNew
+)
+
+func (r Resolution) String() string {
+	switch r {
+	case ResolveKeep:
+		return "keep"
+	case ResolveOverwrite:
+		return "overwrite"
+	case ResolveNew:
+		return "new"
+	default:
+		return "ask"
+	}
+}
+
+// ParseResolution returns the resolution named by s, as printed by String
+func ParseResolution(s string) (Resolution, error) {
+	for _, resolution := range []Resolution{ResolveAsk, ResolveKeep, ResolveOverwrite, ResolveNew} {
+		if resolution.String() == s {
+			return resolution, nil
+		}
+	}
+	return ResolveAsk, fmt.Errorf("unknown conflict resolution %q, expected one of ask, keep, overwrite, new", s)
+}
+
+// Workspace
+//
+// description:
+//
+//	Workspace is the directory a run creates the project in. Every module reads and
+//	writes its files relative to Dir and runs its commands inside of it, so the
+//	working directory of the process is never changed and the pipeline can be run
+//	from tests or embedded in another program. An empty Dir is the working directory.
+type Workspace struct {
+	// Dir is the project directory, absolute so that it does not depend on the working directory
+	Dir string
+	// Overwrite is what happens when Dir already has files in it
+	Overwrite OverwritePolicy
+	// Conflicts is what a merge does with every rendered file that differs from the one on disk
+	Conflicts Resolution
+}
+
+// IWorkspace is implemented by modules that read or write the files of the project
+type IWorkspace interface {
+	SetWorkspace(workspace Workspace)
+}
+
+// projectWorkspace is embedded by the modules that implement IWorkspace
+type projectWorkspace struct {
+	workspace Workspace
+}
+
+func (w *projectWorkspace) SetWorkspace(workspace Workspace) {
+	w.workspace = workspace
+}
+
+// projectPath returns the path of name inside of the project directory
+func (w *projectWorkspace) projectPath(name string) string {
+	if w.workspace.Dir == "" {
+		return name
+	}
+	return filepath.Join(w.workspace.Dir, name)
+}
+
+// ProjectExistsError is returned when the project directory has files in it and
+// neither --force nor --merge was passed
+type ProjectExistsError struct {
+	Dir string
+}
+
+func (e *ProjectExistsError) Error() string {
+	return fmt.Sprintf("%s already exists and is not empty, pass --force to replace it or --merge to create the project inside of it", e.Dir)
+}
+
+// CheckWorkspace
+//
+// params:
+//
+//	workspace: Workspace
+//
+// returns:
+//
+//	error:
+//	  - a ProjectExistsError if Dir has files in it and the policy is OverwriteNever
+//	  - if Dir is a file, or could not be read
+//
+// description:
+//
+//	Verifies that the project can be created in the workspace. An empty directory is
+//	used as if it did not exist, so that it can be created up front (e.g. a volume).
+func CheckWorkspace(workspace Workspace) error {
+	dir := workspace.Dir
+	if dir == "" {
+		dir = "."
+	}
+	info, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s already exists and is not a directory", dir)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	if len(entries) > 0 && workspace.Overwrite == OverwriteNever {
+		return &ProjectExistsError{Dir: dir}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckWorkspace(t *testing.T) {
+	empty := t.TempDir()
+	full := t.TempDir()
+	if err := os.WriteFile(filepath.Join(full, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(full, "main.go")
+
+	tests := []struct {
+		name       string
+		workspace  Workspace
+		wantErr    bool
+		wantExists bool
+	}{
+		{"missing", Workspace{Dir: filepath.Join(empty, "app")}, false, false},
+		{"empty", Workspace{Dir: empty}, false, false},
+		{"not empty", Workspace{Dir: full}, true, true},
+		{"not empty with force", Workspace{Dir: full, Overwrite: OverwriteForce}, false, false},
+		{"not empty with merge", Workspace{Dir: full, Overwrite: OverwriteMerge}, false, false},
+		{"a file", Workspace{Dir: file, Overwrite: OverwriteMerge}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckWorkspace(tt.workspace)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckWorkspace() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var existsErr *ProjectExistsError
+			if errors.As(err, &existsErr) != tt.wantExists {
+				t.Errorf("CheckWorkspace() error = %v, want a ProjectExistsError: %v", err, tt.wantExists)
+			}
+		})
+	}
+}
+
+func TestWorkspace_ModulesWriteIntoTheProjectDirectory(t *testing.T) {
+	wd := t.TempDir()
+	t.Chdir(wd)
+	dir := t.TempDir()
+	logger := createTestLogger(t)
+	defer logger.Close()
+	config := createTestConfiguration()
+
+	directories := &BootstrapDirectoriesModule{}
+	directories.LoadFromConfig(config, logger)
+	directories.SetWorkspace(Workspace{Dir: dir})
+	generate := &GenerateConfigurationModule{}
+	generate.LoadFromConfig(config, logger)
+	generate.SetWorkspace(Workspace{Dir: dir})
+	for _, module := range []IModule{directories, generate} {
+		if _, err := module.Run(context.Background()); err != nil {
+			t.Fatalf("%s Run() error = %v", module.Name(), err)
+		}
+	}
+
+	for _, want := range []string{"controllers", config.Database.QueriesLocation, "config/development.yaml"} {
+		if _, err := os.Stat(filepath.Join(dir, want)); err != nil {
+			t.Errorf("%s was not created in the project directory: %v", want, err)
+		}
+		if _, err := os.Stat(filepath.Join(wd, want)); err == nil {
+			t.Errorf("%s was created in the working directory", want)
+		}
+	}
+}
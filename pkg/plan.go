@@ -0,0 +1,107 @@
+package pkg
+
+import (
+	"strings"
+
+	"github.com/adamkali/egg_cli/pkg/configuration"
+	"github.com/adamkali/egg_cli/pkg/models"
+	"github.com/adamkali/egg_cli/pkg/modules"
+	"github.com/adamkali/egg_cli/pkg/templates"
+)
+
+// PlanFactory
+//
+// params:
+//
+//	configuration: *configuration.Configuration
+//	eggl: *models.EggLog
+//	offline: modules.Offline
+//	workspace: modules.Workspace
+//	templateDir: string
+//
+// returns:
+//
+//	[]modules.Plan: what every registered module would do, in execution order
+//	error:
+//	  - if the modules could not be ordered by their dependencies
+//	  - if a template of templateDir can not be parsed
+//
+// description:
+//
+//	The dry run counterpart of ProjectFactory. Every module is loaded from the
+//	configuration exactly like a real run, but only Describe() is called so
+//	nothing is written to disk and no command is executed.
+func PlanFactory(
+	configuration *configuration.Configuration,
+	eggl *models.EggLog,
+	offline modules.Offline,
+	workspace modules.Workspace,
+	templateDir string,
+) ([]modules.Plan, error) {
+	pipeline, err := Registry.Modules()
+	if err != nil {
+		return nil, err
+	}
+	workspace, err = projectWorkspace(workspace, configuration.Name)
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := templates.Load(configuration, templateDir)
+	if err != nil {
+		return nil, err
+	}
+	plans := make([]modules.Plan, 0, len(pipeline))
+	for _, module := range pipeline {
+		module.LoadFromConfig(configuration, eggl)
+		if o, ok := module.(modules.IOffline); ok {
+			o.SetOffline(offline)
+		}
+		if w, ok := module.(modules.IWorkspace); ok {
+			w.SetWorkspace(workspace)
+		}
+		if t, ok := module.(modules.ITemplates); ok {
+			t.SetTemplates(mapping)
+		}
+		plans = append(plans, module.Describe())
+	}
+	return plans, nil
+}
+
+// RenderPlan renders the plans as a tree with one branch per module
+func RenderPlan(projectName string, plans []modules.Plan) string {
+	var builder strings.Builder
+	builder.WriteString("🥚 " + projectName + "\n")
+	for i, plan := range plans {
+		lastModule := i == len(plans)-1
+		builder.WriteString(branch(lastModule) + plan.Module + "\n")
+		moduleIndent := indent(lastModule)
+		if plan.IsEmpty() {
+			builder.WriteString(moduleIndent + branch(true) + "nothing to do\n")
+			continue
+		}
+		sections := plan.Sections()
+		for j, section := range sections {
+			lastSection := j == len(sections)-1
+			builder.WriteString(moduleIndent + branch(lastSection) + section.Title + "\n")
+			sectionIndent := moduleIndent + indent(lastSection)
+			for k, item := range section.Items {
+				builder.WriteString(sectionIndent + branch(k == len(section.Items)-1) + item + "\n")
+			}
+		}
+	}
+	return builder.String()
+}
+
+func branch(last bool) string {
+	if last {
+		return "└── "
+	}
+	return "├── "
+}
+
+func indent(last bool) string {
+	if last {
+		return "    "
+	}
+	return "│   "
+}
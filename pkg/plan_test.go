@@ -0,0 +1,110 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/adamkali/egg_cli/pkg/configuration"
+	"github.com/adamkali/egg_cli/pkg/models"
+	"github.com/adamkali/egg_cli/pkg/modules"
+)
+
+func TestPlanFactory(t *testing.T) {
+	dir := t.TempDir()
+	logger, err := models.NewLogger(filepath.Join(dir, "test.log"))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+
+	config := new(configuration.Configuration)
+	config.Namespace = "github.com/testuser/testproject"
+	config.Name = "testproject"
+	config.Database.SqlcRepositoryLocation = "db/repository"
+	config.Database.QueriesLocation = "db/queries"
+	config.Database.Migration.Destination = "db/migrations"
+
+	plans, err := PlanFactory(config, logger, modules.Offline{}, modules.Workspace{}, "")
+	if err != nil {
+		t.Fatalf("PlanFactory() error = %v", err)
+	}
+	ordered, err := Registry.Modules()
+	if err != nil {
+		t.Fatalf("Modules() error = %v", err)
+	}
+	names := moduleNames(ordered)
+	if len(plans) != len(names) {
+		t.Fatalf("PlanFactory() returned %d plans, want %d", len(plans), len(names))
+	}
+	for i, plan := range plans {
+		if plan.Module != names[i] {
+			t.Errorf("plan %d is for %q, want %q", i, plan.Module, names[i])
+		}
+	}
+	if _, err := os.Stat(config.Name); !os.IsNotExist(err) {
+		t.Errorf("PlanFactory() must not create the project directory")
+	}
+}
+
+func TestPlanFactory_Templates(t *testing.T) {
+	dir := t.TempDir()
+	logger, err := models.NewLogger(filepath.Join(dir, "test.log"))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Close()
+	config := &configuration.Configuration{Name: "testproject", Namespace: "github.com/testuser/testproject"}
+
+	templateDir := filepath.Join(dir, "templates")
+	if err := os.MkdirAll(filepath.Join(templateDir, ".github"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(templateDir, ".github", "ci.yml.tmpl"), []byte("name: {{.Name}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	plans, err := PlanFactory(config, logger, modules.Offline{}, modules.Workspace{}, templateDir)
+	if err != nil {
+		t.Fatalf("PlanFactory() error = %v", err)
+	}
+	found := false
+	for _, plan := range plans {
+		if plan.Module == modules.BootstrapFrameworkModuleID {
+			found = slices.Contains(plan.Files, ".github/ci.yml")
+		}
+	}
+	if !found {
+		t.Error("the plan of egg::bootstrap_framework does not render the template of the template directory")
+	}
+
+	if err := os.WriteFile(filepath.Join(templateDir, "broken.go.tmpl"), []byte("{{.Name"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := PlanFactory(config, logger, modules.Offline{}, modules.Workspace{}, templateDir); err == nil {
+		t.Error("PlanFactory() did not fail for a template that does not parse")
+	}
+}
+
+func TestRenderPlan(t *testing.T) {
+	plans := []modules.Plan{
+		{Module: "egg::first", Directories: []string{"a", "b"}, Commands: []string{"go version"}},
+		{Module: "egg::second"},
+	}
+	want := strings.Join([]string{
+		"🥚 testproject",
+		"├── egg::first",
+		"│   ├── directories",
+		"│   │   ├── a",
+		"│   │   └── b",
+		"│   └── commands",
+		"│       └── go version",
+		"└── egg::second",
+		"    └── nothing to do",
+		"",
+	}, "\n")
+	if got := RenderPlan("testproject", plans); got != want {
+		t.Errorf("RenderPlan() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,308 @@
+package pkg
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adamkali/egg_cli/pkg/models"
+	"github.com/adamkali/egg_cli/pkg/modules"
+	"github.com/adamkali/egg_cli/styles"
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// progressTailLines is how many of the latest log lines are shown per running module
+const progressTailLines = 3
+
+const progressRefresh = 100 * time.Millisecond
+
+type moduleStatus int
+
+const (
+	statusPending moduleStatus = iota
+	statusRunning
+	statusSucceeded
+	statusFailed
+)
+
+func (s moduleStatus) icon() string {
+	switch s {
+	case statusRunning:
+		return "◐"
+	case statusSucceeded:
+		return "✓"
+	case statusFailed:
+		return "✗"
+	default:
+		return "○"
+	}
+}
+
+// progressState is updated by the runner while the modules run and read by the
+// progress view on every refresh
+type progressState struct {
+	mu         sync.Mutex
+	pipeline   []modules.IModule
+	index      map[string]int
+	statuses   []moduleStatus
+	errs       []error
+	tails      [][]string
+	cancelling bool
+}
+
+func newProgressState(pipeline []modules.IModule) *progressState {
+	state := &progressState{
+		pipeline: pipeline,
+		index:    make(map[string]int, len(pipeline)),
+		statuses: make([]moduleStatus, len(pipeline)),
+		errs:     make([]error, len(pipeline)),
+		tails:    make([][]string, len(pipeline)),
+	}
+	for i, module := range pipeline {
+		state.index[module.Name()] = i
+	}
+	return state
+}
+
+func (s *progressState) start(module modules.IModule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statuses[s.index[module.Name()]] = statusRunning
+}
+
+func (s *progressState) finish(module modules.IModule, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.index[module.Name()]
+	s.statuses[i] = statusSucceeded
+	if err != nil {
+		s.statuses[i] = statusFailed
+		s.errs[i] = err
+	}
+}
+
+// log keeps the latest lines a module logged
+func (s *progressState) log(module modules.IModule, line string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.index[module.Name()]
+	for _, part := range strings.Split(strings.TrimSpace(line), "\n") {
+		s.tails[i] = append(s.tails[i], part)
+	}
+	if len(s.tails[i]) > progressTailLines {
+		s.tails[i] = s.tails[i][len(s.tails[i])-progressTailLines:]
+	}
+}
+
+// failed reports whether the module finished with an error
+func (s *progressState) failed(module modules.IModule) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.statuses[s.index[module.Name()]] == statusFailed
+}
+
+func (s *progressState) cancel() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cancelling = true
+}
+
+// moduleRow is what the progress view shows for a single module
+type moduleRow struct {
+	name     string
+	status   moduleStatus
+	progress float64
+	err      error
+	tail     []string
+}
+
+func (s *progressState) rows() ([]moduleRow, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rows := make([]moduleRow, len(s.pipeline))
+	for i, module := range s.pipeline {
+		row := moduleRow{
+			name:   module.Name(),
+			status: s.statuses[i],
+			err:    s.errs[i],
+			tail:   append([]string(nil), s.tails[i]...),
+		}
+		switch {
+		case row.status == statusSucceeded:
+			// not every module counts the steps it skipped
+			row.progress = 1
+		case row.status == statusPending:
+		default:
+			if reporter, ok := module.(modules.IProgress); ok {
+				row.progress = min(max(reporter.GetProgress(), 0), 1)
+			}
+		}
+		rows[i] = row
+	}
+	return rows, s.cancelling
+}
+
+type progressTickMsg time.Time
+
+// progressDoneMsg is sent by the runner once every module has finished
+type progressDoneMsg struct{}
+
+// progressModel
+//
+// description:
+//
+//	The bubbletea model of the progress view shown by `egg_cli init`. Every module
+//	has a bar fed by its GetProgress() and the running or failed modules show the
+//	latest lines they logged. ctrl+c cancels the run, the view stays open until the
+//	running modules have stopped.
+type progressModel struct {
+	title  string
+	state  *progressState
+	cancel context.CancelFunc
+	bar    progress.Model
+	done   bool
+}
+
+func newProgressModel(title string, state *progressState, cancel context.CancelFunc) progressModel {
+	return progressModel{
+		title:  title,
+		state:  state,
+		cancel: cancel,
+		bar:    progress.New(progress.WithDefaultGradient(), progress.WithWidth(30)),
+	}
+}
+
+func progressTick() tea.Cmd {
+	return tea.Tick(progressRefresh, func(t time.Time) tea.Msg {
+		return progressTickMsg(t)
+	})
+}
+
+func (m progressModel) Init() tea.Cmd {
+	return progressTick()
+}
+
+func (m progressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case progressTickMsg:
+		if m.done {
+			return m, nil
+		}
+		return m, progressTick()
+	case progressDoneMsg:
+		m.done = true
+		return m, tea.Quit
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			m.state.cancel()
+			m.cancel()
+		}
+	}
+	return m, nil
+}
+
+var (
+	progressNameStyle = lipgloss.NewStyle().Width(32)
+	progressTailStyle = lipgloss.NewStyle().Foreground(styles.HintColor).Faint(true).PaddingLeft(4)
+)
+
+func (m progressModel) View() string {
+	rows, cancelling := m.state.rows()
+	var builder strings.Builder
+	builder.WriteString(styles.EggProgressTitle.Render("🥚 "+m.title) + "\n\n")
+	for _, row := range rows {
+		line := fmt.Sprintf("%s %s %s", row.status.icon(), progressNameStyle.Render(row.name), m.bar.ViewAs(row.progress))
+		builder.WriteString(line + "\n")
+		switch row.status {
+		case statusFailed:
+			builder.WriteString(progressTailStyle.Render(styles.EggProgressError.Render(row.err.Error())) + "\n")
+		case statusRunning:
+			for _, tail := range row.tail {
+				builder.WriteString(progressTailStyle.Render(tail) + "\n")
+			}
+		}
+	}
+	if cancelling && !m.done {
+		builder.WriteString("\n" + styles.EggProgressInfo.Render("🥚 cancelling, waiting for the running modules to stop") + "\n")
+	}
+	return builder.String()
+}
+
+// progressView runs the progress model while the modules of a pipeline run. The
+// modules print every step, so stdout is redirected into the log file while the
+// view owns the terminal.
+type progressView struct {
+	title         string
+	state         *progressState
+	cancel        context.CancelFunc
+	eggl          *models.EggLog
+	program       *tea.Program
+	done          chan struct{}
+	restoreStdout func()
+}
+
+func (v *progressView) start() {
+	stdout := os.Stdout
+	v.restoreStdout = redirectStdout(v.eggl)
+	v.program = tea.NewProgram(
+		newProgressModel(v.title, v.state, v.cancel),
+		tea.WithOutput(stdout),
+		// ctrl+c is handled by the model, and SIGTERM by the context of the command
+		tea.WithoutSignalHandler(),
+	)
+	v.done = make(chan struct{})
+	go func() {
+		defer close(v.done)
+		if _, err := v.program.Run(); err != nil {
+			v.eggl.Error("error: progress view: %s", err.Error())
+		}
+	}()
+}
+
+// stop renders the final state of every module and gives the terminal back
+func (v *progressView) stop() {
+	v.program.Send(progressDoneMsg{})
+	<-v.done
+	v.restoreStdout()
+}
+
+// exclusive hands the terminal to an interactive module while it runs
+func (v *progressView) exclusive(run func()) {
+	v.stop()
+	run()
+	v.start()
+}
+
+// redirectStdout writes everything printed to stdout into the log file until the
+// returned function is called
+func redirectStdout(eggl *models.EggLog) func() {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return func() {}
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(ansi.Strip(scanner.Text())); line != "" {
+				eggl.Info("%s", line)
+			}
+		}
+	}()
+	return func() {
+		os.Stdout = stdout
+		w.Close()
+		<-done
+		r.Close()
+	}
+}
@@ -0,0 +1,144 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/adamkali/egg_cli/pkg/configuration"
+	"github.com/adamkali/egg_cli/pkg/models"
+	"github.com/adamkali/egg_cli/pkg/modules"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// progressModule reports a fixed progress
+type progressModule struct {
+	blockingModule
+	progress float64
+}
+
+func (m *progressModule) GetProgress() float64 { return m.progress }
+
+func TestProgressState_Rows(t *testing.T) {
+	running := &progressModule{blockingModule: blockingModule{name: "egg::running"}, progress: 0.5}
+	done := &progressModule{blockingModule: blockingModule{name: "egg::done"}, progress: 0.25}
+	failed := &progressModule{blockingModule: blockingModule{name: "egg::failed"}, progress: 3}
+	pending := &progressModule{blockingModule: blockingModule{name: "egg::pending"}, progress: 0.75}
+	state := newProgressState([]modules.IModule{running, done, failed, pending})
+
+	state.start(running)
+	state.start(done)
+	state.finish(done, nil)
+	state.start(failed)
+	state.finish(failed, errors.New("simulated error"))
+
+	rows, cancelling := state.rows()
+	if cancelling {
+		t.Error("rows() reports cancelling before cancel()")
+	}
+	want := []struct {
+		status   moduleStatus
+		progress float64
+	}{
+		{statusRunning, 0.5},
+		// a finished module is always shown as complete
+		{statusSucceeded, 1},
+		// progress is clamped to the bar
+		{statusFailed, 1},
+		{statusPending, 0},
+	}
+	for i, row := range rows {
+		if row.status != want[i].status || row.progress != want[i].progress {
+			t.Errorf("row %s = %v %v, want %v %v", row.name, row.status, row.progress, want[i].status, want[i].progress)
+		}
+	}
+	if !state.failed(failed) || state.failed(done) {
+		t.Error("failed() does not match the finished modules")
+	}
+}
+
+func TestProgressState_KeepsTheLatestLogLines(t *testing.T) {
+	module := &blockingModule{name: "egg::tools"}
+	state := newProgressState([]modules.IModule{module})
+	for _, line := range []string{"one", "two", "three\nfour", "five"} {
+		state.log(module, line)
+	}
+	rows, _ := state.rows()
+	if got, want := strings.Join(rows[0].tail, ","), "three,four,five"; got != want {
+		t.Errorf("tail = %s, want %s", got, want)
+	}
+}
+
+func TestProgressModel_View(t *testing.T) {
+	tools := &blockingModule{name: "egg::install_tools"}
+	libraries := &blockingModule{name: "egg::install_libraries"}
+	state := newProgressState([]modules.IModule{tools, libraries})
+	state.start(tools)
+	state.log(tools, "installing github.com/air-verse/air")
+	state.start(libraries)
+	state.finish(libraries, errors.New("go get failed"))
+
+	view := newProgressModel("egg_app", state, func() {}).View()
+	for _, want := range []string{"egg_app", "egg::install_tools", "egg::install_libraries", "installing github.com/air-verse/air", "go get failed"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() does not contain %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestProgressModel_Update(t *testing.T) {
+	state := newProgressState(nil)
+	cancelled := false
+	var model tea.Model = newProgressModel("egg_app", state, func() { cancelled = true })
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	if !cancelled {
+		t.Error("ctrl+c did not cancel the run")
+	}
+	if !strings.Contains(model.View(), "cancelling") {
+		t.Error("View() does not show that the run is cancelling")
+	}
+
+	model, cmd := model.Update(progressDoneMsg{})
+	if cmd == nil {
+		t.Fatal("Update(progressDoneMsg) did not quit")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("Update(progressDoneMsg) did not quit")
+	}
+	if _, cmd := model.Update(progressTickMsg{}); cmd != nil {
+		t.Error("the view kept refreshing after it was done")
+	}
+}
+
+func TestExecutePipeline_TailsModuleLogs(t *testing.T) {
+	logger := setupFakeModules(t)
+	module := &loggingModule{name: "egg::logging"}
+	state := newProgressState([]modules.IModule{module})
+	loadModule(module, new(configuration.Configuration), logger.WithTail(func(line string) { state.log(module, line) }), nil)
+
+	if _, err := runModule(context.Background(), module); err != nil {
+		t.Fatalf("runModule() error = %v", err)
+	}
+	rows, _ := state.rows()
+	if len(rows[0].tail) != 1 || rows[0].tail[0] != "egg::logging did something" {
+		t.Errorf("tail = %v, want the logged line", rows[0].tail)
+	}
+}
+
+// loggingModule logs a single line when it runs
+type loggingModule struct {
+	name string
+	eggl *models.EggLog
+}
+
+func (m *loggingModule) Name() string { return m.name }
+func (m *loggingModule) LoadFromConfig(_ *configuration.Configuration, eggl *models.EggLog) {
+	m.eggl = eggl
+}
+func (m *loggingModule) Describe() modules.Plan { return modules.Plan{Module: m.name} }
+func (m *loggingModule) Run(context.Context) (modules.Result, error) {
+	m.eggl.Info("%s did something", m.name)
+	return modules.Result{Module: m.name}, nil
+}
@@ -0,0 +1,133 @@
+package pkg
+
+import (
+	"context"
+
+	"github.com/adamkali/egg_cli/pkg/modules"
+)
+
+// schedule
+//
+// params:
+//
+//	ctx: context.Context
+//	pipeline: []modules.IModule
+//	dependsOn: func(name string) []string
+//	exclusive: func(run func())
+//	run: func(module modules.IModule) error
+//
+// returns:
+//
+//	modules.IModule: the module that failed first, nil if every module succeeded
+//	error:
+//	  - the error of the module that failed first
+//	  - the context error if ctx was cancelled before every module was started
+//
+// description:
+//
+//	Runs the modules of the pipeline as a graph: a module starts as soon as every
+//	module it depends on has succeeded, so modules that do not depend on each other
+//	run at the same time. Dependencies that are not part of the pipeline (e.g. when
+//	recovering a single module) count as done. Interactive modules only start once
+//	nothing else is running and are run through exclusive, so that they can use the
+//	terminal. After the first failure no new module is started, but the modules that
+//	are already running are waited for so that their results are not lost.
+func schedule(
+	ctx context.Context,
+	pipeline []modules.IModule,
+	dependsOn func(name string) []string,
+	exclusive func(run func()),
+	run func(module modules.IModule) error,
+) (modules.IModule, error) {
+	index := make(map[string]int, len(pipeline))
+	for i, module := range pipeline {
+		index[module.Name()] = i
+	}
+	// waitingFor[i] are the modules of the pipeline that module i depends on
+	waitingFor := make([][]int, len(pipeline))
+	for i, module := range pipeline {
+		for _, dependency := range dependsOn(module.Name()) {
+			if j, ok := index[dependency]; ok {
+				waitingFor[i] = append(waitingFor[i], j)
+			}
+		}
+	}
+
+	type completion struct {
+		index int
+		err   error
+	}
+	completions := make(chan completion)
+	started := make([]bool, len(pipeline))
+	succeeded := make([]bool, len(pipeline))
+	running := 0
+
+	var failed modules.IModule
+	var failedErr error
+	complete := func(c completion) {
+		if c.err != nil {
+			if failed == nil {
+				failed, failedErr = pipeline[c.index], c.err
+			}
+			return
+		}
+		succeeded[c.index] = true
+	}
+	ready := func(i int) bool {
+		for _, j := range waitingFor[i] {
+			if !succeeded[j] {
+				return false
+			}
+		}
+		return true
+	}
+
+	for {
+		if failed == nil && ctx.Err() == nil {
+			startedInteractive := false
+			for i, module := range pipeline {
+				if started[i] || !ready(i) {
+					continue
+				}
+				if interactive, ok := module.(modules.IInteractive); ok && interactive.Interactive() {
+					// wait for the running modules, and start nothing else in the meantime
+					if running > 0 {
+						break
+					}
+					started[i] = true
+					var err error
+					exclusive(func() { err = run(module) })
+					complete(completion{index: i, err: err})
+					startedInteractive = true
+					break
+				}
+				started[i] = true
+				running++
+				go func() {
+					completions <- completion{index: i, err: run(module)}
+				}()
+			}
+			if startedInteractive {
+				// its dependents may be ready now
+				continue
+			}
+		}
+		if running == 0 {
+			break
+		}
+		running--
+		complete(<-completions)
+	}
+
+	if failed != nil {
+		return failed, failedErr
+	}
+	if err := ctx.Err(); err != nil {
+		for i, module := range pipeline {
+			if !succeeded[i] {
+				return module, err
+			}
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,211 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adamkali/egg_cli/pkg/configuration"
+	"github.com/adamkali/egg_cli/pkg/models"
+	"github.com/adamkali/egg_cli/pkg/modules"
+)
+
+// blockingModule waits on its gate before it finishes, so tests control when it is done
+type blockingModule struct {
+	name        string
+	gate        chan struct{}
+	err         error
+	interactive bool
+}
+
+func (m *blockingModule) Name() string                                                { return m.name }
+func (m *blockingModule) LoadFromConfig(*configuration.Configuration, *models.EggLog) {}
+func (m *blockingModule) Describe() modules.Plan                                      { return modules.Plan{Module: m.name} }
+func (m *blockingModule) Interactive() bool                                           { return m.interactive }
+func (m *blockingModule) Run(ctx context.Context) (modules.Result, error) {
+	if m.gate != nil {
+		<-m.gate
+	}
+	return modules.Result{Module: m.name}, m.err
+}
+
+// graph returns a dependsOn function for a fixed set of dependencies
+func graph(dependencies map[string][]string) func(string) []string {
+	return func(name string) []string { return dependencies[name] }
+}
+
+func runExclusive(run func()) { run() }
+
+// recorder records the order in which the modules start and finish
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) run(module modules.IModule) error {
+	r.record("start " + module.Name())
+	_, err := module.Run(context.Background())
+	r.record("finish " + module.Name())
+	return err
+}
+
+func (r *recorder) record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func TestSchedule_RunsIndependentModulesConcurrently(t *testing.T) {
+	tools := &blockingModule{name: "egg::tools", gate: make(chan struct{})}
+	libraries := &blockingModule{name: "egg::libraries", gate: make(chan struct{})}
+	started := make(chan string, 2)
+
+	done := make(chan error)
+	go func() {
+		_, err := schedule(context.Background(), []modules.IModule{tools, libraries}, graph(nil), runExclusive,
+			func(module modules.IModule) error {
+				started <- module.Name()
+				_, err := module.Run(context.Background())
+				return err
+			})
+		done <- err
+	}()
+
+	// both have to be running before either is allowed to finish
+	for range 2 {
+		select {
+		case <-started:
+		case <-time.After(5 * time.Second):
+			t.Fatal("independent modules did not run at the same time")
+		}
+	}
+	close(tools.gate)
+	close(libraries.gate)
+	if err := <-done; err != nil {
+		t.Fatalf("schedule() error = %v", err)
+	}
+}
+
+func TestSchedule_WaitsForDependencies(t *testing.T) {
+	pipeline := []modules.IModule{
+		&blockingModule{name: "egg::initialize"},
+		&blockingModule{name: "egg::directories"},
+		&blockingModule{name: "egg::framework"},
+	}
+	dependencies := graph(map[string][]string{
+		"egg::directories": {"egg::initialize"},
+		"egg::framework":   {"egg::directories"},
+	})
+
+	r := new(recorder)
+	if _, err := schedule(context.Background(), pipeline, dependencies, runExclusive, r.run); err != nil {
+		t.Fatalf("schedule() error = %v", err)
+	}
+	want := []string{
+		"start egg::initialize", "finish egg::initialize",
+		"start egg::directories", "finish egg::directories",
+		"start egg::framework", "finish egg::framework",
+	}
+	if !reflect.DeepEqual(r.events, want) {
+		t.Errorf("events = %v, want %v", r.events, want)
+	}
+}
+
+func TestSchedule_StopsAfterFailure(t *testing.T) {
+	slow := &blockingModule{name: "egg::slow", gate: make(chan struct{})}
+	failing := &blockingModule{name: "egg::failing", err: errors.New("simulated error")}
+	dependent := &blockingModule{name: "egg::dependent"}
+	pipeline := []modules.IModule{slow, failing, dependent}
+	dependencies := graph(map[string][]string{"egg::dependent": {"egg::failing"}})
+
+	r := new(recorder)
+	go func() {
+		// the slow module only finishes after the failing one did
+		for {
+			r.mu.Lock()
+			failed := len(r.events) > 0 && r.events[len(r.events)-1] == "finish egg::failing"
+			r.mu.Unlock()
+			if failed {
+				close(slow.gate)
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	failed, err := schedule(context.Background(), pipeline, dependencies, runExclusive, r.run)
+	if failed != failing || err == nil {
+		t.Fatalf("schedule() = %v, %v, want the failing module", failed, err)
+	}
+	for _, event := range r.events {
+		if event == "start egg::dependent" {
+			t.Error("schedule() started a module after a failure")
+		}
+	}
+	if r.events[len(r.events)-1] != "finish egg::slow" {
+		t.Errorf("schedule() did not wait for the running module, events = %v", r.events)
+	}
+}
+
+func TestSchedule_RunsInteractiveModulesAlone(t *testing.T) {
+	slow := &blockingModule{name: "egg::slow", gate: make(chan struct{})}
+	prompt := &blockingModule{name: "egg::prompt", interactive: true}
+	after := &blockingModule{name: "egg::after"}
+	pipeline := []modules.IModule{slow, prompt, after}
+
+	exclusiveRuns := 0
+	exclusive := func(run func()) {
+		exclusiveRuns++
+		run()
+	}
+	r := new(recorder)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(slow.gate)
+	}()
+	if _, err := schedule(context.Background(), pipeline, graph(nil), exclusive, r.run); err != nil {
+		t.Fatalf("schedule() error = %v", err)
+	}
+	want := []string{
+		"start egg::slow", "finish egg::slow",
+		"start egg::prompt", "finish egg::prompt",
+		"start egg::after", "finish egg::after",
+	}
+	if !reflect.DeepEqual(r.events, want) {
+		t.Errorf("events = %v, want %v", r.events, want)
+	}
+	if exclusiveRuns != 1 {
+		t.Errorf("exclusive was used %d times, want 1", exclusiveRuns)
+	}
+}
+
+func TestSchedule_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	pipeline := []modules.IModule{&blockingModule{name: "egg::first"}}
+
+	r := new(recorder)
+	failed, err := schedule(ctx, pipeline, graph(nil), runExclusive, r.run)
+	if !errors.Is(err, context.Canceled) || failed != pipeline[0] {
+		t.Errorf("schedule() = %v, %v, want the first module and context.Canceled", failed, err)
+	}
+	if len(r.events) != 0 {
+		t.Errorf("schedule() started modules after it was cancelled: %v", r.events)
+	}
+}
+
+func TestSchedule_IgnoresDependenciesOutsideThePipeline(t *testing.T) {
+	pipeline := []modules.IModule{&blockingModule{name: "egg::framework"}}
+	dependencies := graph(map[string][]string{"egg::framework": {"egg::directories"}})
+
+	r := new(recorder)
+	if _, err := schedule(context.Background(), pipeline, dependencies, runExclusive, r.run); err != nil {
+		t.Fatalf("schedule() error = %v", err)
+	}
+	if len(r.events) != 2 {
+		t.Errorf("events = %v, want the module to run", r.events)
+	}
+}
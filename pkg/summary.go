@@ -0,0 +1,65 @@
+package pkg
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adamkali/egg_cli/pkg/modules"
+)
+
+// RenderSummary
+//
+// params:
+//
+//	results: []modules.Result
+//
+// returns:
+//
+//	string: one line per module with its duration and what it created and ran
+//
+// description:
+//
+//	Printed by the runner after every run, including failed and cancelled ones,
+//	so that it is clear how far the pipeline got and where the time went.
+func RenderSummary(results []modules.Result) string {
+	width := len("total")
+	for _, result := range results {
+		width = max(width, len(result.Module))
+	}
+
+	var builder strings.Builder
+	var total time.Duration
+	builder.WriteString("🥚 summary\n")
+	for _, result := range results {
+		total += result.Duration
+		failed := 0
+		for _, command := range result.Commands {
+			if command.Error != nil {
+				failed++
+			}
+		}
+		line := fmt.Sprintf(
+			"%-*s %8s  %s, %s, %s",
+			width,
+			result.Module,
+			result.Duration.Round(time.Millisecond),
+			plural(len(result.Directories), "directory", "directories"),
+			plural(len(result.Files), "file", "files"),
+			plural(len(result.Commands), "command", "commands"),
+		)
+		if failed > 0 {
+			line += fmt.Sprintf(" (%d failed)", failed)
+		}
+		builder.WriteString(line + "\n")
+	}
+	builder.WriteString(fmt.Sprintf("%-*s %8s\n", width, "total", total.Round(time.Millisecond)))
+	return builder.String()
+}
+
+func plural(n int, singular, plural string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, singular)
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
@@ -0,0 +1,43 @@
+package pkg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adamkali/egg_cli/pkg/modules"
+)
+
+func TestRenderSummary(t *testing.T) {
+	results := []modules.Result{
+		{
+			Module:      "egg::initialize",
+			Directories: []string{"testproject"},
+			Files:       []string{"go.mod"},
+			Commands: []modules.CommandResult{
+				{Command: "go version"},
+				{Command: "go mod init github.com/testuser/testproject"},
+			},
+			Duration: 1500 * time.Millisecond,
+		},
+		{
+			Module:   "egg::install_libraries",
+			Commands: []modules.CommandResult{{Command: "go get github.com/spf13/cobra", Error: errors.New("fail")}},
+			Duration: 500 * time.Millisecond,
+		},
+	}
+
+	summary := RenderSummary(results)
+	for _, want := range []string{
+		"egg::initialize",
+		"1 directory, 1 file, 2 commands",
+		"0 directories, 0 files, 1 command (1 failed)",
+		"total",
+		"2s",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("RenderSummary() does not contain %q:\n%s", want, summary)
+		}
+	}
+}
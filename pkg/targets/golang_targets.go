@@ -1,32 +1,38 @@
 package targets
 
+// Module is a go module the generated project requires
+type Module struct {
+	Path    string
+	Version string
+}
+
+// String returns the module as path@version
+func (m Module) String() string {
+	return m.Path + "@" + m.Version
+}
 
 var (
-	// the packagesPackages that are required for the fullstack_app (to the best of my knowledge)
-	// this will be tested later to make sure that everything still works
-	GolangPackages = []string{
-		"github.com/labstack/echo",
-		"github.com/spf13/cobra",
-		"gopkg.in/yaml.v3",
-		"github.com/go-openapi/jsonpointer",
-		"github.com/go-openapi/jsonreference",
-		"github.com/go-openapi/spec",
-		"github.com/go-openapi/swag",
-		"github.com/golang-jwt/jwt/v5",
-		"github.com/labstack/echo-jwt/v4",
-		"github.com/golang-jwt/jwt",
-		"github.com/labstack/echo/v4",
-		"github.com/joho/godotenv",
-		"github.com/minio/crc64nvme",
-		"github.com/minio/md5-simd",
-		"github.com/minio/minio-go/v7",
-		"github.com/pkg/errors",
-		"github.com/swaggo/echo-swagger",
-		"github.com/swaggo/files/v2",
-		"github.com/swaggo/swag",
-		"github.com/google/uuid",
-		"github.com/jackc/pgx",
-		"github.com/jackc/pgx/v5",
-		"github.com/redis/go-redis/v9",
+	// the modules that the templates of the fullstack_app import, pinned so that
+	// every project starts from the same go.mod. `go mod tidy` adds their
+	// dependencies (e.g. swaggo/files for echo-swagger) to go.sum. The templates
+	// are checked against this list, see templates.Dependencies.
+	GolangModules = []Module{
+		{Path: "github.com/labstack/echo/v4", Version: "v4.13.3"},
+		{Path: "github.com/labstack/echo-jwt/v4", Version: "v4.3.1"},
+		{Path: "github.com/golang-jwt/jwt/v5", Version: "v5.2.2"},
+		{Path: "github.com/google/uuid", Version: "v1.6.0"},
+		{Path: "github.com/spf13/cobra", Version: "v1.9.1"},
+		{Path: "gopkg.in/yaml.v3", Version: "v3.0.1"},
+		{Path: "github.com/jackc/pgx/v5", Version: "v5.7.5"},
+		{Path: "github.com/redis/go-redis/v9", Version: "v9.8.0"},
+		{Path: "github.com/minio/minio-go/v7", Version: "v7.0.91"},
+		{Path: "github.com/swaggo/echo-swagger", Version: "v1.4.1"},
+		{Path: "golang.org/x/crypto", Version: "v0.38.0"},
+	}
+
+	// the modules that no template imports, but the code the tools generate into
+	// the project does (`swag init` writes docs/docs.go, which imports swag)
+	GeneratedModules = []Module{
+		{Path: "github.com/swaggo/swag", Version: "v1.16.4"},
 	}
 )
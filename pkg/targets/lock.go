@@ -0,0 +1,82 @@
+package targets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LockFileName is the versions manifest written into every project
+const LockFileName = "egg.lock"
+
+const lockHeader = "# egg.lock pins the versions of the tools this project is built with.\n" +
+	"# `egg_cli init` and `egg_cli recover` install exactly these versions, commit this file.\n"
+
+// Lock is the content of egg.lock
+type Lock struct {
+	Tools []Tool `yaml:"tools"`
+}
+
+// DefaultLock returns the lock with the versions egg_cli was released with
+func DefaultLock() Lock {
+	return Lock{Tools: append([]Tool(nil), RequiredTools...)}
+}
+
+// LoadLock
+//
+// params:
+//
+//	path: string
+//
+// returns:
+//
+//	Lock: the lock stored in path, or DefaultLock if path does not exist
+//	error: if the file could not be read or parsed
+//
+// description:
+//
+//	Required tools that are missing from the file are added with their default
+//	version, so an egg.lock written by an older egg_cli still lists every tool.
+func LoadLock(path string) (Lock, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return DefaultLock(), nil
+	}
+	if err != nil {
+		return Lock{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var lock Lock
+	if err := yaml.Unmarshal(content, &lock); err != nil {
+		return Lock{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for _, required := range RequiredTools {
+		if _, ok := lock.Tool(required.Name); !ok {
+			lock.Tools = append(lock.Tools, required)
+		}
+	}
+	return lock, nil
+}
+
+// Tool returns the pinned tool with the given binary name
+func (l Lock) Tool(name string) (Tool, bool) {
+	for _, tool := range l.Tools {
+		if tool.Name == name {
+			return tool, true
+		}
+	}
+	return Tool{}, false
+}
+
+// Write writes the lock to path
+func (l Lock) Write(path string) error {
+	content, err := yaml.Marshal(l)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, append([]byte(lockHeader), content...), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
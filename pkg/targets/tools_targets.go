@@ -1,16 +1,53 @@
 package targets
 
+import "strings"
+
+// Tool is a command line tool that the fullstack_app needs, installed with `go install`
+type Tool struct {
+	// Name is the name of the installed binary
+	Name string `yaml:"name"`
+	// Package is the main package that is installed
+	Package string `yaml:"package"`
+	// Version is the version pinned in egg.lock
+	Version string `yaml:"version"`
+}
+
+// Target returns the argument for `go install`
+func (t Tool) Target() string {
+	return t.Package + "@" + t.Version
+}
+
+// ModulePath returns the module the tool is installed from, e.g. github.com/swaggo/swag for swag
+func (t Tool) ModulePath() string {
+	path, _, _ := strings.Cut(t.Package, "/cmd/")
+	return path
+}
+
+// versionArgs are the arguments that make a tool print its version, "--version" if it is not listed
+var versionArgs = map[string][]string{
+	"air":  {"-v"},
+	"sqlc": {"version"},
+}
+
+// VersionArgs returns the arguments that make the tool print its version
+func (t Tool) VersionArgs() []string {
+	if args, ok := versionArgs[t.Name]; ok {
+		return args
+	}
+	return []string{"--version"}
+}
 
 var (
-	// the tools that are required for the fullstack_app
+	// the tools that are required for the fullstack_app, pinned so that every
+	// project (and every teammate) generates the same code with them
 	// air   -> hot reloading
-	// swag  -> generating docs and frontend/api endpoint connections 
+	// swag  -> generating docs and frontend/api endpoint connections
 	// goose -> migrations
 	// sqlc  -> generating queries
-	RequiredTools = []string{
-		"github.com/air-verse/air@latest",
-		"github.com/swaggo/swag/cmd/swag@latest",
-		"github.com/pressly/goose/v3/cmd/goose@latest",
-		"github.com/sqlc-dev/sqlc/cmd/sqlc@latest",
+	RequiredTools = []Tool{
+		{Name: "air", Package: "github.com/air-verse/air", Version: "v1.61.7"},
+		{Name: "swag", Package: "github.com/swaggo/swag/cmd/swag", Version: "v1.16.4"},
+		{Name: "goose", Package: "github.com/pressly/goose/v3/cmd/goose", Version: "v3.24.3"},
+		{Name: "sqlc", Package: "github.com/sqlc-dev/sqlc/cmd/sqlc", Version: "v1.29.0"},
 	}
 )
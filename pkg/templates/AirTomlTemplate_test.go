@@ -66,7 +66,7 @@ tmp_dir = "tmp"
 func TestAirTomlTemplate(t *testing.T) {
 	// load the template 
 	temp := templates.AirTomlTemplate
-	templateTest := template.Must(template.New("air.toml").Parse(temp))
+	templateTest := template.Must(template.New("air.toml").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
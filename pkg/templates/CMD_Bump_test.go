@@ -135,7 +135,7 @@ func bump() {
 func TestBumpCmdTemplate(t *testing.T) {
 	// load the template
 	temp := templates.BumpCmdTemplate
-	templateTest := template.Must(template.New("bump.go").Parse(temp))
+	templateTest := template.Must(template.New("bump.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
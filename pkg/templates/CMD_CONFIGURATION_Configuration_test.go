@@ -110,7 +110,7 @@ func (configuration *Configuration) GenerateConfigurationFile(environment string
 func TestCmdConfigurationConfigurationTemplate(t *testing.T) {
 	// load the template
 	temp := templates.CmdConfigurationConfigurationTemplate
-	templateTest := template.Must(template.New("configuration.go").Parse(temp))
+	templateTest := template.Must(template.New("configuration.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
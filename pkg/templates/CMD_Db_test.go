@@ -47,7 +47,7 @@ func init() {
 func TestDBCmdTemplate(t *testing.T) {
 	// load the template
 	temp := templates.DBCmdTemplate
-	templateTest := template.Must(template.New("db.go").Parse(temp))
+	templateTest := template.Must(template.New("db.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
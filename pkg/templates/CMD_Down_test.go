@@ -97,7 +97,7 @@ func Down(configuration *configuration.Configuration, args []string) error {
 func TestDownCmdTemplate(t *testing.T) {
 	// load the template
 	temp := templates.DownCmdTemplate
-	templateTest := template.Must(template.New("down.go").Parse(temp))
+	templateTest := template.Must(template.New("down.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
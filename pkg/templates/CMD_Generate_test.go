@@ -94,7 +94,7 @@ func Gen(configuration *configuration.Configuration, args []string) error {
 func TestGenerateCmdTemplate(t *testing.T) {
 	// load the template
 	temp := templates.GenerateCmdTemplate
-	templateTest := template.Must(template.New("generate.go").Parse(temp))
+	templateTest := template.Must(template.New("generate.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
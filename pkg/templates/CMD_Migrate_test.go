@@ -107,7 +107,7 @@ func Migrate(configuration *configuration.Configuration, args []string) error {
 func TestMigrateCmdTemplate(t *testing.T) {
 	// load the template
 	temp := templates.MigrateCmdTemplate
-	templateTest := template.Must(template.New("migrate.go").Parse(temp))
+	templateTest := template.Must(template.New("migrate.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
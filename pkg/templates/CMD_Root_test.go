@@ -135,7 +135,7 @@ func serve(config *configuration.Configuration) {
 func TestRootCmdTemplate(t *testing.T) {
 	// load the template
 	temp := templates.RootCmdTemplate
-	templateTest := template.Must(template.New("root.go").Parse(temp))
+	templateTest := template.Must(template.New("root.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
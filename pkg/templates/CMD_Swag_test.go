@@ -105,7 +105,7 @@ func swag() error {
 func TestSwagCmdTemplate(t *testing.T) {
 	// load the template
 	temp := templates.SwagCmdTemplate
-	templateTest := template.Must(template.New("swag.go").Parse(temp))
+	templateTest := template.Must(template.New("swag.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
@@ -110,7 +110,7 @@ func Up(configuration *configuration.Configuration, args []string) error {
 func TestUpCmdTemplate(t *testing.T) {
 	// load the template
 	temp := templates.UpCmdTemplate
-	templateTest := template.Must(template.New("up.go").Parse(temp))
+	templateTest := template.Must(template.New("up.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
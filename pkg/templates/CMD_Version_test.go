@@ -30,7 +30,7 @@ import (
 	"fmt"
 
 	"github.com/adamkali/egg/cmd/configuration"
-	"github.com/labstack/echo"
+	"github.com/labstack/echo/v4"
 	"github.com/spf13/cobra"
 )
 
@@ -87,7 +87,7 @@ func init() {
 func TestVersionCmdTemplate(t *testing.T) {
 	// load the template
 	temp := templates.VersionCmdTemplate
-	templateTest := template.Must(template.New("version.go").Parse(temp))
+	templateTest := template.Must(template.New("version.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
@@ -17,7 +17,7 @@ import (
 	"context"
 
 	"github.com/adamkali/egg/cmd/configuration"
-	"github.com/adamkali/egg/middlwares/configs"
+	"github.com/adamkali/egg/middlewares/configs"
 	"github.com/adamkali/egg/services"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/labstack/echo/v4"
@@ -65,7 +65,7 @@ func AttatchControllers(e *echo.Echo, config *configuration.Configuration, conts
 func TestCONTROLLER_ControllerTemplate(t *testing.T) {
 	// load the template
 	temp := templates.CONTROLLER_ControllerTemplate
-	templateTest := template.Must(template.New("controller.go").Parse(temp))
+	templateTest := template.Must(template.New("controller.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
@@ -17,7 +17,7 @@ import (
 	"net/http"
 
 	"github.com/adamkali/egg/cmd/configuration"
-	"github.com/adamkali/egg/middlwares/configs"
+	"github.com/adamkali/egg/middlewares/configs"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	echoSwagger "github.com/swaggo/echo-swagger"
@@ -52,7 +52,7 @@ func RegisterRoutes(e *echo.Echo, config *configuration.Configuration) {
 func TestCONTROLLER_RoutesTemplate(t *testing.T) {
 	// load the template
 	temp := templates.CONTROLLER_RoutesTemplate
-	templateTest := template.Must(template.New("routes.go").Parse(temp))
+	templateTest := template.Must(template.New("routes.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
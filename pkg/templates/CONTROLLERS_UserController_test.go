@@ -209,7 +209,7 @@ func (uc UserController) Attatch(e *echo.Echo, authMiddleware echo.MiddlewareFun
 
 func TestCONTROLLERS_UserControllerTemplate(t *testing.T) {
 	temp := templates.CONTROLLERS_UserControllerTemplate
-	templateTest := template.Must(template.New("user_controller.go").Parse(temp))
+	templateTest := template.Must(template.New("user_controller.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	stringWriter := new(bytes.Buffer)
 	err := templateTest.ExecuteTemplate(stringWriter, "user_controller.go", createConfiguration())
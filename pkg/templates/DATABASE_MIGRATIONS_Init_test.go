@@ -40,7 +40,7 @@ DROP TABLE users;
 
 func TestDATABASE_MIGRATIONS_INITTemplate(t *testing.T) {
 	temp := templates.DATABASE_MIGRATIONS_INITTemplate
-	templateTest := template.Must(template.New("init_migration.sql").Parse(temp))
+	templateTest := template.Must(template.New("init_migration.sql").Funcs(templates.FuncMap()).Parse(temp))
 
 	stringWriter := new(bytes.Buffer)
 	err := templateTest.ExecuteTemplate(stringWriter, "init_migration.sql", createConfiguration())
@@ -38,7 +38,7 @@ RETURNING *;
 
 func TestDATABASE_QUERIES_TokenTemplate(t *testing.T) {
 	temp := templates.DATABASE_QUERIES_TokenTemplate
-	templateTest := template.Must(template.New("token_queries.sql").Parse(temp))
+	templateTest := template.Must(template.New("token_queries.sql").Funcs(templates.FuncMap()).Parse(temp))
 
 	stringWriter := new(bytes.Buffer)
 	err := templateTest.ExecuteTemplate(stringWriter, "token_queries.sql", createConfiguration())
@@ -60,7 +60,7 @@ WHERE id = $2;
 
 func TestDATABASE_QUERIES_UserTemplate(t *testing.T) {
 	temp := templates.DATABASE_QUERIES_UserTemplate
-	templateTest := template.Must(template.New("user_queries.sql").Parse(temp))
+	templateTest := template.Must(template.New("user_queries.sql").Funcs(templates.FuncMap()).Parse(temp))
 
 	stringWriter := new(bytes.Buffer)
 	err := templateTest.ExecuteTemplate(stringWriter, "user_queries.sql", createConfiguration())
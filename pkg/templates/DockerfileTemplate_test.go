@@ -43,7 +43,7 @@ CMD ["/app/egg", "-e", "production"]
 func TestDockerfileTemplate(t *testing.T) {
 	// load the template
 	temp := templates.DockerfileTemplate
-	templateTest := template.Must(template.New("Dockerfile").Parse(temp))
+	templateTest := template.Must(template.New("Dockerfile").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
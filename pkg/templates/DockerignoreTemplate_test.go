@@ -52,7 +52,7 @@ tmp/
 func TestDockerignoreTemplate(t *testing.T) {
 	// load the template
 	temp := templates.DockerignoreTemplate
-	templateTest := template.Must(template.New(".dockerignore").Parse(temp))
+	templateTest := template.Must(template.New(".dockerignore").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
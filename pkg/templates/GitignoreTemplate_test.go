@@ -46,7 +46,7 @@ node_modules/
 func TestGitignoreTemplate(t *testing.T) {
 	// load the template
 	temp := templates.GitignoreTemplate
-	templateTest := template.Must(template.New(".gitignore").Parse(temp))
+	templateTest := template.Must(template.New(".gitignore").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
@@ -53,7 +53,7 @@ func AuthMiddlewareConfig(config *configuration.Configuration) echojwt.Config {
 func TestMIDDLEWARES_CONFIGS_AuthConfigTemplate(t *testing.T) {
 	// load the template
 	temp := templates.MIDDLEWARES_CONFIGS_AuthConfigTemplate
-	templateTest := template.Must(template.New("auth_config.go").Parse(temp))
+	templateTest := template.Must(template.New("auth_config.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
@@ -42,7 +42,7 @@ func StaticMiddlewareConfig(config *configuration.Configuration) middleware.Stat
 func TestMIDDLEWARES_CONFIGS_StaticConfigTemplate(t *testing.T) {
 	// load the template
 	temp := templates.MIDDLEWARES_CONFIGS_StaticConfigTemplate
-	templateTest := template.Must(template.New("static_config.go").Parse(temp))
+	templateTest := template.Must(template.New("static_config.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
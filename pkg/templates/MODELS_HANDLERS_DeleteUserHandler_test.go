@@ -16,7 +16,7 @@ package handlers
 import (
 	"fmt"
 
-	"github.com/adamkali/egg/internal/repository"
+	"github.com/adamkali/egg/db/repository"
 	"github.com/adamkali/egg/models/responses"
 	"github.com/adamkali/egg/services"
 	"github.com/golang-jwt/jwt/v5"
@@ -121,7 +121,7 @@ func (h *DeleteUserHandler) JSON() error {
 func TestMODELS_HANDLERS_DeleteUserHandlerTemplate(t *testing.T) {
 	// load the template
 	temp := templates.MODELS_HANDLERS_DeleteUserHandlerTemplate
-	templateTest := template.Must(template.New("delete_user_handler.go").Parse(temp))
+	templateTest := template.Must(template.New("delete_user_handler.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
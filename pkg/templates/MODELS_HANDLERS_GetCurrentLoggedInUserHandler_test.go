@@ -14,7 +14,7 @@ package handlers
 import (
 	"fmt"
 
-	"github.com/adamkali/egg/internal/repository"
+	"github.com/adamkali/egg/db/repository"
 	"github.com/adamkali/egg/models/responses"
 	"github.com/adamkali/egg/services"
 	"github.com/golang-jwt/jwt/v5"
@@ -98,7 +98,7 @@ func (h *GetCurrentLoggedInUserHandler) JSON() error {
 func TestMODELS_HANDLERS_GetCurrentLoggedInUserHandlerTemplate(t *testing.T) {
 	// load the template
 	temp := templates.MODELS_HANDLERS_GetCurrentLoggedInUserHandlerTemplate
-	templateTest := template.Must(template.New("get_current_logged_in_user_handler.go").Parse(temp))
+	templateTest := template.Must(template.New("get_current_logged_in_user_handler.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
@@ -137,7 +137,7 @@ func (h *GetProfilePictureHandler) JSON() error {
 func TestMODELS_HANDLERS_GetUsersHandlerTemplate(t *testing.T) {
 	// load the template
 	temp := templates.MODELS_HANDLERS_GetUsersHandlerTemplate
-	templateTest := template.Must(template.New("get_users_handler.go").Parse(temp))
+	templateTest := template.Must(template.New("get_users_handler.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
@@ -16,7 +16,7 @@ package handlers
 import (
 	"fmt"
 
-	"github.com/adamkali/egg/internal/repository"
+	"github.com/adamkali/egg/db/repository"
 	"github.com/adamkali/egg/models/requests"
 	"github.com/adamkali/egg/models/responses"
 	"github.com/labstack/echo/v4"
@@ -113,7 +113,7 @@ func (h *LoginHandler) Render() error {
 func TestMODELS_HANDLERS_LoginHandlerTemplate(t *testing.T) {
 	// load the template
 	temp := templates.MODELS_HANDLERS_LoginHandlerTemplate
-	templateTest := template.Must(template.New("login_handler.go").Parse(temp))
+	templateTest := template.Must(template.New("login_handler.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
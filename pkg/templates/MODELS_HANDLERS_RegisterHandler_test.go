@@ -16,7 +16,7 @@ package handlers
 import (
 	"fmt"
 
-	"github.com/adamkali/egg/internal/repository"
+	"github.com/adamkali/egg/db/repository"
 	"github.com/adamkali/egg/models/requests"
 	"github.com/adamkali/egg/models/responses"
 	"github.com/labstack/echo/v4"
@@ -97,7 +97,7 @@ func (h *RegisterHandler) JSON() error {
 func TestMODELS_HANDLERS_RegisterHandlerTemplate(t *testing.T) {
 	// load the template
 	temp := templates.MODELS_HANDLERS_RegisterHandlerTemplate
-	templateTest := template.Must(template.New("register_handler.go").Parse(temp))
+	templateTest := template.Must(template.New("register_handler.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
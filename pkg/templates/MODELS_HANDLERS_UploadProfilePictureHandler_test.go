@@ -18,7 +18,7 @@ import (
 	"io"
 	"mime/multipart"
 
-	"github.com/adamkali/egg/internal/repository"
+	"github.com/adamkali/egg/db/repository"
 	"github.com/adamkali/egg/models/responses"
 	"github.com/adamkali/egg/services"
 	"github.com/golang-jwt/jwt/v5"
@@ -121,7 +121,7 @@ func (h *UploadProfilePictureHandler) JSON() error {
 func TestMODELS_HANDLERS_UploadProfilePictureHandlerTemplate(t *testing.T) {
 	// load the template
 	temp := templates.MODELS_HANDLERS_UploadProfilePictureHandlerTemplate
-	templateTest := template.Must(template.New("upload_profile_picture_handler.go").Parse(temp))
+	templateTest := template.Must(template.New("upload_profile_picture_handler.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
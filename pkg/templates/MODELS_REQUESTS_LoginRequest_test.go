@@ -24,7 +24,7 @@ type LoginRequest struct {
 func TestMODELS_REQUESTS_LoginRequestTemplate(t *testing.T) {
 	// load the template
 	temp := templates.MODELS_REQUESTS_LoginRequestTemplate
-	templateTest := template.Must(template.New("login_request.go").Parse(temp))
+	templateTest := template.Must(template.New("login_request.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
@@ -25,7 +25,7 @@ type NewUserRequest struct {
 func TestMODELS_REQUESTS_NewUserRequestTemplate(t *testing.T) {
 	// load the template
 	temp := templates.MODELS_REQUESTS_NewUserRequestTemplate
-	templateTest := template.Must(template.New("new_user_request.go").Parse(temp))
+	templateTest := template.Must(template.New("new_user_request.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
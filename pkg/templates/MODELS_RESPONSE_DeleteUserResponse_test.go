@@ -12,7 +12,7 @@ const ResultMODELS_RESPONSE_DeleteUserResponseTemplate = `
 package responses
 
 import (
-	"github.com/adamkali/egg/internal/repository"
+	"github.com/adamkali/egg/db/repository"
 )
 
 type DashboardResponse struct {
@@ -30,7 +30,7 @@ type DashboardDetailedResponse struct {
 func TestMODELS_RESPONSE_DeleteUserResponseTemplate(t *testing.T) {
 	// load the template
 	temp := templates.MODELS_RESPONSE_DeleteUserResponseTemplate
-	templateTest := template.Must(template.New("delete_user_response.go").Parse(temp))
+	templateTest := template.Must(template.New("delete_user_response.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
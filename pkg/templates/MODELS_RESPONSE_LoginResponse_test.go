@@ -14,7 +14,7 @@ const ResultMODELS_RESPONSE_LoginResponseTemplate = `
 package responses
 
 import (
-	"github.com/adamkali/egg/internal/repository"
+	"github.com/adamkali/egg/db/repository"
 	"github.com/labstack/echo/v4"
 )
 
@@ -58,7 +58,7 @@ func (LoginResponse *LoginResponse) Handle(
 func TestMODELS_RESPONSE_LoginResponseTemplate(t *testing.T) {
 	// load the template
 	temp := templates.MODELS_RESPONSE_LoginResponseTemplate
-	templateTest := template.Must(template.New("login_response.go").Parse(temp))
+	templateTest := template.Must(template.New("login_response.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
@@ -43,7 +43,7 @@ func (StringResponse *StringResponse) Successful(ctx echo.Context, stringLike st
 func TestMODELS_RESPONSE_StringResponseTemplate(t *testing.T) {
 	// load the template
 	temp := templates.MODELS_RESPONSE_StringResponseTemplate
-	templateTest := template.Must(template.New("string_response.go").Parse(temp))
+	templateTest := template.Must(template.New("string_response.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
@@ -17,7 +17,7 @@ import (
 	"errors"
 	"time"
 
-	"github.com/adamkali/egg/internal/repository"
+	"github.com/adamkali/egg/db/repository"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
@@ -81,7 +81,7 @@ func (ur *UserResponse) Component(
 func TestMODELS_RESPONSE_UserResponseTemplate(t *testing.T) {
 	// load the template
 	temp := templates.MODELS_RESPONSE_UserResponseTemplate
-	templateTest := template.Must(template.New("user_response.go").Parse(temp))
+	templateTest := template.Must(template.New("user_response.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
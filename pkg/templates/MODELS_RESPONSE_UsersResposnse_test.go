@@ -14,7 +14,7 @@ const ResultMODELS_RESPONSE_UsersResponseTemplate = `
 package responses
 
 import (
-	"github.com/adamkali/egg/internal/repository"
+	"github.com/adamkali/egg/db/repository"
 	"github.com/labstack/echo/v4"
 )
 
@@ -47,7 +47,7 @@ func (UsersResponse *UsersResponse) Successful(ctx echo.Context, users []reposit
 func TestMODELS_RESPONSE_UsersResponseTemplate(t *testing.T) {
 	// load the template
 	temp := templates.MODELS_RESPONSE_UsersResponseTemplate
-	templateTest := template.Must(template.New("users_response.go").Parse(temp))
+	templateTest := template.Must(template.New("users_response.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
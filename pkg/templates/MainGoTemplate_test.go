@@ -36,7 +36,7 @@ func main() {
 func TestMainGoTemplate(t *testing.T) {
 	// load the template
 	temp := templates.MainGoTemplate
-	templateTest := template.Must(template.New("main.go").Parse(temp))
+	templateTest := template.Must(template.New("main.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
@@ -25,7 +25,7 @@ build: build-backend build-swagger build-frontend
 func TestMakefileTemplate(t *testing.T) {
 	// load the template
 	temp := templates.MakefileTemplate
-	templateTest := template.Must(template.New("Makefile").Parse(temp))
+	templateTest := template.Must(template.New("Makefile").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
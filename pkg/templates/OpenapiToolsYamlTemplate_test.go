@@ -20,7 +20,7 @@ const ResultOpenapiToolsJSONTemplate = `
 func TestOpenapiToolsJSONTemplate(t *testing.T) {
 	// load the template
 	temp := templates.OpenapitoolsJSONTemplate
-	templateTest := template.Must(template.New("openapitools.json").Parse(temp))
+	templateTest := template.Must(template.New("openapitools.json").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
@@ -42,7 +42,7 @@ You can change the default port by going into the config/ directory and changing
 func TestREADMETemplate(t *testing.T) {
 	// load the template
 	temp := templates.READMETemplate
-	templateTest := template.Must(template.New("README.md").Parse(temp))
+	templateTest := template.Must(template.New("README.md").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
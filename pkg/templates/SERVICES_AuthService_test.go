@@ -19,7 +19,7 @@ import (
 	"time"
 
 	"github.com/adamkali/egg/cmd/configuration"
-	"github.com/adamkali/egg/internal/repository"
+	"github.com/adamkali/egg/db/repository"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -192,7 +192,7 @@ func (a *AuthService) Update(user repository.User) (*string, error) {
 func TestSERVICES_AuthServiceTemplate(t *testing.T) {
 	// load the template
 	temp := templates.SERVICES_AuthServiceTemplate
-	templateTest := template.Must(template.New("auth_service.go").Parse(temp))
+	templateTest := template.Must(template.New("auth_service.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
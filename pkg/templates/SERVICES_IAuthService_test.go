@@ -14,7 +14,7 @@ const ResultSERVICES_IAuthServiceTemplate = `
 package services
 
 import (
-	"github.com/adamkali/egg/internal/repository"
+	"github.com/adamkali/egg/db/repository"
 )
 
 
@@ -28,7 +28,7 @@ type IAuthService interface {
 func TestSERVICES_IAuthServiceTemplate(t *testing.T) {
 	// load the template
 	temp := templates.SERVICES_IAuthServiceTemplate
-	templateTest := template.Must(template.New("i_auth_service.go").Parse(temp))
+	templateTest := template.Must(template.New("i_auth_service.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
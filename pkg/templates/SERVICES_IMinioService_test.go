@@ -29,7 +29,7 @@ type IMinioService interface {
 func TestSERVICES_IMinioServiceTemplate(t *testing.T) {
 	// load the template
 	temp := templates.SERVICES_IMinioServiceTemplate
-	templateTest := template.Must(template.New("i_minio_service.go").Parse(temp))
+	templateTest := template.Must(template.New("i_minio_service.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
@@ -27,7 +27,7 @@ type IRedisService interface {
 func TestSERVICES_IRedisServiceTemplate(t *testing.T) {
 	// load the template
 	temp := templates.SERVICES_IRedisServiceTemplate
-	templateTest := template.Must(template.New("i_redis_service.go").Parse(temp))
+	templateTest := template.Must(template.New("i_redis_service.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
@@ -14,7 +14,7 @@ const ResultSERVICES_IUserServiceTemplate = `
 package services
 
 import (
-	"github.com/adamkali/egg/internal/repository"
+	"github.com/adamkali/egg/db/repository"
 	"github.com/adamkali/egg/models/requests"
 	"github.com/google/uuid"
 )
@@ -64,7 +64,7 @@ type IUserService interface {
 func TestSERVICES_IUserServiceTemplate(t *testing.T) {
 	// load the template
 	temp := templates.SERVICES_IUserServiceTemplate
-	templateTest := template.Must(template.New("i_user_service.go").Parse(temp))
+	templateTest := template.Must(template.New("i_user_service.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
@@ -126,7 +126,7 @@ func (m *MinioService) GetPresigned(uploaderID uuid.UUID, uploadName string) (st
 func TestSERVICES_MinioServiceTemplate(t *testing.T) {
 	// load the template
 	temp := templates.SERVICES_MinioServiceTemplate
-	templateTest := template.Must(template.New("minio_service.go").Parse(temp))
+	templateTest := template.Must(template.New("minio_service.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
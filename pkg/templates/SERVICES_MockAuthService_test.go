@@ -17,7 +17,7 @@ import (
 	"context"
 	//"time"
 
-	"github.com/adamkali/egg/internal/repository"
+	"github.com/adamkali/egg/db/repository"
 )
 
 // AuthService provides authentication services, including creating and checking tokens.
@@ -55,7 +55,7 @@ func (MockAuthService *MockAuthService) CheckToken(token string) error {
 func TestSERVICES_MockAuthServiceTemplate(t *testing.T) {
 	// load the template
 	temp := templates.SERVICES_MockAuthServiceTemplate
-	templateTest := template.Must(template.New("mock_auth_service.go").Parse(temp))
+	templateTest := template.Must(template.New("mock_auth_service.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
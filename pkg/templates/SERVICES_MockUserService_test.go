@@ -16,7 +16,7 @@ package services
 import (
 	"context"
 
-	"github.com/adamkali/egg/internal/repository"
+	"github.com/adamkali/egg/db/repository"
 	"github.com/adamkali/egg/models/requests"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -107,7 +107,7 @@ func (service *MockUserService) Remove(id uuid.UUID) error {
 func TestSERVICES_MockUserServiceTemplate(t *testing.T) {
 	// load the template
 	temp := templates.SERVICES_MockUserServiceTemplate
-	templateTest := template.Must(template.New("mock_user_service.go").Parse(temp))
+	templateTest := template.Must(template.New("mock_user_service.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
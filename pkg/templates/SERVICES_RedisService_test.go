@@ -131,7 +131,7 @@ func (r *RedisService) Delete(key string) error {
 func TestSERVICES_RedisServiceTemplate(t *testing.T) {
 	// load the template
 	temp := templates.SERVICES_RedisServiceTemplate
-	templateTest := template.Must(template.New("redis_service.go").Parse(temp))
+	templateTest := template.Must(template.New("redis_service.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
@@ -17,7 +17,7 @@ import (
 	"context"
 	"errors"
 
-	"github.com/adamkali/egg/internal/repository"
+	"github.com/adamkali/egg/db/repository"
 	"github.com/adamkali/egg/models/requests"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -272,7 +272,7 @@ func (UserService *UserService) Update(user_id uuid.UUID, profile_name string) (
 func TestSERVICES_UserServiceTemplate(t *testing.T) {
 	// load the template
 	temp := templates.SERVICES_UserServiceTemplate
-	templateTest := template.Must(template.New("user_service.go").Parse(temp))
+	templateTest := template.Must(template.New("user_service.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
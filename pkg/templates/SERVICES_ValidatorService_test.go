@@ -136,7 +136,7 @@ func (vs  ValidatorService ) ValidateLoginFormRequest(e echo.Context) (*requests
 func TestSERVICES_ValidatorServiceTemplate(t *testing.T) {
 	// load the template
 	temp := templates.SERVICES_ValidatorServiceTemplate
-	templateTest := template.Must(template.New("validator_service.go").Parse(temp))
+	templateTest := template.Must(template.New("validator_service.go").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
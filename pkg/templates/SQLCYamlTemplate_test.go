@@ -51,7 +51,7 @@ sql:
 func TestSQLCYamlTemplate(t *testing.T) {
 	// load the template
 	temp := templates.SQLCYamlTemplate
-	templateTest := template.Must(template.New("sqlc.yaml").Parse(temp))
+	templateTest := template.Must(template.New("sqlc.yaml").Funcs(templates.FuncMap()).Parse(temp))
 
 	// execute the template
 	stringWriter := new(bytes.Buffer)
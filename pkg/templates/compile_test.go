@@ -0,0 +1,299 @@
+package templates_test
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adamkali/egg_cli/pkg/configuration"
+	"github.com/adamkali/egg_cli/pkg/modules"
+	"github.com/adamkali/egg_cli/pkg/templates"
+)
+
+// go test ./pkg/templates -run TestGolden -update rewrites testdata/golden after a template changed
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+const (
+	goldenDir = "testdata/golden"
+	// goldenSuffix keeps the go tools from reading the golden go files as code
+	goldenSuffix = ".golden"
+	// the code sqlc and swag would generate into a project, see testdata/stubs
+	stubsDir = "testdata/stubs"
+)
+
+// renderProject writes every file the configuration generates into dir and returns their output paths
+func renderProject(t *testing.T, config *configuration.Configuration, dir string) []string {
+	t.Helper()
+	files, err := templates.Files(config)
+	if err != nil {
+		t.Fatalf("Files() error = %v", err)
+	}
+	outputs := make([]string, 0, len(files))
+	for _, file := range files {
+		rendered := new(bytes.Buffer)
+		if err := file.Template.Execute(rendered, config); err != nil {
+			t.Fatalf("error rendering %s: %v", file.Output, err)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(file.Output))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(target, rendered.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+		outputs = append(outputs, file.Output)
+	}
+	return outputs
+}
+
+// featureCombinations returns a configuration per combination of configuration.ValidFeatures
+func featureCombinations() map[string]*configuration.Configuration {
+	features := configuration.ValidFeatures
+	combinations := make(map[string]*configuration.Configuration, 1<<len(features))
+	for combination := 0; combination < 1<<len(features); combination++ {
+		config := createConfiguration()
+		enabled := []string{}
+		for i, feature := range features {
+			config.SetFeature(feature, combination&(1<<i) != 0)
+			if combination&(1<<i) != 0 {
+				enabled = append(enabled, feature)
+			}
+		}
+		name := strings.Join(enabled, "+")
+		if name == "" {
+			name = "none"
+		}
+		combinations[name] = config
+	}
+	return combinations
+}
+
+// TestGolden compares the project of createConfiguration with testdata/golden, so that
+// every change to the rendered output of a template shows up in review
+func TestGolden(t *testing.T) {
+	config := createConfiguration()
+	dir := t.TempDir()
+	outputs := renderProject(t, config, dir)
+
+	if *update {
+		if err := os.RemoveAll(goldenDir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rendered := make(map[string]bool, len(outputs))
+	for _, output := range outputs {
+		rendered[output] = true
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(output)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		golden := filepath.Join(goldenDir, filepath.FromSlash(output)+goldenSuffix)
+		if *update {
+			if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(golden, content, 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Errorf("%s has no golden file, run go test -run TestGolden -update: %v", output, err)
+			continue
+		}
+		if !bytes.Equal(content, want) {
+			t.Errorf("%s differs from %s at %s, run go test -run TestGolden -update if the change is intended",
+				output, golden, firstDifference(string(want), string(content)))
+		}
+	}
+
+	err := filepath.WalkDir(goldenDir, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		relative, err := filepath.Rel(goldenDir, file)
+		if err != nil {
+			return err
+		}
+		output := strings.TrimSuffix(filepath.ToSlash(relative), goldenSuffix)
+		if !rendered[output] {
+			t.Errorf("%s is no longer rendered, run go test -run TestGolden -update", file)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestParse parses the go files of every combination of features, so that a template
+// or a feature conditional that leaves a dangling brace or string fails without a go toolchain
+func TestParse(t *testing.T) {
+	for name, config := range featureCombinations() {
+		files, err := templates.Files(config)
+		if err != nil {
+			t.Fatalf("Files(%s) error = %v", name, err)
+		}
+		for _, file := range files {
+			if !strings.HasSuffix(file.Output, ".go") {
+				continue
+			}
+			rendered := new(bytes.Buffer)
+			if err := file.Template.Execute(rendered, config); err != nil {
+				t.Fatalf("error rendering %s with %s: %v", file.Output, name, err)
+			}
+			if _, err := parser.ParseFile(token.NewFileSet(), file.Output, rendered, parser.AllErrors); err != nil {
+				t.Errorf("%s with %s does not parse: %v", file.Output, name, err)
+			}
+		}
+	}
+}
+
+// TestCompile
+//
+// description:
+//
+//	Renders the project into a temporary module, adds the stubs of the code that
+//	sqlc and swag generate and runs go vet and go build on it, for every feature
+//	enabled, none and every feature disabled on its own. The modules of the project
+//	are taken from the module cache (GOPROXY=off) and downloaded when they are not
+//	all there. When they can not be resolved at all the test is skipped, unless
+//	EGG_COMPILE_TEST=1 or CI is set so that it can not pass without compiling.
+func TestCompile(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiling the generated project is skipped with -short")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+	stubs, err := filepath.Abs(stubsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	combinations := featureCombinations()
+	names := []string{strings.Join(configuration.ValidFeatures, "+"), "none"}
+	for _, disabled := range configuration.ValidFeatures {
+		var enabled []string
+		for _, feature := range configuration.ValidFeatures {
+			if feature != disabled {
+				enabled = append(enabled, feature)
+			}
+		}
+		names = append(names, strings.Join(enabled, "+"))
+	}
+
+	for _, name := range names {
+		config := combinations[name]
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			renderProject(t, config, dir)
+			writeGoMod(t, config, dir)
+			// the repository is only imported by the code of auth, and imports pgx and uuid itself
+			if config.HasFeature(configuration.FeatureAuth) {
+				copyStub(t, filepath.Join(stubs, "repository"), filepath.Join(dir, filepath.FromSlash(config.Database.SqlcRepositoryLocation)))
+			}
+			copyStub(t, filepath.Join(stubs, "docs"), filepath.Join(dir, "docs"))
+
+			env := []string{"GOPROXY=off"}
+			output, err := runGo(dir, env, "mod", "tidy")
+			if err != nil {
+				// the module cache does not have every module, download them
+				env = nil
+				output, err = runGo(dir, env, "mod", "tidy")
+			}
+			if err != nil {
+				if strictCompile() {
+					t.Fatalf("the modules of the project with %s can not be resolved: %v\n%s", name, err, output)
+				}
+				t.Skipf("the modules of the project can not be resolved, set EGG_COMPILE_TEST=1 to fail instead: %v\n%s", err, output)
+			}
+			if output, err := runGo(dir, env, "vet", "./..."); err != nil {
+				t.Fatalf("go vet failed on the project with %s: %v\n%s", name, err, output)
+			}
+			if output, err := runGo(dir, env, "build", "./..."); err != nil {
+				t.Fatalf("go build failed on the project with %s: %v\n%s", name, err, output)
+			}
+		})
+	}
+}
+
+// strictCompile reports whether TestCompile has to fail instead of being skipped
+func strictCompile() bool {
+	return os.Getenv("EGG_COMPILE_TEST") == "1" || os.Getenv("CI") != ""
+}
+
+// writeGoMod writes the go.mod egg::install_libraries would, requiring the pinned modules
+func writeGoMod(t *testing.T, config *configuration.Configuration, dir string) {
+	t.Helper()
+	required, err := templates.Dependencies(config, templates.Mapping(config))
+	if err != nil {
+		t.Fatalf("Dependencies() error = %v", err)
+	}
+	var goMod strings.Builder
+	fmt.Fprintf(&goMod, "module %s\n\ngo %d.%d\n\nrequire (\n", config.Namespace, modules.MinimumGoMajor, modules.MinimumGoMinor)
+	for _, module := range required {
+		fmt.Fprintf(&goMod, "\t%s %s\n", module.Path, module.Version)
+	}
+	goMod.WriteString(")\n")
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// copyStub copies the go files of a stub package into dir
+func copyStub(t *testing.T, stub string, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(stub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		content, err := os.ReadFile(filepath.Join(stub, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, entry.Name()), content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// runGo runs the go command in the project with env, outside of any workspace of egg_cli
+func runGo(dir string, env []string, args ...string) ([]byte, error) {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Env = append(append(os.Environ(), "GOWORK=off", "GOFLAGS=-mod=mod"), env...)
+	return cmd.CombinedOutput()
+}
+
+// firstDifference describes the first line where got differs from want
+func firstDifference(want string, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var wantLine, gotLine string
+		if i < len(wantLines) {
+			wantLine = wantLines[i]
+		}
+		if i < len(gotLines) {
+			gotLine = gotLines[i]
+		}
+		if wantLine != gotLine {
+			return fmt.Sprintf("line %d:\n\twant: %q\n\tgot:  %q", i+1, wantLine, gotLine)
+		}
+	}
+	return "the end of the file"
+}
@@ -0,0 +1,196 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/adamkali/egg_cli/pkg/configuration"
+	"github.com/adamkali/egg_cli/pkg/targets"
+)
+
+// DriftError is returned when the imports of the rendered templates and the pinned modules disagree
+type DriftError struct {
+	// Unpinned are imported packages that no module in targets.GolangModules provides
+	Unpinned []string
+	// Unused are pinned modules that no template imports
+	Unused []targets.Module
+}
+
+func (e *DriftError) Error() string {
+	var problems []string
+	if len(e.Unpinned) > 0 {
+		problems = append(problems, "imports without a pinned module in targets.GolangModules: "+strings.Join(e.Unpinned, ", "))
+	}
+	if len(e.Unused) > 0 {
+		unused := make([]string, len(e.Unused))
+		for i, module := range e.Unused {
+			unused[i] = module.Path
+		}
+		problems = append(problems, "pinned modules that no template imports: "+strings.Join(unused, ", "))
+	}
+	return "the templates drifted from targets.GolangModules, " + strings.Join(problems, "; ")
+}
+
+// Imports
+//
+// params:
+//
+//	config: *configuration.Configuration
+//	mapping: map[string]*template.Template
+//
+// returns:
+//
+//	[]string: every package the rendered go files import outside of the standard library
+//	          and of the project itself, sorted
+//	error:
+//	  - if a template can not be executed
+//	  - if the imports of a rendered file can not be parsed
+//
+// description:
+//
+//	Renders every go file of the mapping (see Load) with the configuration and parses its
+//	imports with go/parser, so that the list always matches what the project will import.
+func Imports(config *configuration.Configuration, mapping map[string]*template.Template) ([]string, error) {
+	seen := make(map[string]bool)
+	fileSet := token.NewFileSet()
+	for name, tmpl := range mapping {
+		if path.Ext(name) != ".go" {
+			continue
+		}
+		var rendered bytes.Buffer
+		if err := tmpl.Execute(&rendered, config); err != nil {
+			return nil, fmt.Errorf("error executing template %s: %w", name, err)
+		}
+		file, err := parser.ParseFile(fileSet, name, rendered.Bytes(), parser.ImportsOnly)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing the imports of %s: %w", name, err)
+		}
+		for _, spec := range file.Imports {
+			importPath, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing the imports of %s: %w", name, err)
+			}
+			if isStandardLibrary(importPath) || withinModule(importPath, config.Namespace) {
+				continue
+			}
+			seen[importPath] = true
+		}
+	}
+
+	imports := make([]string, 0, len(seen))
+	for importPath := range seen {
+		imports = append(imports, importPath)
+	}
+	sort.Strings(imports)
+	return imports, nil
+}
+
+// Dependencies
+//
+// params:
+//
+//	config: *configuration.Configuration
+//	mapping: map[string]*template.Template
+//
+// returns:
+//
+//	[]targets.Module: the pinned modules the project requires, in the order of
+//	                  targets.GolangModules followed by targets.GeneratedModules
+//	error:
+//	  - a DriftError, together with the modules, if the templates and the pins disagree
+//	  - if the imports could not be collected
+//
+// description:
+//
+//	Computes the modules to require from the imports of the rendered templates instead
+//	of requiring every pinned module. An import whose module is not pinned is left to
+//	`go mod tidy`, a pinned module that is not imported is left out of go.mod. A pinned
+//	module is only reported as unused when the built-in templates with every feature
+//	enabled do not import it either, so that e.g. minio-go is no drift without storage.
+func Dependencies(config *configuration.Configuration, mapping map[string]*template.Template) ([]targets.Module, error) {
+	imports, err := Imports(config, mapping)
+	if err != nil {
+		return nil, err
+	}
+	featured, err := featureModules(config)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[string]bool)
+	drift := new(DriftError)
+	for _, importPath := range imports {
+		module, ok := providingModule(importPath, targets.GolangModules)
+		if !ok {
+			drift.Unpinned = append(drift.Unpinned, importPath)
+			continue
+		}
+		used[module.Path] = true
+	}
+
+	var required []targets.Module
+	for _, module := range targets.GolangModules {
+		if used[module.Path] {
+			required = append(required, module)
+		} else if !featured[module.Path] {
+			drift.Unused = append(drift.Unused, module)
+		}
+	}
+	required = append(required, targets.GeneratedModules...)
+	if len(drift.Unpinned) > 0 || len(drift.Unused) > 0 {
+		return required, drift
+	}
+	return required, nil
+}
+
+// featureModules returns the pinned modules the built-in templates import with every
+// feature of the configuration enabled, empty when none is disabled
+func featureModules(config *configuration.Configuration) (map[string]bool, error) {
+	featured := make(map[string]bool)
+	if slices.IndexFunc(configuration.ValidFeatures, func(feature string) bool { return !config.HasFeature(feature) }) < 0 {
+		return featured, nil
+	}
+	all := *config
+	all.Features = configuration.Features{}
+	imports, err := Imports(&all, Mapping(&all))
+	if err != nil {
+		return nil, err
+	}
+	for _, importPath := range imports {
+		if module, ok := providingModule(importPath, targets.GolangModules); ok {
+			featured[module.Path] = true
+		}
+	}
+	return featured, nil
+}
+
+// providingModule returns the module with the longest path that contains the package
+func providingModule(importPath string, modules []targets.Module) (targets.Module, bool) {
+	var found targets.Module
+	for _, module := range modules {
+		if withinModule(importPath, module.Path) && len(module.Path) > len(found.Path) {
+			found = module
+		}
+	}
+	return found, found.Path != ""
+}
+
+// withinModule reports whether the package belongs to the module
+func withinModule(importPath, modulePath string) bool {
+	return importPath == modulePath || strings.HasPrefix(importPath, modulePath+"/")
+}
+
+// isStandardLibrary reports whether the package is part of the standard library,
+// whose import paths never have a dot in their first element
+func isStandardLibrary(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
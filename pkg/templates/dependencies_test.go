@@ -0,0 +1,75 @@
+package templates_test
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/adamkali/egg_cli/pkg/targets"
+	"github.com/adamkali/egg_cli/pkg/templates"
+)
+
+// TestDependencies fails when a template imports a module that is not pinned in
+// targets.GolangModules, or a pinned module is no longer imported by any template
+func TestDependencies(t *testing.T) {
+	config := createConfiguration()
+	required, err := templates.Dependencies(config, templates.Mapping(config))
+	if err != nil {
+		t.Fatalf("Dependencies() error = %v", err)
+	}
+	want := append(append([]targets.Module(nil), targets.GolangModules...), targets.GeneratedModules...)
+	if !reflect.DeepEqual(required, want) {
+		t.Errorf("Dependencies() = %v, want %v", required, want)
+	}
+}
+
+// TestDependencies_Features checks that the modules of a disabled feature are left out
+// of go.mod without being reported as a drift
+func TestDependencies_Features(t *testing.T) {
+	config := createConfiguration()
+	config.SetFeature("cache", false)
+	config.SetFeature("storage", false)
+	required, err := templates.Dependencies(config, templates.Mapping(config))
+	if err != nil {
+		t.Fatalf("Dependencies() error = %v without cache and storage", err)
+	}
+	for _, module := range required {
+		if module.Path == "github.com/redis/go-redis/v9" || module.Path == "github.com/minio/minio-go/v7" {
+			t.Errorf("Dependencies() requires %s without its feature", module.Path)
+		}
+	}
+}
+
+func TestImports(t *testing.T) {
+	config := createConfiguration()
+	imports, err := templates.Imports(config, templates.Mapping(config))
+	if err != nil {
+		t.Fatalf("Imports() error = %v", err)
+	}
+	for _, want := range []string{"golang.org/x/crypto/bcrypt", "github.com/labstack/echo/v4/middleware", "github.com/jackc/pgx/v5/pgxpool"} {
+		if !slices.Contains(imports, want) {
+			t.Errorf("Imports() does not contain %s: %v", want, imports)
+		}
+	}
+	for _, importPath := range imports {
+		if strings.HasPrefix(importPath, config.Namespace+"/") || !strings.Contains(importPath, ".") {
+			t.Errorf("Imports() contains %s, which is not an external package", importPath)
+		}
+	}
+	if !slices.IsSorted(imports) {
+		t.Errorf("Imports() is not sorted: %v", imports)
+	}
+}
+
+func TestDriftError(t *testing.T) {
+	err := &templates.DriftError{
+		Unpinned: []string{"github.com/labstack/echo"},
+		Unused:   []targets.Module{{Path: "github.com/google/uuid", Version: "v1.6.0"}},
+	}
+	for _, want := range []string{"github.com/labstack/echo", "github.com/google/uuid"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Error() = %q, want it to name %s", err.Error(), want)
+		}
+	}
+}
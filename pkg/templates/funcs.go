@@ -0,0 +1,126 @@
+package templates
+
+import (
+	"go/token"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// FuncMap
+//
+// returns:
+//
+//	template.FuncMap: the helpers every built-in template, output path and override can use
+//
+// description:
+//
+//	The helpers turn the values of the configuration into names that are valid
+//	where they are pasted, e.g. for the name my-app:
+//
+//	  pascal  -> MyApp   (exported go names)
+//	  snake   -> my_app  (sql and file names)
+//	  kebab   -> my-app  (binaries and docker images)
+//	  goIdent -> myapp   (go identifiers and package names)
+//	  envVar  -> MY_APP  (environment variables)
+//	  quote   -> "my-app" (go, json and yaml string literals)
+//	  plural  -> my-apps
+//
+//	A new map is returned every call so that a caller can add to it.
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"pascal":  pascal,
+		"snake":   snake,
+		"kebab":   kebab,
+		"goIdent": goIdent,
+		"envVar":  envVar,
+		"quote":   strconv.Quote,
+		"plural":  plural,
+	}
+}
+
+// newTemplate starts a template with the helpers of FuncMap
+func newTemplate(name string) *template.Template {
+	return template.New(name).Funcs(FuncMap())
+}
+
+// words splits s into lower case words at every character that is not a letter or a
+// digit and at every change of case, so that my-app, my_app, MyApp and myApp are the same
+func words(s string) []string {
+	var result []string
+	var word []rune
+	runes := []rune(s)
+	flush := func() {
+		if len(word) > 0 {
+			result = append(result, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && len(word) > 0 {
+			previous := runes[i-1]
+			// myApp -> my app, HTTPServer -> http server
+			next := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && next) {
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+	return result
+}
+
+func pascal(s string) string {
+	var builder strings.Builder
+	for _, word := range words(s) {
+		runes := []rune(word)
+		builder.WriteString(strings.ToUpper(string(runes[0])) + string(runes[1:]))
+	}
+	return builder.String()
+}
+
+func snake(s string) string {
+	return strings.Join(words(s), "_")
+}
+
+func kebab(s string) string {
+	return strings.Join(words(s), "-")
+}
+
+func envVar(s string) string {
+	return strings.ToUpper(snake(s))
+}
+
+// goIdent returns the lower case words of s joined, prefixed with _ when it would start
+// with a digit and suffixed with _ when it is a go keyword
+func goIdent(s string) string {
+	ident := strings.Join(words(s), "")
+	if ident == "" || unicode.IsDigit([]rune(ident)[0]) {
+		ident = "_" + ident
+	}
+	if token.IsKeyword(ident) {
+		ident += "_"
+	}
+	return ident
+}
+
+// plural returns the english plural of the last word of s
+func plural(s string) string {
+	lower := strings.ToLower(s)
+	switch {
+	case s == "":
+		return s
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !strings.ContainsAny(lower[len(lower)-2:len(lower)-1], "aeiou"):
+		return s[:len(s)-1] + "ies"
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return s + "es"
+	}
+	return s + "s"
+}
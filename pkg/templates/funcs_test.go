@@ -0,0 +1,69 @@
+package templates_test
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+
+	"github.com/adamkali/egg_cli/pkg/templates"
+)
+
+func TestFuncMap(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{`{{pascal "my-app"}}`, "MyApp"},
+		{`{{pascal "egg_cli"}}`, "EggCli"},
+		{`{{pascal "HTTPServer"}}`, "HttpServer"},
+		{`{{snake "my-app"}}`, "my_app"},
+		{`{{snake "myApp2"}}`, "my_app2"},
+		{`{{kebab "My App"}}`, "my-app"},
+		{`{{kebab "egg_cli"}}`, "egg-cli"},
+		{`{{goIdent "my-app"}}`, "myapp"},
+		{`{{goIdent "9lives"}}`, "_9lives"},
+		{`{{goIdent "type"}}`, "type_"},
+		{`{{goIdent "--"}}`, "_"},
+		{`{{envVar "my-app"}}`, "MY_APP"},
+		{`{{envVar "myApp.db"}}`, "MY_APP_DB"},
+		{`{{quote "my \"app\""}}`, `"my \"app\""`},
+		{`{{plural "user"}}`, "users"},
+		{`{{plural "category"}}`, "categories"},
+		{`{{plural "key"}}`, "keys"},
+		{`{{plural "box"}}`, "boxes"},
+		{`{{plural "match"}}`, "matches"},
+		{`{{quote (kebab .Name)}}`, `"egg"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			tmpl := template.Must(template.New("funcs").Funcs(templates.FuncMap()).Parse(tt.text))
+			rendered := new(bytes.Buffer)
+			if err := tmpl.Execute(rendered, createConfiguration()); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if rendered.String() != tt.want {
+				t.Errorf("%s = %q, want %q", tt.text, rendered.String(), tt.want)
+			}
+		})
+	}
+}
+
+// TestMapping_Funcs renders the built-in templates for a name that is not a valid
+// binary or identifier as it is, and checks that it only shows up sanitised
+func TestMapping_Funcs(t *testing.T) {
+	config := createConfiguration()
+	config.Name = "My_App"
+	rendered := new(bytes.Buffer)
+	for _, name := range []string{"Dockerfile", "cmd/root.go", ".air.toml"} {
+		rendered.Reset()
+		if err := templates.Mapping(config)[name].Execute(rendered, config); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if bytes.Contains(rendered.Bytes(), []byte("My_App")) {
+			t.Errorf("%s contains the unsanitised name My_App", name)
+		}
+		if !bytes.Contains(rendered.Bytes(), []byte("my-app")) {
+			t.Errorf("%s does not contain the name my-app", name)
+		}
+	}
+}
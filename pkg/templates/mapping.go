@@ -1,70 +1,213 @@
 package templates
 
 import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
 	"text/template"
 
 	"github.com/adamkali/egg_cli/pkg/configuration"
+	"gopkg.in/yaml.v3"
 )
 
+var (
+	// files are the built-in templates, all: keeps .gitignore and the other dotfiles
+	//go:embed all:files
+	files embed.FS
+	// manifestFile declares every template of files, see Entry
+	//go:embed manifest.yaml
+	manifestFile []byte
+)
+
+// DefaultPermissions is the mode of a generated file whose entry has none
+const DefaultPermissions fs.FileMode = 0644
+
+// Entry is a single template declared in manifest.yaml
+type Entry struct {
+	// Template is the path of the template under files/
+	Template string `yaml:"template"`
+	// Output is where the file is written in the project, a template of the configuration
+	Output string `yaml:"output"`
+	// When is a pipeline of the configuration, e.g. .HasFeature "auth", the file is only
+	// rendered when it is true. An empty When is always true.
+	When string `yaml:"when,omitempty"`
+	// Permissions is the octal mode of the file, empty is DefaultPermissions
+	Permissions string `yaml:"permissions,omitempty"`
+}
+
+// File is a template of the manifest for a configuration
+type File struct {
+	// Output is the path of the file in the project
+	Output   string
+	Template *template.Template
+	Mode     fs.FileMode
+}
+
+// Entries returns the templates declared in manifest.yaml, in order
+func Entries() ([]Entry, error) {
+	var manifest struct {
+		Templates []Entry `yaml:"templates"`
+	}
+	if err := yaml.Unmarshal(manifestFile, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse the template manifest: %w", err)
+	}
+	return manifest.Templates, nil
+}
+
+// Files
+//
+// params:
+//
+//	config: *configuration.Configuration
+//
+// returns:
+//
+//	[]File: every file the configuration generates, in the order of the manifest
+//	error: the error of the first entry that can not be rendered, see Entry.File
+//
+// description:
+//
+//	Generates the templates from manifest.yaml, leaving out the entries whose
+//	condition is false so that the project only gets the files of its features.
+func Files(config *configuration.Configuration) ([]File, error) {
+	entries, err := Entries()
+	if err != nil {
+		return nil, err
+	}
+	generated := make([]File, 0, len(entries))
+	for _, entry := range entries {
+		file, ok, err := entry.File(config)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			generated = append(generated, file)
+		}
+	}
+	return generated, nil
+}
+
+// File
+//
+// params:
+//
+//	config: *configuration.Configuration
+//
+// returns:
+//
+//	File: the template of the entry with its output path and mode
+//	bool: false if the condition of the entry is false for the configuration
+//	error:
+//	  - if the template, the output path or the condition does not parse
+//	  - if the output path or the condition can not be rendered with the configuration
+//	  - if the permissions are not an octal mode
+func (entry Entry) File(config *configuration.Configuration) (File, bool, error) {
+	if entry.When != "" {
+		enabled, err := render("when of "+entry.Template, "{{if "+entry.When+"}}true{{end}}", config)
+		if err != nil {
+			return File{}, false, err
+		}
+		if enabled != "true" {
+			return File{}, false, nil
+		}
+	}
+	output, err := render("output of "+entry.Template, entry.Output, config)
+	if err != nil {
+		return File{}, false, err
+	}
+	mode := DefaultPermissions
+	if entry.Permissions != "" {
+		permissions, err := strconv.ParseUint(entry.Permissions, 8, 32)
+		if err != nil {
+			return File{}, false, fmt.Errorf("invalid permissions %q of %s: %w", entry.Permissions, entry.Template, err)
+		}
+		mode = fs.FileMode(permissions)
+	}
+	content, err := fs.ReadFile(files, path.Join("files", entry.Template))
+	if err != nil {
+		return File{}, false, fmt.Errorf("template %s of the manifest: %w", entry.Template, err)
+	}
+	tmpl, err := newTemplate(entry.Template).Parse(string(content))
+	if err != nil {
+		return File{}, false, err
+	}
+	return File{Output: path.Clean(output), Template: tmpl, Mode: mode}, true, nil
+}
+
+// excludedOutputs returns the output paths of the entries whose condition is false for the
+// configuration, the files Files leaves out
+func excludedOutputs(config *configuration.Configuration) (map[string]bool, error) {
+	entries, err := Entries()
+	if err != nil {
+		return nil, err
+	}
+	excluded := make(map[string]bool)
+	for _, entry := range entries {
+		_, ok, err := entry.File(config)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			continue
+		}
+		output, err := render("output of "+entry.Template, entry.Output, config)
+		if err != nil {
+			return nil, err
+		}
+		excluded[path.Clean(output)] = true
+	}
+	return excluded, nil
+}
+
+// Mapping returns the templates of Files by output path. The manifest and the templates
+// are embedded, so an error is a bug of egg_cli and panics like template.Must.
 func Mapping(config *configuration.Configuration) map[string]*template.Template {
-	return map[string]*template.Template{
-		"main.go":                                                 template.Must(template.New("main.go").Parse(MainGoTemplate)),
-		"openapitools.json":                                       template.Must(template.New("openapitools.yaml").Parse(OpenapitoolsJSONTemplate)),
-		"sqlc.yaml":                                               template.Must(template.New("sqlc.yaml").Parse(SQLCYamlTemplate)),
-		"README.md":                                               template.Must(template.New("README.md").Parse(READMETemplate)),
-		"Makefile":                                                template.Must(template.New("Makefile").Parse(MakefileTemplate)),
-		"Dockerfile":                                              template.Must(template.New("Dockerfile").Parse(DockerfileTemplate)),
-		".gitignore":                                              template.Must(template.New(".gitignore").Parse(GitignoreTemplate)),
-		".dockerignore":                                           template.Must(template.New(".dockerignore").Parse(DockerignoreTemplate)),
-		".air.toml":                                               template.Must(template.New(".air.toml").Parse(AirTomlTemplate)),
-		"./cmd/configuration/configuration.go":                    template.Must(template.New("./cmd/configuration/configuration.go").Parse(CmdConfigurationConfigurationTemplate)),
-		"./cmd/db.go":                                             template.Must(template.New("./cmd/db.go").Parse(DBCmdTemplate)),
-		"./cmd/migrate.go":                                        template.Must(template.New("./cmd/migrate.go").Parse(MigrateCmdTemplate)),
-		"./cmd/root.go":                                           template.Must(template.New("./cmd/root.go").Parse(RootCmdTemplate)),
-		"./cmd/swag.go":                                           template.Must(template.New("./cmd/swag.go").Parse(SwagCmdTemplate)),
-		"./cmd/up.go":                                             template.Must(template.New("./cmd/up.go").Parse(UpCmdTemplate)),
-		"./cmd/down.go":                                           template.Must(template.New("./cmd/down.go").Parse(DownCmdTemplate)),
-		"./cmd/generate.go":                                       template.Must(template.New("./cmd/generate.go").Parse(GenerateCmdTemplate)),
-		"./cmd/version.go":                                        template.Must(template.New("./cmd/version.go").Parse(VersionCmdTemplate)),
-		"./cmd/bump.go":                                           template.Must(template.New("./cmd/bump.go").Parse(BumpCmdTemplate)),
-		"./services/auth_service.go":                              template.Must(template.New("./services/auth_service.go").Parse(SERVICES_AuthServiceTemplate)),
-		"./services/minio_service.go":                             template.Must(template.New("./services/minio_service.go").Parse(SERVICES_MinioServiceTemplate)),
-		"./services/redis_service.go":                             template.Must(template.New("./services/redis_service.go").Parse(SERVICES_RedisServiceTemplate)),
-		"./services/user_service.go":                              template.Must(template.New("./services/user_service.go").Parse(SERVICES_UserServiceTemplate)),
-		"./services/validator_service.go":                         template.Must(template.New("./services/validator_service.go").Parse(SERVICES_ValidatorServiceTemplate)),
-		"./services/mock_auth_service.go":                         template.Must(template.New("./services/mock_auth_service.go").Parse(SERVICES_MockAuthServiceTemplate)),
-		"./services/mock_user_service.go":                         template.Must(template.New("./services/mock_user_service.go").Parse(SERVICES_MockUserServiceTemplate)),
-		"./services/i_auth_service.go":                            template.Must(template.New("./services/i_auth_service.go").Parse(SERVICES_IAuthServiceTemplate)),
-		"./services/i_minio_service.go":                           template.Must(template.New("./services/i_minio_service.go").Parse(SERVICES_IMinioServiceTemplate)),
-		"./services/i_redis_service.go":                           template.Must(template.New("./services/i_redis_service.go").Parse(SERVICES_IRedisServiceTemplate)),
-		"./services/i_user_service.go":                            template.Must(template.New("./services/i_user_service.go").Parse(SERVICES_IUserServiceTemplate)),
-		"./controllers/controller.go":                             template.Must(template.New("./controllers/controller.go").Parse(CONTROLLER_ControllerTemplate)),
-		"./controllers/routes.go":                                 template.Must(template.New("./controllers/routes.go").Parse(CONTROLLER_RoutesTemplate)),
-		"./controllers/user_controller.go":                        template.Must(template.New("./controllers/user_controller.go").Parse(CONTROLLERS_UserControllerTemplate)),
-		"./middlewares/configs/auth.go":                           template.Must(template.New("./middlewares/configs/auth.go").Parse(MIDDLEWARES_CONFIGS_AuthConfigTemplate)),
-		"./middlewares/configs/static.go":                         template.Must(template.New("./middlewares/configs/static.go").Parse(MIDDLEWARES_CONFIGS_StaticConfigTemplate)),
-		"./models/requests/login_request.go":                      template.Must(template.New("./models/requests/login_request.go").Parse(MODELS_REQUESTS_LoginRequestTemplate)),
-		"./models/requests/new_user_request.go":                   template.Must(template.New("./models/requests/new_user_request.go").Parse(MODELS_REQUESTS_NewUserRequestTemplate)),
-		"./models/responses/delete_user_response.go":              template.Must(template.New("./models/responses/delete_user_response.go").Parse(MODELS_RESPONSE_DeleteUserResponseTemplate)),
-		"./models/responses/login_response.go":                    template.Must(template.New("./models/responses/login_response.go").Parse(MODELS_RESPONSE_LoginResponseTemplate)),
-		"./models/responses/user_response.go":                     template.Must(template.New("./models/responses/user_response.go").Parse(MODELS_RESPONSE_UserResponseTemplate)),
-		"./models/responses/users_response.go":                    template.Must(template.New("./models/responses/users_response.go").Parse(MODELS_RESPONSE_UsersResponseTemplate)),
-		"./models/handlers/login_handler.go":                      template.Must(template.New("./models/handlers/login_handler.go").Parse(MODELS_HANDLERS_LoginHandlerTemplate)),
-		"./models/handlers/register_handler.go":                   template.Must(template.New("./models/handlers/register_handler.go").Parse(MODELS_HANDLERS_RegisterHandlerTemplate)),
-		"./models/handlers/delete_user_handler.go":                template.Must(template.New("./models/handlers/delete_user_handler.go").Parse(MODELS_HANDLERS_DeleteUserHandlerTemplate)),
-		"./models/handlers/get_current_logged_in_user_handler.go": template.Must(template.New("./models/handlers/get_current_logged_in_user_handler.go").Parse(MODELS_HANDLERS_GetCurrentLoggedInUserHandlerTemplate)),
-		"./models/handlers/get_profile_picture_handler.go":        template.Must(template.New("./models/handlers/get_profile_picture_handler.go").Parse(MODELS_HANDLERS_GetProfilePictureHandlerTemplate)),
-		"./models/handlers/get_users_handler.go":                  template.Must(template.New("./models/handlers/get_users_handler.go").Parse(MODELS_HANDLERS_GetUsersHandlerTemplate)),
-		"./models/handlers/upload_profile_picture_handler.go":     template.Must(template.New("./models/handlers/upload_profile_picture_handler.go").Parse(MODELS_HANDLERS_UploadProfilePictureHandlerTemplate)),
-		"./" + config.Database.Migration.Destination + "/0001_init.sql": template.Must(
-			template.New("./" + config.Database.Migration.Destination + "/migrations/0001_init.sql").Parse(DATABASE_MIGRATIONS_INITTemplate),
-		),
-		"./" + config.Database.QueriesLocation + "/token.go": template.Must(
-			template.New("./" + config.Database.QueriesLocation + "/token.go").Parse(DATABASE_QUERIES_TokenTemplate),
-		),
+	generated, err := Files(config)
+	if err != nil {
+		panic(err)
+	}
+	mapping := make(map[string]*template.Template, len(generated))
+	for _, file := range generated {
+		mapping[file.Output] = file.Template
+	}
+	return mapping
+}
+
+// Permissions returns the mode of every file of Files by output path, see Mapping
+func Permissions(config *configuration.Configuration) map[string]fs.FileMode {
+	generated, err := Files(config)
+	if err != nil {
+		panic(err)
+	}
+	permissions := make(map[string]fs.FileMode, len(generated))
+	for _, file := range generated {
+		permissions[file.Output] = file.Mode
+	}
+	return permissions
+}
+
+// render executes text, a template of the manifest, with the configuration
+func render(name string, text string, config *configuration.Configuration) (string, error) {
+	tmpl, err := newTemplate(name).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, config); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(rendered.String()), nil
+}
 
-		"./" + config.Database.QueriesLocation + "/user.go": template.Must(
-			template.New("./" + config.Database.QueriesLocation + "/user.go").Parse(DATABASE_QUERIES_UserTemplate),
-		),
+// source returns the content of a built-in template, name is its path under files/
+func source(name string) string {
+	content, err := fs.ReadFile(files, path.Join("files", name))
+	if err != nil {
+		panic(err)
 	}
+	return string(content)
 }
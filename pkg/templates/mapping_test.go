@@ -0,0 +1,172 @@
+package templates_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adamkali/egg_cli/pkg/configuration"
+	"github.com/adamkali/egg_cli/pkg/templates"
+)
+
+// TestEntries fails when a template under files/ is not declared in manifest.yaml, or
+// the manifest declares a template that does not exist or an output twice
+func TestEntries(t *testing.T) {
+	entries, err := templates.Entries()
+	if err != nil {
+		t.Fatalf("Entries() error = %v", err)
+	}
+	declared := make(map[string]bool, len(entries))
+	outputs := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if declared[entry.Template] {
+			t.Errorf("%s is declared twice", entry.Template)
+		}
+		declared[entry.Template] = true
+		if outputs[entry.Output] {
+			t.Errorf("%s is the output of two templates", entry.Output)
+		}
+		outputs[entry.Output] = true
+	}
+
+	err = filepath.WalkDir("files", func(file string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		name, _ := filepath.Rel("files", file)
+		name = filepath.ToSlash(name)
+		if !strings.HasSuffix(name, ".tmpl") {
+			t.Errorf("%s does not end with .tmpl", name)
+		}
+		if !declared[name] {
+			t.Errorf("%s is not declared in manifest.yaml", name)
+		}
+		delete(declared, name)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name := range declared {
+		t.Errorf("manifest.yaml declares %s, which does not exist", name)
+	}
+}
+
+func TestMapping(t *testing.T) {
+	config := createConfiguration()
+	config.Database.QueriesLocation = "sql/queries"
+	mapping := templates.Mapping(config)
+	for _, want := range []string{"main.go", "cmd/db.go", ".gitignore", "sql/queries/user.sql", "db/migrations/0001_init.sql"} {
+		if _, ok := mapping[want]; !ok {
+			t.Errorf("Mapping() does not contain %s", want)
+		}
+	}
+	for name := range mapping {
+		if strings.HasPrefix(name, "./") || strings.Contains(name, "{{") {
+			t.Errorf("Mapping() contains %s, want a rendered and cleaned output path", name)
+		}
+	}
+	if mode := templates.Permissions(config)["main.go"]; mode != templates.DefaultPermissions {
+		t.Errorf("Permissions() of main.go = %v, want %v", mode, templates.DefaultPermissions)
+	}
+}
+
+func TestEntry_File(t *testing.T) {
+	config := createConfiguration()
+	tests := []struct {
+		name     string
+		entry    templates.Entry
+		wantOK   bool
+		wantPath string
+		wantMode os.FileMode
+		wantErr  bool
+	}{
+		{name: "always", entry: templates.Entry{Template: "main.go.tmpl", Output: "./main.go"}, wantOK: true, wantPath: "main.go", wantMode: 0644},
+		{name: "output of the configuration", entry: templates.Entry{Template: "main.go.tmpl", Output: "{{.Name}}/main.go"}, wantOK: true, wantPath: "egg/main.go", wantMode: 0644},
+		{name: "when true", entry: templates.Entry{Template: "main.go.tmpl", Output: "main.go", When: ".Cache.URL"}, wantOK: true, wantPath: "main.go", wantMode: 0644},
+		{name: "when false", entry: templates.Entry{Template: "main.go.tmpl", Output: "main.go", When: "not .Cache.URL"}},
+		{name: "permissions", entry: templates.Entry{Template: "main.go.tmpl", Output: "main.go", Permissions: "0755"}, wantOK: true, wantPath: "main.go", wantMode: 0755},
+		{name: "invalid permissions", entry: templates.Entry{Template: "main.go.tmpl", Output: "main.go", Permissions: "rwx"}, wantErr: true},
+		{name: "missing template", entry: templates.Entry{Template: "missing.go.tmpl", Output: "missing.go"}, wantErr: true},
+		{name: "invalid condition", entry: templates.Entry{Template: "main.go.tmpl", Output: "main.go", When: ".Missing"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, ok, err := tt.entry.File(config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("File() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOK {
+				t.Fatalf("File() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if file.Output != tt.wantPath || file.Mode != tt.wantMode || file.Template == nil {
+				t.Errorf("File() = %+v, want %s with mode %v", file, tt.wantPath, tt.wantMode)
+			}
+		})
+	}
+}
+
+func TestFiles_Features(t *testing.T) {
+	config := createConfiguration()
+	all, err := templates.Files(config)
+	if err != nil {
+		t.Fatalf("Files() error = %v", err)
+	}
+
+	for _, feature := range configuration.ValidFeatures {
+		config.SetFeature(feature, false)
+	}
+	minimal, err := templates.Files(config)
+	if err != nil {
+		t.Fatalf("Files() without features error = %v", err)
+	}
+	generated := make(map[string]bool, len(minimal))
+	for _, file := range minimal {
+		generated[file.Output] = true
+	}
+	for _, stripped := range []string{"Dockerfile", "openapitools.json", "sqlc.yaml", "cmd/generate.go", "services/auth_service.go", "services/redis_service.go", "services/minio_service.go", "controllers/user_controller.go", "middlewares/configs/static.go"} {
+		if generated[stripped] {
+			t.Errorf("Files() without features contains %s", stripped)
+		}
+	}
+	for _, kept := range []string{"main.go", "controllers/controller.go", "controllers/routes.go", "services/validator_service.go"} {
+		if !generated[kept] {
+			t.Errorf("Files() without features does not contain %s", kept)
+		}
+	}
+	if len(minimal) >= len(all) {
+		t.Errorf("Files() without features = %d files, want fewer than the %d of every feature", len(minimal), len(all))
+	}
+}
+
+// TestFiles_DockerWithoutFrontend checks that the Dockerfile of a project without a frontend
+// does not build one, docker build fails on a missing frontend/ directory
+func TestFiles_DockerWithoutFrontend(t *testing.T) {
+	config := createConfiguration()
+	config.SetFeature(configuration.FeatureFrontend, false)
+	files, err := templates.Files(config)
+	if err != nil {
+		t.Fatalf("Files() error = %v", err)
+	}
+	for _, file := range files {
+		if file.Output != "Dockerfile" {
+			continue
+		}
+		rendered := new(strings.Builder)
+		if err := file.Template.Execute(rendered, config); err != nil {
+			t.Fatalf("error rendering Dockerfile: %v", err)
+		}
+		for _, reference := range []string{"node_builder", "frontend/", "/app/" + config.Server.Frontend.Dir} {
+			if strings.Contains(rendered.String(), reference) {
+				t.Errorf("Dockerfile without frontend contains %q:\n%s", reference, rendered)
+			}
+		}
+		return
+	}
+	t.Fatal("Files() with docker does not contain the Dockerfile")
+}
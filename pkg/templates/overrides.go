@@ -0,0 +1,185 @@
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/adamkali/egg_cli/pkg/configuration"
+)
+
+// OverrideSuffix is stripped from the name of an override to get its output path, so
+// that e.g. main.go.tmpl is not mistaken for a go file by editors and linters
+const OverrideSuffix = ".tmpl"
+
+// the directories of version control systems, skipped so that a checkout of the templates can be passed
+var vcsDirs = map[string]bool{".git": true, ".hg": true, ".svn": true, ".jj": true}
+
+// DefaultOverrideDir returns the template directory used when none is passed,
+// $XDG_CONFIG_HOME/egg/templates or ~/.config/egg/templates
+func DefaultOverrideDir() (string, error) {
+	if configHome := os.Getenv("XDG_CONFIG_HOME"); configHome != "" {
+		return filepath.Join(configHome, "egg", "templates"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "egg", "templates"), nil
+}
+
+// OverrideDir
+//
+// params:
+//
+//	dir: string
+//
+// returns:
+//
+//	string: the absolute template directory, empty if there is none
+//	error:
+//	  - if dir was passed but is not a directory
+//
+// description:
+//
+//	Resolves the template directory of the --templates flag. Without a flag the
+//	DefaultOverrideDir is used when it exists, so a team can install its templates
+//	once instead of passing them to every command.
+func OverrideDir(dir string) (string, error) {
+	if dir == "" {
+		defaultDir, err := DefaultOverrideDir()
+		if err != nil {
+			return "", nil
+		}
+		if info, err := os.Stat(defaultDir); err != nil || !info.IsDir() {
+			return "", nil
+		}
+		dir = defaultDir
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", fmt.Errorf("template directory %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("template directory %s is not a directory", dir)
+	}
+	return abs, nil
+}
+
+// Overrides
+//
+// params:
+//
+//	dir: string
+//
+// returns:
+//
+//	map[string]*template.Template: every template of dir by its output path
+//	error:
+//	  - if dir can not be read
+//	  - every template that can not be parsed
+//
+// description:
+//
+//	Parses every file of dir with the OverrideSuffix as a template. The output path of
+//	a file is its path inside of dir without the suffix, e.g.
+//	controllers/user_controller.go.tmpl renders controllers/user_controller.go, and the
+//	template is named by its path inside of dir so that errors and the manifest point
+//	at the override. Other files and the directories of version control are skipped, so
+//	that a git checkout of the templates does not render its .git directory, nor
+//	.DS_Store or the swap files of an editor. Other dot directories such as .github are
+//	kept.
+func Overrides(dir string) (map[string]*template.Template, error) {
+	overrides := make(map[string]*template.Template)
+	var errs []error
+	err := filepath.WalkDir(dir, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if file != dir && vcsDirs[entry.Name()] {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(entry.Name(), OverrideSuffix) {
+			return nil
+		}
+		relative, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(relative)
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		tmpl, err := newTemplate(name).Parse(string(content))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error parsing template %s: %w", file, err))
+			return nil
+		}
+		overrides[strings.TrimSuffix(name, OverrideSuffix)] = tmpl
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading the template directory %s: %w", dir, err)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return overrides, nil
+}
+
+// WithOverrides returns mapping with every override replacing the template of the same
+// output path, or added to it. Paths are compared cleaned, so "./cmd/db.go" is "cmd/db.go".
+func WithOverrides(mapping map[string]*template.Template, overrides map[string]*template.Template) map[string]*template.Template {
+	merged := make(map[string]*template.Template, len(mapping)+len(overrides))
+	keys := make(map[string]string, len(mapping))
+	for name, tmpl := range mapping {
+		merged[name] = tmpl
+		keys[path.Clean(name)] = name
+	}
+	for name, tmpl := range overrides {
+		if key, ok := keys[path.Clean(name)]; ok {
+			merged[key] = tmpl
+			continue
+		}
+		merged[name] = tmpl
+	}
+	return merged
+}
+
+// Load returns Mapping of the configuration with the overrides of the template
+// directory dir, an empty dir is the built-in mapping alone. An override of a built-in
+// template whose condition is false is dropped like the built-in one, so that e.g.
+// services/redis_service.go is not generated without the cache feature.
+func Load(config *configuration.Configuration, dir string) (map[string]*template.Template, error) {
+	mapping := Mapping(config)
+	if dir == "" {
+		return mapping, nil
+	}
+	overrides, err := Overrides(dir)
+	if err != nil {
+		return nil, err
+	}
+	excluded, err := excludedOutputs(config)
+	if err != nil {
+		return nil, err
+	}
+	for name := range overrides {
+		if excluded[path.Clean(name)] {
+			delete(overrides, name)
+		}
+	}
+	return WithOverrides(mapping, overrides), nil
+}
@@ -0,0 +1,157 @@
+package templates_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/adamkali/egg_cli/pkg/templates"
+)
+
+// writeOverrides writes the files into a temporary template directory
+func writeOverrides(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	config := createConfiguration()
+	dir := writeOverrides(t, map[string]string{
+		"cmd/db.go.tmpl":                  "package cmd // {{.Name}}\n",
+		"deploy/ci.yml.tmpl":              "name: {{.Name}}\n",
+		"controllers/house_style.go.tmpl": "package controllers\n",
+	})
+
+	builtin, err := templates.Load(config, "")
+	if err != nil {
+		t.Fatalf("Load() error = %v without a template directory", err)
+	}
+	if len(builtin) != len(templates.Mapping(config)) {
+		t.Errorf("Load() has %d templates without a template directory, want %d", len(builtin), len(templates.Mapping(config)))
+	}
+
+	mapping, err := templates.Load(config, dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(mapping) != len(builtin)+2 {
+		t.Errorf("Load() has %d templates, want the %d built-in ones and 2 new ones", len(mapping), len(builtin))
+	}
+	var rendered bytes.Buffer
+	if err := mapping["cmd/db.go"].Execute(&rendered, config); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if rendered.String() != "package cmd // egg\n" {
+		t.Errorf("cmd/db.go = %q, want the override", rendered.String())
+	}
+	if mapping["cmd/db.go"].Name() != "cmd/db.go.tmpl" {
+		t.Errorf("override is named %q, want its path in the template directory", mapping["cmd/db.go"].Name())
+	}
+	for _, name := range []string{"deploy/ci.yml", "controllers/house_style.go"} {
+		if _, ok := mapping[name]; !ok {
+			t.Errorf("Load() does not contain the new template %s", name)
+		}
+	}
+}
+
+func TestWithOverrides(t *testing.T) {
+	builtin := template.Must(template.New("builtin").Parse(""))
+	override := template.Must(template.New("override").Parse(""))
+	mapping := templates.WithOverrides(
+		map[string]*template.Template{"./cmd/db.go": builtin, "main.go": builtin},
+		map[string]*template.Template{"cmd/db.go": override, "ci.yml": override},
+	)
+	// the override replaces the built-in template under its original key
+	if len(mapping) != 3 || mapping["./cmd/db.go"] != override || mapping["main.go"] != builtin || mapping["ci.yml"] != override {
+		t.Errorf("WithOverrides() = %v", mapping)
+	}
+}
+
+func TestOverrides_Skipped(t *testing.T) {
+	dir := writeOverrides(t, map[string]string{
+		"main.go.tmpl":                  "package main\n",
+		".gitignore.tmpl":               "bin/\n",
+		".git/HEAD":                     "ref: refs/heads/main\n",
+		".git/hooks/commit.tmpl":        "{{.Name",
+		".DS_Store":                     "\x00",
+		"cmd/.db.go.tmpl.swp":           "{{",
+		"README.md":                     "our templates\n",
+		".github/workflows/ci.yml.tmpl": "name: {{.Name}}\n",
+	})
+	overrides, err := templates.Overrides(dir)
+	if err != nil {
+		t.Fatalf("Overrides() error = %v", err)
+	}
+	if len(overrides) != 3 || overrides["main.go"] == nil || overrides[".gitignore"] == nil || overrides[".github/workflows/ci.yml"] == nil {
+		t.Errorf("Overrides() = %v, want only main.go, .gitignore and .github/workflows/ci.yml", overrides)
+	}
+}
+
+func TestLoad_Features(t *testing.T) {
+	config := createConfiguration()
+	config.SetFeature("cache", false)
+	dir := writeOverrides(t, map[string]string{
+		"services/redis_service.go.tmpl": "package services\n",
+		"main.go.tmpl":                   "package main\n",
+	})
+	mapping, err := templates.Load(config, dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if _, ok := mapping["services/redis_service.go"]; ok {
+		t.Error("Load() kept the override of services/redis_service.go without the cache feature")
+	}
+	if mapping["main.go"] == nil || mapping["main.go"].Name() != "main.go.tmpl" {
+		t.Error("Load() dropped the override of main.go")
+	}
+}
+
+func TestOverrides_ParseError(t *testing.T) {
+	dir := writeOverrides(t, map[string]string{"a.go.tmpl": "{{.Name", "b.go.tmpl": "{{end}}", "c.go.tmpl": "ok"})
+	_, err := templates.Overrides(dir)
+	if err == nil {
+		t.Fatal("Overrides() did not fail for templates that do not parse")
+	}
+	if !strings.Contains(err.Error(), "a.go") || !strings.Contains(err.Error(), "b.go") {
+		t.Errorf("Overrides() error = %v, want every template that does not parse", err)
+	}
+}
+
+func TestOverrideDir(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	dir, err := templates.OverrideDir("")
+	if err != nil || dir != "" {
+		t.Errorf("OverrideDir() = %q, %v without a default directory, want none", dir, err)
+	}
+
+	defaultDir := filepath.Join(configHome, "egg", "templates")
+	if err := os.MkdirAll(defaultDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if dir, err := templates.OverrideDir(""); err != nil || dir != defaultDir {
+		t.Errorf("OverrideDir() = %q, %v, want the default directory %s", dir, err, defaultDir)
+	}
+
+	passed := t.TempDir()
+	if dir, err := templates.OverrideDir(passed); err != nil || dir != passed {
+		t.Errorf("OverrideDir(%s) = %q, %v", passed, dir, err)
+	}
+	if _, err := templates.OverrideDir(filepath.Join(passed, "missing")); err == nil {
+		t.Error("OverrideDir() did not fail for a directory that does not exist")
+	}
+}
@@ -0,0 +1,58 @@
+package templates
+
+// The sources of the built-in templates by the names they had as string constants,
+// the templates themselves are the files under files/ declared in manifest.yaml
+var (
+	MainGoTemplate                                        = source("main.go.tmpl")
+	OpenapitoolsJSONTemplate                              = source("openapitools.json.tmpl")
+	SQLCYamlTemplate                                      = source("sqlc.yaml.tmpl")
+	READMETemplate                                        = source("README.md.tmpl")
+	MakefileTemplate                                      = source("Makefile.tmpl")
+	DockerfileTemplate                                    = source("Dockerfile.tmpl")
+	GitignoreTemplate                                     = source(".gitignore.tmpl")
+	DockerignoreTemplate                                  = source(".dockerignore.tmpl")
+	AirTomlTemplate                                       = source(".air.toml.tmpl")
+	CmdConfigurationConfigurationTemplate                 = source("cmd/configuration/configuration.go.tmpl")
+	DBCmdTemplate                                         = source("cmd/db.go.tmpl")
+	MigrateCmdTemplate                                    = source("cmd/migrate.go.tmpl")
+	RootCmdTemplate                                       = source("cmd/root.go.tmpl")
+	SwagCmdTemplate                                       = source("cmd/swag.go.tmpl")
+	UpCmdTemplate                                         = source("cmd/up.go.tmpl")
+	DownCmdTemplate                                       = source("cmd/down.go.tmpl")
+	GenerateCmdTemplate                                   = source("cmd/generate.go.tmpl")
+	VersionCmdTemplate                                    = source("cmd/version.go.tmpl")
+	BumpCmdTemplate                                       = source("cmd/bump.go.tmpl")
+	SERVICES_AuthServiceTemplate                          = source("services/auth_service.go.tmpl")
+	SERVICES_MinioServiceTemplate                         = source("services/minio_service.go.tmpl")
+	SERVICES_RedisServiceTemplate                         = source("services/redis_service.go.tmpl")
+	SERVICES_UserServiceTemplate                          = source("services/user_service.go.tmpl")
+	SERVICES_ValidatorServiceTemplate                     = source("services/validator_service.go.tmpl")
+	SERVICES_MockAuthServiceTemplate                      = source("services/mock_auth_service.go.tmpl")
+	SERVICES_MockUserServiceTemplate                      = source("services/mock_user_service.go.tmpl")
+	SERVICES_IAuthServiceTemplate                         = source("services/i_auth_service.go.tmpl")
+	SERVICES_IMinioServiceTemplate                        = source("services/i_minio_service.go.tmpl")
+	SERVICES_IRedisServiceTemplate                        = source("services/i_redis_service.go.tmpl")
+	SERVICES_IUserServiceTemplate                         = source("services/i_user_service.go.tmpl")
+	CONTROLLER_ControllerTemplate                         = source("controllers/controller.go.tmpl")
+	CONTROLLER_RoutesTemplate                             = source("controllers/routes.go.tmpl")
+	CONTROLLERS_UserControllerTemplate                    = source("controllers/user_controller.go.tmpl")
+	MIDDLEWARES_CONFIGS_AuthConfigTemplate                = source("middlewares/configs/auth.go.tmpl")
+	MIDDLEWARES_CONFIGS_StaticConfigTemplate              = source("middlewares/configs/static.go.tmpl")
+	MODELS_REQUESTS_LoginRequestTemplate                  = source("models/requests/login_request.go.tmpl")
+	MODELS_REQUESTS_NewUserRequestTemplate                = source("models/requests/new_user_request.go.tmpl")
+	MODELS_RESPONSE_DeleteUserResponseTemplate            = source("models/responses/delete_user_response.go.tmpl")
+	MODELS_RESPONSE_LoginResponseTemplate                 = source("models/responses/login_response.go.tmpl")
+	MODELS_RESPONSE_UserResponseTemplate                  = source("models/responses/user_response.go.tmpl")
+	MODELS_RESPONSE_UsersResponseTemplate                 = source("models/responses/users_response.go.tmpl")
+	MODELS_HANDLERS_LoginHandlerTemplate                  = source("models/handlers/login_handler.go.tmpl")
+	MODELS_HANDLERS_RegisterHandlerTemplate               = source("models/handlers/register_handler.go.tmpl")
+	MODELS_HANDLERS_DeleteUserHandlerTemplate             = source("models/handlers/delete_user_handler.go.tmpl")
+	MODELS_HANDLERS_GetCurrentLoggedInUserHandlerTemplate = source("models/handlers/get_current_logged_in_user_handler.go.tmpl")
+	MODELS_HANDLERS_GetProfilePictureHandlerTemplate      = source("models/handlers/get_profile_picture_handler.go.tmpl")
+	MODELS_HANDLERS_GetUsersHandlerTemplate               = source("models/handlers/get_users_handler.go.tmpl")
+	MODELS_HANDLERS_UploadProfilePictureHandlerTemplate   = source("models/handlers/upload_profile_picture_handler.go.tmpl")
+	DATABASE_MIGRATIONS_INITTemplate                      = source("db/migrations/0001_init.sql.tmpl")
+	DATABASE_QUERIES_TokenTemplate                        = source("db/queries/token.sql.tmpl")
+	DATABASE_QUERIES_UserTemplate                         = source("db/queries/user.sql.tmpl")
+	MODELS_RESPONSE_StringResponseTemplate                = source("models/responses/string_response.go.tmpl")
+)
@@ -0,0 +1,3 @@
+// Package docs stands in for the package `swag init` generates, so that the
+// compile test can build a project that imports it.
+package docs
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateTokenParams struct {
+	UserID             uuid.UUID  `json:"user_id"`
+	ExpirationDatetime *time.Time `json:"expiration_datetime"`
+	Token              *string    `json:"token"`
+}
+
+func (q *Queries) CreateToken(ctx context.Context, arg CreateTokenParams) (Token, error) {
+	return Token{}, nil
+}
+
+func (q *Queries) FindTokenByToken(ctx context.Context, token *string) (Token, error) {
+	return Token{}, nil
+}
+
+func (q *Queries) FindTokenByUserId(ctx context.Context, userID uuid.UUID) (Token, error) {
+	return Token{}, nil
+}
+
+type UpdateTokenByUserIdParams struct {
+	Token              *string    `json:"token"`
+	ExpirationDatetime *time.Time `json:"expiration_datetime"`
+	UserID             uuid.UUID  `json:"user_id"`
+}
+
+func (q *Queries) UpdateTokenByUserId(ctx context.Context, arg UpdateTokenByUserIdParams) error {
+	return nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type CreateUserParams struct {
+	Email      string `json:"email"`
+	Username   string `json:"username"`
+	BCryptHash string `json:"b_crypt_hash"`
+}
+
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	return User{}, nil
+}
+
+type CreateUserAdminParams struct {
+	Email      string `json:"email"`
+	Username   string `json:"username"`
+	BCryptHash string `json:"b_crypt_hash"`
+}
+
+func (q *Queries) CreateUserAdmin(ctx context.Context, arg CreateUserAdminParams) (User, error) {
+	return User{}, nil
+}
+
+func (q *Queries) DeleteUserByID(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+func (q *Queries) FindBCryptHashByEmail(ctx context.Context, email string) (string, error) {
+	return "", nil
+}
+
+func (q *Queries) FindBCryptHashByUsername(ctx context.Context, username string) (string, error) {
+	return "", nil
+}
+
+func (q *Queries) FindUserByEmail(ctx context.Context, email string) (User, error) {
+	return User{}, nil
+}
+
+func (q *Queries) FindUserByID(ctx context.Context, id uuid.UUID) (User, error) {
+	return User{}, nil
+}
+
+func (q *Queries) FindUserByUsername(ctx context.Context, username string) (User, error) {
+	return User{}, nil
+}
+
+func (q *Queries) FindUsers(ctx context.Context) ([]User, error) {
+	return nil, nil
+}
+
+type UpdateUserProfileParams struct {
+	ProfilePicUrl *string   `json:"profile_pic_url"`
+	ID            uuid.UUID `json:"id"`
+}
+
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) error {
+	return nil
+}
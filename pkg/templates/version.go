@@ -0,0 +1,5 @@
+package templates
+
+// Version is the release of the templates, every generated file is recorded with it
+// in the manifest of the project so that upgrades know what they were rendered from
+const Version = "v0.0.1"
@@ -0,0 +1,284 @@
+package upgrade
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/adamkali/egg_cli/pkg/configuration"
+	"github.com/adamkali/egg_cli/pkg/modules"
+	"github.com/adamkali/egg_cli/pkg/templates"
+)
+
+// Status is what an upgrade did with a single file
+type Status int
+
+const (
+	// Unchanged is a file that is already the same as the rendered template
+	Unchanged Status = iota
+	// Created is a template whose file did not exist
+	Created
+	// Updated is a file that was not edited since it was rendered, it was replaced
+	Updated
+	// Kept is an edited file whose template did not change, the edits were kept
+	Kept
+	// Merged is an edited file whose template changed, both were merged without conflicts
+	Merged
+	// Conflict is a file that was written with conflict markers
+	Conflict
+	// NoBaseline is a file that differs but has no baseline, the template was written next to it
+	NoBaseline
+)
+
+func (s Status) String() string {
+	switch s {
+	case Created:
+		return "created"
+	case Updated:
+		return "updated"
+	case Kept:
+		return "kept"
+	case Merged:
+		return "merged"
+	case Conflict:
+		return "conflict"
+	case NoBaseline:
+		return "no baseline"
+	default:
+		return "unchanged"
+	}
+}
+
+// File is a single row of the upgrade report
+type File struct {
+	Name   string
+	Status Status
+	// Conflicts is the number of conflict markers written into the file
+	Conflicts int
+}
+
+// Upgrade
+//
+// description:
+//
+//	Upgrade re-renders templates.Mapping for an existing project with the templates of
+//	this egg_cli release. Every file is merged three-way: the baseline stored in
+//	.egg/baseline when the file was last rendered is the common ancestor, the file on
+//	disk holds the edits of the project and the rendered template holds the fixes of
+//	egg_cli. Conflicts are written with git style markers and reported. A file without
+//	a baseline is only replaced when its checksum in the manifest shows it was not edited.
+type Upgrade struct {
+	// Dir is the root of the project
+	Dir           string
+	Configuration *configuration.Configuration
+	// DryRun reports what would happen without writing anything
+	DryRun bool
+	// Templates is a directory of templates that override or add to templates.Mapping, see templates.Load
+	Templates string
+
+	MappingFunc func(config *configuration.Configuration) map[string]*template.Template // For testing - can be injected to mock the templates
+
+	// modes are the permissions of the manifest, overrides get templates.DefaultPermissions
+	modes map[string]fs.FileMode
+}
+
+// New returns the upgrade of the project in dir, configured by config/<environment>.yaml
+func New(dir string, environment string) (*Upgrade, error) {
+	configurationFile := filepath.Join(dir, configuration.ConfigurationDir, environment+".yaml")
+	config, err := configuration.LoadConfigurationFromFile(configurationFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s, egg_cli upgrade has to be run in an egg project: %w", configurationFile, err)
+	}
+	return &Upgrade{Dir: dir, Configuration: config}, nil
+}
+
+// Run upgrades every file of the mapping, and reports them sorted by name
+func (u *Upgrade) Run() ([]File, error) {
+	// Use injected function if available, otherwise use real implementation
+	var mapping map[string]*template.Template
+	if u.MappingFunc != nil {
+		mapping = u.MappingFunc(u.Configuration)
+	} else {
+		var err error
+		if mapping, err = templates.Load(u.Configuration, u.Templates); err != nil {
+			return nil, err
+		}
+	}
+
+	u.modes = templates.Permissions(u.Configuration)
+
+	names := make([]string, 0, len(mapping))
+	for name := range mapping {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	manifest, err := modules.LoadManifest(u.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]File, 0, len(names))
+	var errs []error
+	for _, name := range names {
+		file, err := u.upgradeFile(name, mapping[name], manifest)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		files = append(files, file)
+	}
+	if !u.DryRun {
+		if err := manifest.Save(u.Dir); err != nil {
+			errs = append(errs, fmt.Errorf("error writing %s: %w", modules.ManifestFile, err))
+		}
+	}
+	return files, errors.Join(errs...)
+}
+
+// upgradeFile
+//
+// params:
+//
+//	name: string
+//	tmpl: *template.Template
+//	manifest: *modules.Manifest
+//
+// returns:
+//
+//	File: what was done with the file
+//	error:
+//	  - if the template can not be executed
+//	  - if the file or its baseline can not be read or written
+//
+// description:
+//
+//	Renders the template and decides from the file on disk and its baseline whether
+//	the file is replaced, kept or merged. The rendered template becomes the new
+//	baseline and is recorded in the manifest, so that the next upgrade only merges
+//	the changes made after this one.
+func (u *Upgrade) upgradeFile(name string, tmpl *template.Template, manifest *modules.Manifest) (File, error) {
+	file := File{Name: path.Clean(name)}
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, u.Configuration); err != nil {
+		return file, fmt.Errorf("error executing template %s: %w", name, err)
+	}
+	target := filepath.Join(u.Dir, filepath.FromSlash(file.Name))
+
+	current, err := os.ReadFile(target)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return file, fmt.Errorf("error reading %s: %w", name, err)
+	}
+	base, baseErr := modules.ReadBaseline(u.Dir, name)
+	if baseErr != nil && !errors.Is(baseErr, os.ErrNotExist) {
+		return file, fmt.Errorf("error reading the baseline of %s: %w", name, baseErr)
+	}
+
+	content := rendered.Bytes()
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		file.Status = Created
+	case bytes.Equal(current, content):
+		file.Status = Unchanged
+	case baseErr != nil && untouched(manifest, file.Name, current):
+		file.Status = Updated
+	case baseErr != nil:
+		// without a baseline the edits can not be told apart from the changes of the template
+		file.Status = NoBaseline
+		target += modules.NewFileSuffix
+	case bytes.Equal(base, content):
+		file.Status = Kept
+	case bytes.Equal(base, current):
+		file.Status = Updated
+	default:
+		merged, conflicts := modules.MergeThreeWay(string(base), string(current), string(content))
+		content = []byte(merged)
+		file.Status, file.Conflicts = Merged, conflicts
+		if conflicts > 0 {
+			file.Status = Conflict
+		}
+	}
+	if u.DryRun {
+		return file, nil
+	}
+
+	if file.Status != Unchanged && file.Status != Kept {
+		if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+			return file, fmt.Errorf("error creating the directory of %s: %w", name, err)
+		}
+		mode, ok := u.modes[name]
+		if !ok {
+			mode = templates.DefaultPermissions
+		}
+		if err := os.WriteFile(target, content, mode); err != nil {
+			return file, fmt.Errorf("error writing %s: %w", name, err)
+		}
+	}
+	if err := modules.WriteBaseline(u.Dir, name, rendered.Bytes()); err != nil {
+		return file, fmt.Errorf("error writing the baseline of %s: %w", name, err)
+	}
+	manifest.Set(modules.ManifestEntry{
+		Path:       file.Name,
+		Template:   tmpl.Name(),
+		EggVersion: templates.Version,
+		SHA256:     modules.Checksum(rendered.Bytes()),
+	})
+	return file, nil
+}
+
+// untouched reports whether the manifest shows that the file was not edited since it was generated
+func untouched(manifest *modules.Manifest, name string, current []byte) bool {
+	entry, ok := manifest.Lookup(name)
+	return ok && entry.SHA256 == modules.Checksum(current)
+}
+
+// Conflicted reports whether any of the files needs to be resolved by hand
+func Conflicted(files []File) bool {
+	for _, file := range files {
+		if file.Status == Conflict || file.Status == NoBaseline {
+			return true
+		}
+	}
+	return false
+}
+
+// Render renders the files that changed as a report, with a hint for every file that has to be resolved
+func Render(files []File) string {
+	width := 0
+	for _, file := range files {
+		width = max(width, len(file.Status.String()))
+	}
+
+	var builder strings.Builder
+	builder.WriteString("🥚 upgrade\n")
+	counts := make(map[Status]int)
+	for _, file := range files {
+		counts[file.Status]++
+		if file.Status == Unchanged {
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("%-*s  %s\n", width, file.Status, file.Name))
+		switch file.Status {
+		case Conflict:
+			builder.WriteString(fmt.Sprintf("%-*s  💡 resolve the %d conflicts between <<<<<<< and >>>>>>>\n", width, "", file.Conflicts))
+		case NoBaseline:
+			builder.WriteString(fmt.Sprintf("%-*s  💡 compare it with %s and remove the %s file\n", width, "", file.Name+modules.NewFileSuffix, modules.NewFileSuffix))
+		}
+	}
+
+	var summary []string
+	for status := Unchanged; status <= NoBaseline; status++ {
+		if counts[status] > 0 {
+			summary = append(summary, fmt.Sprintf("%d %s", counts[status], status))
+		}
+	}
+	builder.WriteString(strings.Join(summary, ", ") + "\n")
+	return builder.String()
+}
@@ -0,0 +1,223 @@
+package upgrade
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/adamkali/egg_cli/pkg/configuration"
+	"github.com/adamkali/egg_cli/pkg/modules"
+	"github.com/adamkali/egg_cli/pkg/templates"
+)
+
+// project writes the files and baselines of a project into a temporary directory
+func project(t *testing.T, files map[string]string, baselines map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, content := range baselines {
+		if err := modules.WriteBaseline(dir, name, []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestUpgrade_Run(t *testing.T) {
+	rendered := map[string]string{
+		"same.go":      "package {{.Name}}\n",
+		"new.go":       "package {{.Name}}\n",
+		"untouched.go": "package {{.Name}}\n\n// fixed\n",
+		"edited.go":    "package {{.Name}}\n",
+		"merged.go":    "package {{.Name}}\n\nfunc a() { fixed() }\n\nfunc b() {}\n",
+		"conflict.go":  "package {{.Name}}\n\nfunc a() { fixed() }\n",
+		"unknown.go":   "package {{.Name}}\n",
+	}
+	dir := project(t, map[string]string{
+		"same.go":      "package app\n",
+		"untouched.go": "package app\n",
+		"edited.go":    "package app\n\n// mine\n",
+		"merged.go":    "package app\n\nfunc a() {}\n\nfunc b() { mine() }\n",
+		"conflict.go":  "package app\n\nfunc a() { mine() }\n",
+		"unknown.go":   "package app\n\n// mine\n",
+	}, map[string]string{
+		"same.go":      "package app\n",
+		"untouched.go": "package app\n",
+		"edited.go":    "package app\n",
+		"merged.go":    "package app\n\nfunc a() {}\n\nfunc b() {}\n",
+		"conflict.go":  "package app\n\nfunc a() {}\n",
+	})
+
+	u := &Upgrade{
+		Dir:           dir,
+		Configuration: &configuration.Configuration{Name: "app"},
+		MappingFunc: func(config *configuration.Configuration) map[string]*template.Template {
+			mapping := make(map[string]*template.Template)
+			for name, text := range rendered {
+				mapping[name] = template.Must(template.New(name).Parse(text))
+			}
+			return mapping
+		},
+	}
+	files, err := u.Run()
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := map[string]Status{
+		"same.go":      Unchanged,
+		"new.go":       Created,
+		"untouched.go": Updated,
+		"edited.go":    Kept,
+		"merged.go":    Merged,
+		"conflict.go":  Conflict,
+		"unknown.go":   NoBaseline,
+	}
+	for _, file := range files {
+		if file.Status != want[file.Name] {
+			t.Errorf("%s status = %s, want %s", file.Name, file.Status, want[file.Name])
+		}
+	}
+	if len(files) != len(want) {
+		t.Errorf("Run() reported %d files, want %d", len(files), len(want))
+	}
+
+	read := func(name string) string {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+	if got := read("untouched.go"); got != "package app\n\n// fixed\n" {
+		t.Errorf("untouched.go = %q, want the new template", got)
+	}
+	if got := read("edited.go"); got != "package app\n\n// mine\n" {
+		t.Errorf("edited.go = %q, want the edits to be kept", got)
+	}
+	if got := read("merged.go"); got != "package app\n\nfunc a() { fixed() }\n\nfunc b() { mine() }\n" {
+		t.Errorf("merged.go = %q, want both changes", got)
+	}
+	if got := read("conflict.go"); !strings.Contains(got, "<<<<<<< yours\nfunc a() { mine() }") || !strings.Contains(got, "func a() { fixed() }\n>>>>>>> egg_cli") {
+		t.Errorf("conflict.go = %q, want conflict markers", got)
+	}
+	if got := read("unknown.go"); got != "package app\n\n// mine\n" {
+		t.Errorf("unknown.go = %q, want the file to be kept", got)
+	}
+	if got := read("unknown.go" + modules.NewFileSuffix); got != "package app\n" {
+		t.Errorf("unknown.go%s = %q, want the rendered template", modules.NewFileSuffix, got)
+	}
+	for name := range rendered {
+		baseline, err := modules.ReadBaseline(dir, name)
+		if err != nil {
+			t.Errorf("no baseline for %s after Run(): %v", name, err)
+			continue
+		}
+		if want := strings.ReplaceAll(rendered[name], "{{.Name}}", "app"); string(baseline) != want {
+			t.Errorf("baseline of %s = %q, want %q", name, baseline, want)
+		}
+	}
+	if !Conflicted(files) {
+		t.Error("Conflicted() = false with a conflict")
+	}
+	report := Render(files)
+	if !strings.Contains(report, "conflict     conflict.go") || strings.Contains(report, "same.go") {
+		t.Errorf("Render() = %q", report)
+	}
+}
+
+func TestUpgrade_DryRun(t *testing.T) {
+	dir := project(t, map[string]string{"a.go": "package app\n"}, map[string]string{"a.go": "package app\n"})
+	u := &Upgrade{
+		Dir:           dir,
+		Configuration: &configuration.Configuration{Name: "app"},
+		DryRun:        true,
+		MappingFunc: func(config *configuration.Configuration) map[string]*template.Template {
+			return map[string]*template.Template{
+				"a.go": template.Must(template.New("a.go").Parse("package {{.Name}}\n\n// fixed\n")),
+				"b.go": template.Must(template.New("b.go").Parse("package {{.Name}}\n")),
+			}
+		},
+	}
+	files, err := u.Run()
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(files) != 2 || files[0].Status != Updated || files[1].Status != Created {
+		t.Errorf("Run() = %v, want a.go updated and b.go created", files)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "a.go")); string(content) != "package app\n" {
+		t.Errorf("a.go was written by a dry run: %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "b.go")); err == nil {
+		t.Error("b.go was created by a dry run")
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(t.TempDir(), "development"); err == nil {
+		t.Error("New() did not fail outside of a project")
+	}
+
+	dir := t.TempDir()
+	config := &configuration.Configuration{Name: "app"}
+	if err := config.GenerateConfigurationFileIn(dir, "development"); err != nil {
+		t.Fatal(err)
+	}
+	u, err := New(dir, "development")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if u.Configuration.Name != "app" || u.Dir != dir {
+		t.Errorf("New() = %+v", u)
+	}
+}
+
+func TestUpgrade_Run_Manifest(t *testing.T) {
+	dir := project(t, map[string]string{"a.go": "package app\n", "b.go": "package app\n\n// mine\n"}, nil)
+	manifest := &modules.Manifest{}
+	manifest.Set(modules.ManifestEntry{Path: "a.go", Template: "a.go", EggVersion: "v0.0.0", SHA256: modules.Checksum([]byte("package app\n"))})
+	manifest.Set(modules.ManifestEntry{Path: "b.go", Template: "b.go", EggVersion: "v0.0.0", SHA256: modules.Checksum([]byte("package app\n"))})
+	if err := manifest.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	u := &Upgrade{
+		Dir:           dir,
+		Configuration: &configuration.Configuration{Name: "app"},
+		MappingFunc: func(config *configuration.Configuration) map[string]*template.Template {
+			return map[string]*template.Template{
+				"a.go": template.Must(template.New("a.go").Parse("package {{.Name}}\n\n// fixed\n")),
+				"b.go": template.Must(template.New("b.go").Parse("package {{.Name}}\n\n// fixed\n")),
+			}
+		},
+	}
+	files, err := u.Run()
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	// without a baseline the manifest tells the untouched file apart from the edited one
+	if len(files) != 2 || files[0].Status != Updated || files[1].Status != NoBaseline {
+		t.Errorf("Run() = %v, want a.go updated and b.go without a baseline", files)
+	}
+
+	saved, err := modules.LoadManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.go", "b.go"} {
+		entry, ok := saved.Lookup(name)
+		if !ok || entry.EggVersion != templates.Version || entry.SHA256 != modules.Checksum([]byte("package app\n\n// fixed\n")) {
+			t.Errorf("manifest entry of %s = %+v, want the upgraded template", name, entry)
+		}
+	}
+}
@@ -66,4 +66,6 @@ var (
 		1: MinioAccessKeyName,
 		2: MinioSecretKeyName,
 	}
+	// Features are the features saved on the features page, by the name of the feature
+	Features = map[string]bool{}
 )